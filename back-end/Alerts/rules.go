@@ -0,0 +1,233 @@
+// Package alerts decides which clients a dietitian should look at, from the
+// daily totals they logged and their goals. The rules are evaluated nightly
+// by the alert evaluation job.
+package alerts
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	models "HabitBite/backend/Models"
+)
+
+// Rules are the thresholds of the alert rules.
+type Rules struct {
+	// CaloriePercent is how far, in percent of the target, the calories of a
+	// day may be over or under before the day counts against the client.
+	CaloriePercent float64
+	// CalorieDays is how many consecutive days over, or under, raise an
+	// alert.
+	CalorieDays int
+	// InactiveDays is how many days without any entry raise an alert.
+	InactiveDays int
+	// ProteinPercent is the share of the protein target, in percent, the
+	// average of the logged days must reach over the last ProteinDays days.
+	ProteinPercent float64
+	ProteinDays    int
+}
+
+// DefaultRules flags 3 days in a row 20% off the calorie target, 3 days
+// without logging, and a week averaging under 80% of the protein target.
+func DefaultRules() *Rules {
+	return &Rules{
+		CaloriePercent: 20,
+		CalorieDays:    3,
+		InactiveDays:   3,
+		ProteinPercent: 80,
+		ProteinDays:    7,
+	}
+}
+
+// NewRules returns the default rules with the overrides of spec, such as
+// "calorie_percent=15,calorie_days=5,inactive_days=2,protein_percent=70,protein_days=14".
+// An empty spec keeps the defaults.
+func NewRules(spec string) (*Rules, error) {
+	rules := DefaultRules()
+
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid alert rules: %q is not key=value", item)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		var err error
+		switch key {
+		case "calorie_percent":
+			rules.CaloriePercent, err = parsePercent(value)
+		case "calorie_days":
+			rules.CalorieDays, err = parseDays(value)
+		case "inactive_days":
+			rules.InactiveDays, err = parseDays(value)
+		case "protein_percent":
+			rules.ProteinPercent, err = parsePercent(value)
+		case "protein_days":
+			rules.ProteinDays, err = parseDays(value)
+		default:
+			err = fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid alert rules: %v", err)
+		}
+	}
+	return rules, nil
+}
+
+func parsePercent(value string) (float64, error) {
+	percent, err := strconv.ParseFloat(value, 64)
+	if err != nil || percent <= 0 || percent >= 100 {
+		return 0, fmt.Errorf("%q is not a percentage between 0 and 100", value)
+	}
+	return percent, nil
+}
+
+func parseDays(value string) (int, error) {
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 1 || days > 60 {
+		return 0, fmt.Errorf("%q is not a number of days between 1 and 60", value)
+	}
+	return days, nil
+}
+
+// Window is how many days of totals, ending on the evaluated day, Evaluate
+// needs.
+func (r *Rules) Window() int {
+	return max(r.CalorieDays, r.ProteinDays)
+}
+
+// Client is what the rules know about one client.
+type Client struct {
+	// Joined is when the account was created; a new client is not flagged
+	// for not logging before InactiveDays have passed.
+	Joined time.Time
+	// Goals is nil when the client has none.
+	Goals *models.UserGoals
+	// Days are the totals of the Window days ending on the evaluated day,
+	// keyed by YYYY-MM-DD. Days without entries are missing.
+	Days map[string]models.DailyTotals
+	// LastLog is the latest day with entries, or nil if there is none.
+	LastLog *time.Time
+}
+
+// Evaluate returns the rules that apply to the client on asOf, the last
+// complete day.
+func (r *Rules) Evaluate(client *Client, asOf time.Time) []models.AlertFinding {
+	asOf = models.DateOnly(asOf)
+	var findings []models.AlertFinding
+
+	if finding, ok := r.notLogging(client, asOf); ok {
+		findings = append(findings, finding)
+	}
+	if client.Goals == nil {
+		return findings
+	}
+	if finding, ok := r.calories(client, asOf, 1); ok {
+		findings = append(findings, finding)
+	}
+	if finding, ok := r.calories(client, asOf, -1); ok {
+		findings = append(findings, finding)
+	}
+	if finding, ok := r.lowProtein(client, asOf); ok {
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+func (r *Rules) notLogging(client *Client, asOf time.Time) (models.AlertFinding, bool) {
+	since := models.DateOnly(client.Joined)
+	if client.LastLog != nil {
+		since = models.DateOnly(*client.LastLog)
+	}
+	days := daysBetween(since, asOf)
+	if days < r.InactiveDays {
+		return models.AlertFinding{}, false
+	}
+
+	message := fmt.Sprintf("No food logged for %d days", days)
+	if client.LastLog == nil {
+		message = fmt.Sprintf("No food logged since joining %d days ago", days)
+	}
+	return models.AlertFinding{Rule: models.AlertNotLogging, Message: message}, true
+}
+
+// calories looks for a streak of days ending on asOf above the target, for a
+// direction of 1, or below it, for -1.
+func (r *Rules) calories(client *Client, asOf time.Time, direction float64) (models.AlertFinding, bool) {
+	target := float64(client.Goals.TargetCalories)
+	if target <= 0 {
+		return models.AlertFinding{}, false
+	}
+
+	var streak int
+	var deviation float64
+	for day := asOf; streak < r.CalorieDays; day = day.AddDate(0, 0, -1) {
+		totals, ok := client.Days[day.Format("2006-01-02")]
+		if !ok {
+			break
+		}
+		off := (totals.Calories - target) / target * 100 * direction
+		if off <= r.CaloriePercent {
+			break
+		}
+		streak++
+		deviation += off
+	}
+	if streak < r.CalorieDays {
+		return models.AlertFinding{}, false
+	}
+
+	if direction > 0 {
+		return models.AlertFinding{
+			Rule:    models.AlertCaloriesOver,
+			Message: fmt.Sprintf("Calories %.0f%% over target on average for %d days in a row", deviation/float64(streak), streak),
+		}, true
+	}
+	return models.AlertFinding{
+		Rule:    models.AlertCaloriesUnder,
+		Message: fmt.Sprintf("Calories %.0f%% under target on average for %d days in a row", deviation/float64(streak), streak),
+	}, true
+}
+
+// lowProtein averages the logged days of the last ProteinDays days. At least
+// half of them must be logged for the average to mean anything.
+func (r *Rules) lowProtein(client *Client, asOf time.Time) (models.AlertFinding, bool) {
+	target := client.Goals.TargetProtein
+	if target <= 0 {
+		return models.AlertFinding{}, false
+	}
+
+	var logged int
+	var protein float64
+	for i := 0; i < r.ProteinDays; i++ {
+		if totals, ok := client.Days[asOf.AddDate(0, 0, -i).Format("2006-01-02")]; ok {
+			logged++
+			protein += totals.Protein
+		}
+	}
+	if logged == 0 || logged*2 < r.ProteinDays {
+		return models.AlertFinding{}, false
+	}
+
+	average := protein / float64(logged)
+	percent := average / target * 100
+	if percent >= r.ProteinPercent {
+		return models.AlertFinding{}, false
+	}
+	return models.AlertFinding{
+		Rule: models.AlertLowProtein,
+		Message: fmt.Sprintf("Protein averaged %.0f g, %.0f%% of the %.0f g target, over the last %d days",
+			average, math.Round(percent), target, r.ProteinDays),
+	}, true
+}
+
+// daysBetween counts the calendar days from a to b.
+func daysBetween(a, b time.Time) int {
+	return int(math.Round(models.DateOnly(b).Sub(models.DateOnly(a)).Hours() / 24))
+}
@@ -0,0 +1,216 @@
+package alerts
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	models "HabitBite/backend/Models"
+)
+
+var asOf = time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+// loggedDays returns the totals of the days ending on asOf, the last one
+// first.
+func loggedDays(totals ...models.DailyTotals) map[string]models.DailyTotals {
+	days := make(map[string]models.DailyTotals)
+	for i, day := range totals {
+		date := asOf.AddDate(0, 0, -i).Format("2006-01-02")
+		day.Date = date
+		days[date] = day
+	}
+	return days
+}
+
+func repeat(day models.DailyTotals, n int) []models.DailyTotals {
+	days := make([]models.DailyTotals, n)
+	for i := range days {
+		days[i] = day
+	}
+	return days
+}
+
+func daysAgo(n int) *time.Time {
+	day := asOf.AddDate(0, 0, -n).Add(12 * time.Hour)
+	return &day
+}
+
+func TestEvaluate(t *testing.T) {
+	goals := &models.UserGoals{TargetCalories: 2000, TargetProtein: 100}
+	onTarget := models.DailyTotals{Calories: 2000, Protein: 100}
+
+	tests := []struct {
+		name   string
+		client Client
+		want   []string
+	}{
+		{
+			name:   "on target",
+			client: Client{Goals: goals, Days: loggedDays(repeat(onTarget, 7)...), LastLog: daysAgo(0)},
+		},
+
+		// Calories: three days in a row more than 20% off.
+		{
+			name:   "over",
+			client: Client{Goals: goals, Days: loggedDays(repeat(models.DailyTotals{Calories: 2401, Protein: 100}, 3)...), LastLog: daysAgo(0)},
+			want:   []string{models.AlertCaloriesOver},
+		},
+		{
+			name:   "exactly 20% over",
+			client: Client{Goals: goals, Days: loggedDays(repeat(models.DailyTotals{Calories: 2400, Protein: 100}, 3)...), LastLog: daysAgo(0)},
+		},
+		{
+			name:   "under",
+			client: Client{Goals: goals, Days: loggedDays(repeat(models.DailyTotals{Calories: 1599, Protein: 100}, 3)...), LastLog: daysAgo(0)},
+			want:   []string{models.AlertCaloriesUnder},
+		},
+		{
+			name:   "exactly 20% under",
+			client: Client{Goals: goals, Days: loggedDays(repeat(models.DailyTotals{Calories: 1600, Protein: 100}, 3)...), LastLog: daysAgo(0)},
+		},
+		{
+			name: "two days over",
+			client: Client{Goals: goals, Days: loggedDays(
+				models.DailyTotals{Calories: 3000, Protein: 100}, models.DailyTotals{Calories: 3000, Protein: 100}, onTarget,
+			), LastLog: daysAgo(0)},
+		},
+		{
+			name: "streak broken by a day without entries",
+			client: Client{Goals: goals, Days: map[string]models.DailyTotals{
+				"2026-03-10": {Calories: 3000, Protein: 100},
+				"2026-03-09": {Calories: 3000, Protein: 100},
+				"2026-03-07": {Calories: 3000, Protein: 100},
+			}, LastLog: daysAgo(0)},
+		},
+		{
+			name: "over and under alternate",
+			client: Client{Goals: goals, Days: loggedDays(
+				models.DailyTotals{Calories: 3000, Protein: 100}, models.DailyTotals{Calories: 1000, Protein: 100}, models.DailyTotals{Calories: 3000, Protein: 100},
+			), LastLog: daysAgo(0)},
+		},
+		{
+			name:   "no calorie target",
+			client: Client{Goals: &models.UserGoals{TargetProtein: 100}, Days: loggedDays(repeat(models.DailyTotals{Calories: 5000, Protein: 100}, 3)...), LastLog: daysAgo(0)},
+		},
+
+		// Not logging: three days since the last entry, or since joining.
+		{
+			name:   "three days since the last entry",
+			client: Client{Goals: goals, LastLog: daysAgo(3)},
+			want:   []string{models.AlertNotLogging},
+		},
+		{
+			name:   "two days since the last entry",
+			client: Client{Goals: goals, LastLog: daysAgo(2)},
+		},
+		{
+			name:   "three days since joining",
+			client: Client{Joined: *daysAgo(3)},
+			want:   []string{models.AlertNotLogging},
+		},
+		{
+			name:   "two days since joining",
+			client: Client{Joined: *daysAgo(2)},
+		},
+
+		// Protein: the logged days of the last week average under 80%.
+		{
+			name:   "low protein",
+			client: Client{Goals: goals, Days: loggedDays(repeat(models.DailyTotals{Calories: 2000, Protein: 79.9}, 7)...), LastLog: daysAgo(0)},
+			want:   []string{models.AlertLowProtein},
+		},
+		{
+			name:   "exactly 80% protein",
+			client: Client{Goals: goals, Days: loggedDays(repeat(models.DailyTotals{Calories: 2000, Protein: 80}, 7)...), LastLog: daysAgo(0)},
+		},
+		{
+			name:   "half the week logged",
+			client: Client{Goals: goals, Days: loggedDays(repeat(models.DailyTotals{Calories: 2000, Protein: 50}, 4)...), LastLog: daysAgo(0)},
+			want:   []string{models.AlertLowProtein},
+		},
+		{
+			name:   "less than half the week logged",
+			client: Client{Goals: goals, Days: loggedDays(repeat(models.DailyTotals{Calories: 2000, Protein: 10}, 3)...), LastLog: daysAgo(0)},
+		},
+
+		{
+			name:   "no goals",
+			client: Client{Days: loggedDays(repeat(models.DailyTotals{Calories: 5000}, 7)...), LastLog: daysAgo(0)},
+		},
+		{
+			name:   "several rules",
+			client: Client{Goals: goals, Days: loggedDays(repeat(models.DailyTotals{Calories: 1000, Protein: 20}, 7)...), LastLog: daysAgo(0)},
+			want:   []string{models.AlertCaloriesUnder, models.AlertLowProtein},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rules []string
+			for _, finding := range DefaultRules().Evaluate(&tt.client, asOf.Add(20*time.Hour)) {
+				rules = append(rules, finding.Rule)
+			}
+			if !reflect.DeepEqual(rules, tt.want) {
+				t.Errorf("Evaluate = %v, want %v", rules, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateMessages(t *testing.T) {
+	goals := &models.UserGoals{TargetCalories: 2000, TargetProtein: 100}
+
+	tests := []struct {
+		client Client
+		want   string
+	}{
+		{
+			Client{Goals: goals, Days: loggedDays(repeat(models.DailyTotals{Calories: 2500, Protein: 100}, 3)...), LastLog: daysAgo(0)},
+			"Calories 25% over target on average for 3 days in a row",
+		},
+		{Client{LastLog: daysAgo(4)}, "No food logged for 4 days"},
+		{Client{Joined: *daysAgo(5)}, "No food logged since joining 5 days ago"},
+		{
+			Client{Goals: goals, Days: loggedDays(repeat(models.DailyTotals{Calories: 2000, Protein: 60}, 7)...), LastLog: daysAgo(0)},
+			"Protein averaged 60 g, 60% of the 100 g target, over the last 7 days",
+		},
+	}
+	for _, tt := range tests {
+		findings := DefaultRules().Evaluate(&tt.client, asOf)
+		if len(findings) != 1 || findings[0].Message != tt.want {
+			t.Errorf("Evaluate = %+v, want one finding %q", findings, tt.want)
+		}
+	}
+}
+
+func TestCustomRules(t *testing.T) {
+	rules, err := NewRules("calorie_percent=10, calorie_days=2,inactive_days=1,protein_percent=90,protein_days=4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Rules{CaloriePercent: 10, CalorieDays: 2, InactiveDays: 1, ProteinPercent: 90, ProteinDays: 4}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("NewRules = %+v, want %+v", rules, want)
+	}
+	if rules.Window() != 4 {
+		t.Errorf("Window = %d, want 4", rules.Window())
+	}
+
+	client := &Client{
+		Goals:   &models.UserGoals{TargetCalories: 2000, TargetProtein: 100},
+		Days:    loggedDays(repeat(models.DailyTotals{Calories: 2250, Protein: 85}, 2)...),
+		LastLog: daysAgo(0),
+	}
+	var found []string
+	for _, finding := range rules.Evaluate(client, asOf) {
+		found = append(found, finding.Rule)
+	}
+	if want := []string{models.AlertCaloriesOver, models.AlertLowProtein}; !reflect.DeepEqual(found, want) {
+		t.Errorf("Evaluate with custom rules = %v, want %v", found, want)
+	}
+
+	for _, spec := range []string{"calorie_percent=0", "calorie_percent=100", "calorie_days=0", "protein_days=61", "inactive_days=two", "speed=1", "calorie_days"} {
+		if _, err := NewRules(spec); err == nil {
+			t.Errorf("NewRules(%q) succeeded", spec)
+		}
+	}
+}
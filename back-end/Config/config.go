@@ -1,20 +1,45 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
 	"errors"
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// Supported values of DB_DRIVER
+const (
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Supported values of EMAIL_VERIFICATION. Verification links are mailed on
+// registration in every mode; the mode decides what unverified users can do.
+const (
+	// VerificationOff lets unverified users do everything.
+	VerificationOff = "off"
+	// VerificationRestrict lets unverified users log in and read, but not
+	// write outside of their account settings.
+	VerificationRestrict = "restrict"
+	// VerificationBlock refuses to log unverified users in.
+	VerificationBlock = "block"
+)
+
 // Config holds all configuration parameters for the application
 type Config struct {
+	DBDriver   string
+	DBPath     string
 	DBHost     string
 	DBPort     int
 	DBUser     string
 	DBPassword string
 	DBName     string
+	DBSSLMode  string
 
 	JWTSecret      string
 	JWTExpiryHours int
@@ -24,6 +49,38 @@ type Config struct {
 	ServerPort         string
 	CORSAllowedOrigins []string
 
+	// Goal calculation overrides, see goals.NewPlanner. By default targets
+	// use Mifflin-St Jeor and the percentage presets; BMR_FORMULA=katch_mcardle
+	// and PROTEIN_PER_KG=recommended opt into lean mass and protein rules.
+	BMRFormula   string
+	MacroPresets string
+	ProteinPerKg string
+
+	// Alert rule overrides, see alerts.NewRules, and the hour the alerts are
+	// evaluated every night; a negative hour turns the nightly run off.
+	AlertRules string
+	AlertHour  int
+
+	// Outgoing mail, see mailer.New. AppURL is the front-end address the
+	// links in emails point to.
+	MailDriver   string
+	MailFrom     string
+	MailFile     string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUser     string
+	SMTPPassword string
+	AppURL       string
+
+	// EmailVerification is the policy for unverified accounts
+	EmailVerification string
+
+	// MFARequiredRoles are the roles that must use two-factor login, and
+	// MFAEncryptionKey the base64 key encrypting TOTP secrets, see
+	// TwoFactorKey.
+	MFARequiredRoles []string
+	MFAEncryptionKey string
+
 	Environment string
 }
 
@@ -32,11 +89,14 @@ func LoadConfig() (*Config, error) {
 	// Default configuration for local development
 	config := &Config{
 		// Database (XAMPP defaults)
+		DBDriver:   DriverMySQL,
+		DBPath:     "habitbite.db",
 		DBHost:     "localhost",
 		DBPort:     3306,
 		DBUser:     "root",
 		DBPassword: "",
 		DBName:     "habitbite",
+		DBSSLMode:  "disable",
 
 		JWTSecret:      os.Getenv("JWT_SECRET"), // should be changed in production (it's an example)
 		JWTExpiryHours: 24,
@@ -47,9 +107,27 @@ func LoadConfig() (*Config, error) {
 		ServerPort:         "8080",
 		CORSAllowedOrigins: []string{"http://localhost:3000", "http://localhost:5173"},
 
+		AlertHour: 2,
+
+		MailDriver: "log",
+		MailFrom:   "HabitBite <no-reply@habitbite.local>",
+		SMTPPort:   587,
+		AppURL:     "http://localhost:5173",
+
+		EmailVerification: VerificationRestrict,
+
 		Environment: "development",
 	}
 
+	if driver := os.Getenv("DB_DRIVER"); driver != "" {
+		config.DBDriver = driver
+	}
+	if path := os.Getenv("DB_PATH"); path != "" {
+		config.DBPath = path
+	}
+	if config.DBDriver == DriverPostgres {
+		config.DBPort = 5432
+	}
 	if port := os.Getenv("DB_PORT"); port != "" {
 		if p, err := strconv.Atoi(port); err == nil {
 			config.DBPort = p
@@ -67,6 +145,9 @@ func LoadConfig() (*Config, error) {
 	if name := os.Getenv("DB_NAME"); name != "" {
 		config.DBName = name
 	}
+	if sslMode := os.Getenv("DB_SSLMODE"); sslMode != "" {
+		config.DBSSLMode = sslMode
+	}
 	if secret := os.Getenv("JWT_SECRET"); secret != "" {
 		config.JWTSecret = secret
 	}
@@ -85,6 +166,47 @@ func LoadConfig() (*Config, error) {
 	if origins := os.Getenv("CORS_ALLOWED_ORIGINS"); origins != "" {
 		config.CORSAllowedOrigins = strings.Split(origins, ",")
 	}
+	config.BMRFormula = os.Getenv("BMR_FORMULA")
+	config.MacroPresets = os.Getenv("MACRO_PRESETS")
+	config.ProteinPerKg = os.Getenv("PROTEIN_PER_KG")
+	config.AlertRules = os.Getenv("ALERT_RULES")
+	if hour := os.Getenv("ALERT_HOUR"); hour != "" {
+		if h, err := strconv.Atoi(hour); err == nil {
+			config.AlertHour = h
+		}
+	}
+	if driver := os.Getenv("MAIL_DRIVER"); driver != "" {
+		config.MailDriver = driver
+	}
+	if from := os.Getenv("MAIL_FROM"); from != "" {
+		config.MailFrom = from
+	}
+	config.MailFile = os.Getenv("MAIL_FILE")
+	config.SMTPHost = os.Getenv("SMTP_HOST")
+	if port := os.Getenv("SMTP_PORT"); port != "" {
+		if p, err := strconv.Atoi(port); err == nil {
+			config.SMTPPort = p
+		}
+	}
+	config.SMTPUser = os.Getenv("SMTP_USER")
+	config.SMTPPassword = os.Getenv("SMTP_PASS")
+	if url := os.Getenv("APP_URL"); url != "" {
+		config.AppURL = strings.TrimRight(url, "/")
+	}
+	if policy := os.Getenv("EMAIL_VERIFICATION"); policy != "" {
+		config.EmailVerification = policy
+	}
+	if err := config.validateEmailVerification(); err != nil {
+		return nil, err
+	}
+	if roles := os.Getenv("MFA_REQUIRED_ROLES"); roles != "" {
+		for _, role := range strings.Split(roles, ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				config.MFARequiredRoles = append(config.MFARequiredRoles, role)
+			}
+		}
+	}
+	config.MFAEncryptionKey = os.Getenv("MFA_ENCRYPTION_KEY")
 
 	return config, nil
 }
@@ -94,6 +216,32 @@ func (c *Config) JWTExpiryDuration() time.Duration {
 	return time.Duration(c.JWTExpiryHours) * time.Hour
 }
 
+// TwoFactorRequired reports whether users of role must use two-factor login.
+func (c *Config) TwoFactorRequired(role string) bool {
+	for _, required := range c.MFARequiredRoles {
+		if required == role {
+			return true
+		}
+	}
+	return false
+}
+
+// TwoFactorKey returns the 32-byte key encrypting TOTP secrets. Without
+// MFA_ENCRYPTION_KEY it is derived from the JWT secret, which works for
+// development but means rotating that secret makes the stored TOTP secrets
+// unreadable.
+func (c *Config) TwoFactorKey() ([]byte, error) {
+	if c.MFAEncryptionKey == "" {
+		key := sha256.Sum256([]byte("habitbite-mfa:" + c.JWTSecret))
+		return key[:], nil
+	}
+	key, err := base64.StdEncoding.DecodeString(c.MFAEncryptionKey)
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("MFA_ENCRYPTION_KEY must be the base64 encoding of 32 bytes")
+	}
+	return key, nil
+}
+
 // IsDevelopment returns true if the application is running in development mode
 func (c *Config) IsDevelopment() bool {
 	return c.Environment == "development"
@@ -114,5 +262,24 @@ func (c *Config) Validate() error {
 		return errors.New("JWT_EXPIRY_HOURS must be positive")
 	}
 
-	return nil
+	switch c.DBDriver {
+	case DriverMySQL, DriverSQLite, DriverPostgres:
+	default:
+		return fmt.Errorf("unsupported DB_DRIVER %q", c.DBDriver)
+	}
+
+	if c.AlertHour > 23 {
+		return errors.New("ALERT_HOUR must be an hour of the day, or negative to disable")
+	}
+
+	return c.validateEmailVerification()
+}
+
+func (c *Config) validateEmailVerification() error {
+	switch c.EmailVerification {
+	case VerificationOff, VerificationRestrict, VerificationBlock:
+		return nil
+	default:
+		return fmt.Errorf("unsupported EMAIL_VERIFICATION %q", c.EmailVerification)
+	}
 }
@@ -2,12 +2,29 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
 )
 
+// NewDB opens a connection for the configured DB_DRIVER
+func NewDB(cfg *Config) (*sqlx.DB, error) {
+	switch cfg.DBDriver {
+	case DriverMySQL:
+		return NewMySQLDB(cfg)
+	case DriverSQLite:
+		return NewSQLiteDB(cfg)
+	case DriverPostgres:
+		return NewPostgresDB(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.DBDriver)
+	}
+}
+
 // NewMySQLDB creates a new MySQL database connection
 func NewMySQLDB(cfg *Config) (*sqlx.DB, error) {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&charset=utf8mb4&collation=utf8mb4_unicode_ci&loc=Local",
@@ -29,33 +46,51 @@ func NewMySQLDB(cfg *Config) (*sqlx.DB, error) {
 	return db, nil
 }
 
-// CreateSchema creates the database schema if it doesn't exist
-func CreateSchema(db *sqlx.DB) error {
-	//users table
-	_, err := db.Exec(`
-	CREATE TABLE IF NOT EXISTS users (
-		id INT AUTO_INCREMENT PRIMARY KEY,
-		email VARCHAR(255) NOT NULL UNIQUE,
-		username VARCHAR(50) NOT NULL UNIQUE,
-		password_hash VARCHAR(255) NOT NULL,
-		full_name VARCHAR(255) NOT NULL,
-		birthdate DATE NOT NULL,
-		gender VARCHAR(20) NOT NULL,
-		height DECIMAL(5,2) NOT NULL,
-		weight DECIMAL(5,2) NOT NULL,
-		goal_type VARCHAR(50) NOT NULL,
-		activity_level VARCHAR(50) NOT NULL,
-		daily_calorie_goal INT NOT NULL DEFAULT 2000,
-		role VARCHAR(20) NOT NULL DEFAULT 'user',
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-		INDEX (email),
-		INDEX (username)
-	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
-	`)
+// NewSQLiteDB opens the SQLite database file at cfg.DBPath, creating it if
+// needed.
+func NewSQLiteDB(cfg *Config) (*sqlx.DB, error) {
+	// WAL lets readers run alongside a writer, and immediate transactions take
+	// the write lock up front so concurrent writers wait instead of failing.
+	dsn := fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate", cfg.DBPath)
+
+	db, err := sqlx.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("could not open database: %v", err)
+	}
+
+	db.SetMaxIdleConns(10)
+	db.SetMaxOpenConns(10)
+	db.SetConnMaxLifetime(time.Hour)
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("database connection failed: %v", err)
+	}
+
+	return db, nil
+}
+
+// NewPostgresDB creates a new PostgreSQL database connection through pgx
+func NewPostgresDB(cfg *Config) (*sqlx.DB, error) {
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.DBUser, cfg.DBPassword),
+		Host:     fmt.Sprintf("%s:%d", cfg.DBHost, cfg.DBPort),
+		Path:     "/" + cfg.DBName,
+		RawQuery: url.Values{"sslmode": {cfg.DBSSLMode}}.Encode(),
+	}
 
+	db, err := sqlx.Open("pgx", dsn.String())
 	if err != nil {
-		return fmt.Errorf("error creating users table: %v", err)
+		return nil, fmt.Errorf("could not open database: %v", err)
 	}
-	return nil
+
+	db.SetMaxIdleConns(10)
+	db.SetMaxOpenConns(100)
+	db.SetConnMaxLifetime(time.Hour)
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("database connection failed: %v", err)
+	}
+
+	return db, nil
 }
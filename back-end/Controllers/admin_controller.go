@@ -11,11 +11,12 @@ import (
 )
 
 type AdminController struct {
-	userRepo repositories.UserRepository
+	userRepo  repositories.UserRepository
+	auditRepo repositories.AuditRepository
 }
 
-func NewAdminController(repo repositories.UserRepository) *AdminController {
-	return &AdminController{userRepo: repo}
+func NewAdminController(repo repositories.UserRepository, auditRepo repositories.AuditRepository) *AdminController {
+	return &AdminController{userRepo: repo, auditRepo: auditRepo}
 }
 
 // GetAllUsers returns all users in the system
@@ -99,6 +100,19 @@ func (ac *AdminController) UpdateUser(c *gin.Context) {
 	if weight, ok := requestData["weight"].(float64); ok {
 		existingUser.Weight = weight
 	}
+	if bodyFat, ok := requestData["bodyFat"]; ok {
+		// null clears a body fat percentage that is no longer known
+		switch v := bodyFat.(type) {
+		case float64:
+			if v < 3 || v > 70 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Body fat must be between 3 and 70 percent"})
+				return
+			}
+			existingUser.BodyFat = &v
+		case nil:
+			existingUser.BodyFat = nil
+		}
+	}
 	if goalType, ok := requestData["goalType"].(string); ok {
 		existingUser.GoalType = goalType
 	}
@@ -127,6 +141,7 @@ func (ac *AdminController) UpdateUser(c *gin.Context) {
 		"gender":           sanitizedUser.Gender,
 		"height":           sanitizedUser.Height,
 		"weight":           sanitizedUser.Weight,
+		"bodyFat":          sanitizedUser.BodyFat,
 		"goalType":         sanitizedUser.GoalType,
 		"activityLevel":    sanitizedUser.ActivityLevel,
 		"dailyCalorieGoal": sanitizedUser.DailyCalorieGoal,
@@ -199,6 +214,9 @@ func (ac *AdminController) CreateUser(c *gin.Context) {
 	if role, ok := requestData["role"].(string); ok {
 		user.Role = role
 	}
+	// Accounts created by an admin don't go through email verification.
+	verifiedAt := time.Now()
+	user.EmailVerifiedAt = &verifiedAt
 
 	if err := ac.userRepo.CreateUser(c.Request.Context(), user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
@@ -223,3 +241,34 @@ func (ac *AdminController) CreateUser(c *gin.Context) {
 		"updatedAt":        sanitizedUser.UpdatedAt,
 	})
 }
+
+// GetAuditLog returns recent privileged accesses, optionally for one user
+func (ac *AdminController) GetAuditLog(c *gin.Context) {
+	targetUserID := 0
+	if userIDStr := c.Query("userId"); userIDStr != "" {
+		id, err := strconv.Atoi(userIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		targetUserID = id
+	}
+
+	limit := 100
+	if limitStr := c.Query("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l < 1 || l > 500 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Limit must be between 1 and 500"})
+			return
+		}
+		limit = l
+	}
+
+	entries, err := ac.auditRepo.GetAuditEntries(c.Request.Context(), targetUserID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
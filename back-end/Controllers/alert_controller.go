@@ -0,0 +1,126 @@
+package Controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	models "HabitBite/backend/Models"
+	repositories "HabitBite/backend/Repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AlertController struct {
+	alertRepo repositories.AlertRepository
+}
+
+func NewAlertController(alertRepo repositories.AlertRepository) *AlertController {
+	return &AlertController{
+		alertRepo: alertRepo,
+	}
+}
+
+// GetAlerts lists the caller's alerts about their clients. ?status= is open
+// (the default, without the snoozed ones), snoozed, acknowledged, resolved or
+// all; ?userId= limits the list to one client.
+func (c *AlertController) GetAlerts(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	status := ctx.DefaultQuery("status", models.AlertOpen)
+	switch status {
+	case models.AlertOpen, models.AlertSnoozed, models.AlertAcknowledged, models.AlertResolved:
+	case "all":
+		status = ""
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+
+	var clientID int
+	if value := ctx.Query("userId"); value != "" {
+		var err error
+		if clientID, err = strconv.Atoi(value); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+	}
+
+	alerts, err := c.alertRepo.GetAlerts(ctx.Request.Context(), int(userID.(float64)), status, clientID, time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alerts"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, alerts)
+}
+
+// AcknowledgeAlert marks an open alert as handled. It is resolved once the
+// rule stops applying and opened again if it applies later.
+func (c *AlertController) AcknowledgeAlert(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	alertID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert ID"})
+		return
+	}
+
+	alert, err := c.alertRepo.AcknowledgeAlert(ctx.Request.Context(), int(userID.(float64)), alertID, time.Now())
+	if err != nil {
+		c.respondAlertError(ctx, err, "Failed to acknowledge alert")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, alert)
+}
+
+// SnoozeAlert hides an open alert for {"hours": n}, up to 30 days.
+func (c *AlertController) SnoozeAlert(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	alertID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert ID"})
+		return
+	}
+
+	var req models.SnoozeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	until := time.Now().Add(time.Duration(req.Hours) * time.Hour)
+	alert, err := c.alertRepo.SnoozeAlert(ctx.Request.Context(), int(userID.(float64)), alertID, until)
+	if err != nil {
+		c.respondAlertError(ctx, err, "Failed to snooze alert")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, alert)
+}
+
+func (c *AlertController) respondAlertError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repositories.ErrAlertNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Alert not found"})
+	case errors.Is(err, repositories.ErrAlertNotOpen):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Only open alerts can be changed"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -1,6 +1,8 @@
 package Controllers
 
 import (
+	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"log"
@@ -9,6 +11,7 @@ import (
 	"time"
 
 	config "HabitBite/backend/Config"
+	goals "HabitBite/backend/Goals"
 	middleware "HabitBite/backend/Middleware"
 	models "HabitBite/backend/Models"
 	repositories "HabitBite/backend/Repositories"
@@ -18,36 +21,56 @@ import (
 )
 
 type AuthController struct {
-	userRepo    repositories.UserRepository
-	userService *models.UserService
-	config      *config.Config
+	userRepo         repositories.UserRepository
+	userService      *models.UserService
+	refreshTokenRepo repositories.RefreshTokenRepository
+	verification     *VerificationController
+	twoFactor        *TwoFactorController
+	planner          *goals.Planner
+	config           *config.Config
 }
 
-func NewAuthController(repo repositories.UserRepository, cfg *config.Config) *AuthController {
+const (
+	// mfaTokenLifetime is how long the second step of a login can take.
+	mfaTokenLifetime = 5 * time.Minute
+	// mfaMaxAttempts is how many codes an mfaToken can be tried with.
+	mfaMaxAttempts = 5
+)
+
+func NewAuthController(repo repositories.UserRepository, refreshTokenRepo repositories.RefreshTokenRepository, verification *VerificationController, twoFactor *TwoFactorController, planner *goals.Planner, cfg *config.Config) *AuthController {
 	return &AuthController{
-		userRepo: repo,
-		config:   cfg,
+		userRepo:         repo,
+		refreshTokenRepo: refreshTokenRepo,
+		verification:     verification,
+		twoFactor:        twoFactor,
+		planner:          planner,
+		config:           cfg,
 	}
 }
 
-func NewAuthControllerWithService(service *models.UserService, cfg *config.Config) *AuthController {
+func NewAuthControllerWithService(service *models.UserService, refreshTokenRepo repositories.RefreshTokenRepository, verification *VerificationController, twoFactor *TwoFactorController, planner *goals.Planner, cfg *config.Config) *AuthController {
 	return &AuthController{
-		userService: service,
-		config:      cfg,
+		userService:      service,
+		refreshTokenRepo: refreshTokenRepo,
+		verification:     verification,
+		twoFactor:        twoFactor,
+		planner:          planner,
+		config:           cfg,
 	}
 }
 
 type RegisterRequest struct {
-	Email         string  `json:"email" binding:"required,email"`
-	Username      string  `json:"username" binding:"required,alphanum,min=3,max=50"`
-	Password      string  `json:"password" binding:"required,min=8"`
-	FullName      string  `json:"fullName" binding:"required"`
-	Birthdate     string  `json:"birthdate" binding:"required"`
-	Gender        string  `json:"gender" binding:"required,oneof=male female other"`
-	Height        float64 `json:"height" binding:"required,gt=0"`
-	Weight        float64 `json:"weight" binding:"required,gt=0"`
-	GoalType      string  `json:"goalType" binding:"required,oneof=lose gain maintain"`
-	ActivityLevel string  `json:"activityLevel" binding:"required,oneof=sedentary light moderate active very_active"`
+	Email         string   `json:"email" binding:"required,email"`
+	Username      string   `json:"username" binding:"required,alphanum,min=3,max=50"`
+	Password      string   `json:"password" binding:"required,min=8"`
+	FullName      string   `json:"fullName" binding:"required"`
+	Birthdate     string   `json:"birthdate" binding:"required"`
+	Gender        string   `json:"gender" binding:"required,oneof=male female other"`
+	Height        float64  `json:"height" binding:"required,gt=0"`
+	Weight        float64  `json:"weight" binding:"required,gt=0"`
+	BodyFat       *float64 `json:"bodyFat" binding:"omitempty,gte=3,lte=70"`
+	GoalType      string   `json:"goalType" binding:"required,oneof=lose gain maintain"`
+	ActivityLevel string   `json:"activityLevel" binding:"required,oneof=sedentary light moderate active very_active"`
 }
 
 type LoginRequest struct {
@@ -55,6 +78,14 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// LoginTwoFactorRequest completes a login that needs a second factor, with
+// either a code from the authenticator app or a recovery code.
+type LoginTwoFactorRequest struct {
+	MFAToken     string `json:"mfaToken" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
+}
+
 type AuthResponse struct {
 	User  *models.AuthUser `json:"user"`
 	Token string           `json:"token"`
@@ -104,32 +135,24 @@ func (ac *AuthController) Register(c *gin.Context) {
 		return
 	}
 
-	dailyCalorieGoal := calculateDailyCalorieGoal(
-		req.Weight,
-		req.Height,
-		req.Gender,
-		time.Now().Year()-birthdate.Year(),
-		req.ActivityLevel,
-		req.GoalType,
-	)
-
-	log.Printf("Calculated daily calorie goal: %d", dailyCalorieGoal)
-
 	user := &models.User{
-		Email:            req.Email,
-		Username:         req.Username,
-		FullName:         req.FullName,
-		Birthdate:        birthdate,
-		Gender:           req.Gender,
-		Height:           req.Height,
-		Weight:           req.Weight,
-		GoalType:         req.GoalType,
-		ActivityLevel:    req.ActivityLevel,
-		Role:             models.RoleUser,
-		CreatedAt:        time.Now(),
-		UpdatedAt:        time.Now(),
-		DailyCalorieGoal: dailyCalorieGoal,
-	}
+		Email:         req.Email,
+		Username:      req.Username,
+		FullName:      req.FullName,
+		Birthdate:     birthdate,
+		Gender:        req.Gender,
+		Height:        req.Height,
+		Weight:        req.Weight,
+		BodyFat:       req.BodyFat,
+		GoalType:      req.GoalType,
+		ActivityLevel: req.ActivityLevel,
+		Role:          models.RoleUser,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	user.DailyCalorieGoal = ac.planner.CalorieGoal(goals.ProfileOf(user, time.Now()))
+
+	log.Printf("Calculated daily calorie goal: %d", user.DailyCalorieGoal)
 
 	if err := user.SetPassword(req.Password); err != nil {
 		log.Printf("Error hashing password: %v", err)
@@ -157,7 +180,19 @@ func (ac *AuthController) Register(c *gin.Context) {
 
 	log.Printf("User created successfully with ID: %d", user.ID)
 
-	accessToken, refreshToken, err := ac.generateAuthTokens(user)
+	ac.verification.SendVerificationEmail(user)
+
+	// Unverified users can't log in under the block policy, so there is no
+	// session to start yet.
+	if ac.config.EmailVerification == config.VerificationBlock {
+		c.JSON(http.StatusCreated, gin.H{
+			"user":    user.ToAuthUser(),
+			"message": "User registered successfully, check your email to verify your address",
+		})
+		return
+	}
+
+	accessToken, refreshToken, err := ac.generateAuthTokens(c, user)
 	if err != nil {
 		log.Printf("Error generating tokens: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
@@ -215,7 +250,112 @@ func (ac *AuthController) Login(c *gin.Context) {
 		return
 	}
 
-	accessToken, refreshToken, err := ac.generateAuthTokens(user)
+	if ac.verificationBlocks(user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Please verify your email address before logging in"})
+		return
+	}
+
+	enabled, err := ac.twoFactor.IsEnabled(c.Request.Context(), user.ID)
+	if err != nil {
+		log.Printf("Error checking two-factor setup of user %d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		return
+	}
+	if enabled {
+		// The password was right, but no session starts before the second
+		// factor is checked by LoginTwoFactor.
+		mfaToken, err := ac.generateMFAToken(c.Request.Context(), user)
+		if err != nil {
+			log.Printf("Error generating MFA token: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"mfaRequired": true,
+			"mfaToken":    mfaToken,
+		})
+		return
+	}
+
+	ac.completeLogin(c, user)
+}
+
+// LoginTwoFactor is the second step of a login with two-factor
+// authentication: it exchanges the mfaToken Login returned and a valid code
+// for a session, as Login does without a second factor. An mfaToken takes
+// mfaMaxAttempts codes and works once.
+func (ac *AuthController) LoginTwoFactor(c *gin.Context) {
+	var req LoginTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Code == "" && req.RecoveryCode == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mfaToken and a code or recoveryCode are required"})
+		return
+	}
+
+	userID, challengeID, err := ac.parseMFAToken(req.MFAToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login expired, please log in again"})
+		return
+	}
+
+	reqCtx := c.Request.Context()
+	now := time.Now()
+
+	if err := ac.twoFactor.AttemptChallenge(reqCtx, challengeID, userID, mfaMaxAttempts, now); err != nil {
+		if errors.Is(err, repositories.ErrMFAChallengeInvalid) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Login expired, please log in again"})
+			return
+		}
+		log.Printf("Error checking login challenge of user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		return
+	}
+
+	if err := ac.twoFactor.VerifyCode(reqCtx, userID, req.Code, req.RecoveryCode, now); err != nil {
+		switch {
+		case errors.Is(err, errInvalidTwoFactorCode):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid two-factor code"})
+		case errors.Is(err, errTwoFactorLocked):
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many invalid codes, please try again later"})
+		default:
+			log.Printf("Error checking two-factor code of user %d: %v", userID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		}
+		return
+	}
+
+	if err := ac.twoFactor.UseChallenge(reqCtx, challengeID, now); err != nil {
+		if errors.Is(err, repositories.ErrMFAChallengeInvalid) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Login expired, please log in again"})
+			return
+		}
+		log.Printf("Error using login challenge of user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		return
+	}
+
+	var user *models.User
+	if ac.userService != nil {
+		user, err = ac.userService.FindByID(c.Request.Context(), userID)
+	} else {
+		user, err = ac.userRepo.FindByID(c.Request.Context(), userID)
+	}
+	if err != nil {
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			return
+		}
+		log.Printf("Error finding user: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		return
+	}
+
+	ac.completeLogin(c, user)
+}
+
+// completeLogin starts a session for an authenticated user and sends its
+// tokens.
+func (ac *AuthController) completeLogin(c *gin.Context, user *models.User) {
+	accessToken, refreshToken, err := ac.generateAuthTokens(c, user)
 	if err != nil {
 		log.Printf("Error generating tokens: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
@@ -237,7 +377,19 @@ func (ac *AuthController) Login(c *gin.Context) {
 	})
 }
 
+// Logout ends the session of the refresh_token cookie, if any, and clears the
+// cookies. It works without a valid access token.
 func (ac *AuthController) Logout(c *gin.Context) {
+	if refreshToken, err := c.Cookie("refresh_token"); err == nil && refreshToken != "" {
+		err := ac.refreshTokenRepo.RevokeRefreshTokenFamily(c.Request.Context(), models.HashToken(refreshToken), time.Now())
+		if err != nil && !errors.Is(err, repositories.ErrRefreshTokenInvalid) {
+			log.Printf("Error revoking refresh token: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+			return
+		}
+	}
+
+	ac.clearRefreshTokenCookie(c)
 	c.SetCookie(
 		"auth_token",
 		"",
@@ -289,25 +441,45 @@ func (ac *AuthController) GetCurrentUser(c *gin.Context) {
 	})
 }
 
+// RefreshToken exchanges the refresh_token cookie for a new access token and
+// a new refresh token. The used refresh token can't be used again: presenting
+// it a second time revokes every token of its session.
 func (ac *AuthController) RefreshToken(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+	refreshToken, err := c.Cookie("refresh_token")
+	if err != nil || refreshToken == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token required"})
 		return
 	}
-	id, ok := userID.(float64)
-	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID"})
+
+	plain, next, err := models.NewRefreshToken(0, "", time.Now())
+	if err != nil {
+		log.Printf("Error generating refresh token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+	session, err := ac.refreshTokenRepo.RotateRefreshToken(c.Request.Context(), models.HashToken(refreshToken), next, clientUserAgent(c), c.ClientIP())
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrRefreshTokenReused):
+			log.Printf("Refresh token reused, session revoked")
+			ac.clearRefreshTokenCookie(c)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session revoked, please log in again"})
+		case errors.Is(err, repositories.ErrRefreshTokenInvalid):
+			ac.clearRefreshTokenCookie(c)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		default:
+			log.Printf("Error rotating refresh token: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		}
 		return
 	}
 
 	var user *models.User
-	var err error
 
 	if ac.userService != nil {
-		user, err = ac.userService.FindByID(c.Request.Context(), int(id))
+		user, err = ac.userService.FindByID(c.Request.Context(), session.UserID)
 	} else {
-		user, err = ac.userRepo.FindByID(c.Request.Context(), int(id))
+		user, err = ac.userRepo.FindByID(c.Request.Context(), session.UserID)
 	}
 
 	if err != nil {
@@ -321,7 +493,13 @@ func (ac *AuthController) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	token, err := ac.generateJWT(user)
+	if ac.verificationBlocks(user) {
+		ac.clearRefreshTokenCookie(c)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Please verify your email address before logging in"})
+		return
+	}
+
+	token, err := ac.generateJWT(user, session.FamilyID)
 	if err != nil {
 		log.Printf("Error generating JWT: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
@@ -329,6 +507,7 @@ func (ac *AuthController) RefreshToken(c *gin.Context) {
 	}
 
 	ac.setAuthCookie(c, token)
+	ac.setRefreshTokenCookie(c, plain)
 
 	c.JSON(http.StatusOK, gin.H{
 		"token": token,
@@ -336,6 +515,12 @@ func (ac *AuthController) RefreshToken(c *gin.Context) {
 	})
 }
 
+// verificationBlocks reports whether the email verification policy keeps user
+// from logging in.
+func (ac *AuthController) verificationBlocks(user *models.User) bool {
+	return ac.config.EmailVerification == config.VerificationBlock && user.EmailVerifiedAt == nil
+}
+
 func (ac *AuthController) GetCSRFToken(c *gin.Context) {
 	if err := middleware.SetCSRFToken(c); err != nil {
 		log.Printf("Error setting CSRF token: %v", err)
@@ -348,12 +533,13 @@ func (ac *AuthController) GetCSRFToken(c *gin.Context) {
 	})
 }
 
-func (ac *AuthController) generateJWT(user *models.User) (string, error) {
+func (ac *AuthController) generateJWT(user *models.User, sessionID string) (string, error) {
 	expirationTime := time.Now().Add(ac.config.JWTExpiryDuration())
 	claims := jwt.MapClaims{
 		"sub":  user.ID,
 		"name": user.Username,
 		"role": user.Role,
+		"sid":  sessionID,
 		"iat":  time.Now().Unix(),
 		"exp":  expirationTime.Unix(),
 	}
@@ -368,6 +554,60 @@ func (ac *AuthController) generateJWT(user *models.User) (string, error) {
 	return tokenString, nil
 }
 
+// generateMFAToken returns the short-lived token of a login waiting for its
+// second factor. It is signed with a key derived from the JWT secret, so it
+// can't be used as an access token, and its jti names the stored challenge
+// that counts the codes tried with it.
+func (ac *AuthController) generateMFAToken(ctx context.Context, user *models.User) (string, error) {
+	now := time.Now()
+	challengeID, err := ac.twoFactor.StartChallenge(ctx, user.ID, mfaTokenLifetime, now)
+	if err != nil {
+		return "", err
+	}
+
+	claims := jwt.MapClaims{
+		"sub": user.ID,
+		"jti": challengeID,
+		"iat": now.Unix(),
+		"exp": now.Add(mfaTokenLifetime).Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(ac.mfaTokenKey())
+}
+
+// parseMFAToken returns the user and challenge of a valid MFA token.
+func (ac *AuthController) parseMFAToken(tokenString string) (int, string, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return ac.mfaTokenKey(), nil
+	})
+	if err != nil {
+		return 0, "", err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return 0, "", errors.New("invalid MFA token")
+	}
+	sub, ok := claims["sub"].(float64)
+	if !ok {
+		return 0, "", errors.New("invalid MFA token subject")
+	}
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		return 0, "", errors.New("invalid MFA token ID")
+	}
+	return int(sub), jti, nil
+}
+
+func (ac *AuthController) mfaTokenKey() []byte {
+	key := sha256.Sum256([]byte("habitbite-mfa-pending:" + ac.config.JWTSecret))
+	return key[:]
+}
+
 func (ac *AuthController) setAuthCookie(c *gin.Context, token string) {
 	c.SetSameSite(http.SameSiteStrictMode)
 	c.SetCookie(
@@ -388,52 +628,27 @@ func validationErrors(err error) interface{} {
 	return err.Error()
 }
 
-func calculateDailyCalorieGoal(weight, height float64, gender string, age int, activityLevel, goalType string) int {
-	heightInCm := height
-
-	// Basic BMR calculation (Mifflin-St Jeor Equation)
-	var bmr float64
-	if gender == "male" {
-		bmr = 10*weight + 6.25*heightInCm - 5*float64(age) + 5
-	} else {
-		bmr = 10*weight + 6.25*heightInCm - 5*float64(age) - 161
-	}
-
-	// Activity multiplier
-	activityMultiplier := 1.2
-	switch activityLevel {
-	case "light":
-		activityMultiplier = 1.375
-	case "moderate":
-		activityMultiplier = 1.55
-	case "active":
-		activityMultiplier = 1.725
-	case "very_active":
-		activityMultiplier = 1.9
+// generateAuthTokens starts a new session for the client of c and returns
+// its access token and first refresh token.
+func (ac *AuthController) generateAuthTokens(c *gin.Context, user *models.User) (string, string, error) {
+	now := time.Now()
+	refreshToken, stored, err := models.NewRefreshToken(user.ID, "", now)
+	if err != nil {
+		return "", "", err
 	}
-
-	// Calculate TDEE (Total Daily Energy Expenditure)
-	tdee := bmr * activityMultiplier
-
-	// Adjust based on goal
-	switch goalType {
-	case "lose":
-		tdee -= 500 // 500 calorie deficit
-	case "gain":
-		tdee += 500 // 500 calorie surplus
+	session := &models.Session{
+		UserID:     user.ID,
+		FamilyID:   stored.FamilyID,
+		UserAgent:  clientUserAgent(c),
+		IPAddress:  c.ClientIP(),
+		CreatedAt:  now,
+		LastUsedAt: now,
 	}
-
-	return int(tdee)
-}
-
-func (ac *AuthController) generateAuthTokens(user *models.User) (string, string, error) {
-	accessToken, err := ac.generateAccessToken(user)
-	if err != nil {
+	if err := ac.refreshTokenRepo.CreateSession(c.Request.Context(), session, stored); err != nil {
 		return "", "", err
 	}
 
-	// Generate refresh token
-	refreshToken, err := ac.generateRefreshToken(user)
+	accessToken, err := ac.generateAccessToken(user, session.FamilyID)
 	if err != nil {
 		return "", "", err
 	}
@@ -441,11 +656,22 @@ func (ac *AuthController) generateAuthTokens(user *models.User) (string, string,
 	return accessToken, refreshToken, nil
 }
 
-func (ac *AuthController) generateAccessToken(user *models.User) (string, error) {
+// clientUserAgent returns the User-Agent header, cut to the length sessions
+// store.
+func clientUserAgent(c *gin.Context) string {
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > 255 {
+		userAgent = strings.ToValidUTF8(userAgent[:255], "")
+	}
+	return userAgent
+}
+
+func (ac *AuthController) generateAccessToken(user *models.User, sessionID string) (string, error) {
 	claims := jwt.MapClaims{
 		"sub":   user.ID,
 		"email": user.Email,
 		"role":  user.Role,
+		"sid":   sessionID,
 		"exp":   time.Now().Add(time.Hour * 24).Unix(),
 	}
 
@@ -453,22 +679,11 @@ func (ac *AuthController) generateAccessToken(user *models.User) (string, error)
 	return token.SignedString([]byte(ac.config.JWTSecret))
 }
 
-func (ac *AuthController) generateRefreshToken(user *models.User) (string, error) {
-	claims := jwt.MapClaims{
-		"sub":  user.ID,
-		"type": "refresh",
-		"exp":  time.Now().Add(time.Hour * 24 * 7).Unix(),
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(ac.config.JWTSecret))
-}
-
 func (ac *AuthController) setRefreshTokenCookie(c *gin.Context, token string) {
 	c.SetCookie(
 		"refresh_token",
 		token,
-		7*24*60*60,
+		int(models.RefreshTokenLifetime.Seconds()),
 		"/",
 		"",
 		true, // secure
@@ -476,6 +691,10 @@ func (ac *AuthController) setRefreshTokenCookie(c *gin.Context, token string) {
 	)
 }
 
+func (ac *AuthController) clearRefreshTokenCookie(c *gin.Context) {
+	c.SetCookie("refresh_token", "", -1, "/", "", true, true)
+}
+
 func (ac *AuthController) GetUserGoals(c *gin.Context) {
 	userIDValue, exists := c.Get("userID")
 	if !exists {
@@ -555,79 +774,3 @@ func (ac *AuthController) UpdateUserGoals(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Goals updated successfully", "goals": goals})
 }
-
-func (ac *AuthController) RecalculateAllUserGoals(c *gin.Context) {
-	userRole, exists := c.Get("userRole")
-	if !exists || userRole != models.RoleAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
-		return
-	}
-	maxUserID := 100
-	updatedCount := 0
-	failedCount := 0
-	for userID := 1; userID <= maxUserID; userID++ {
-		user, err := ac.userRepo.FindByID(c.Request.Context(), userID)
-		if err != nil {
-			if errors.Is(err, repositories.ErrUserNotFound) {
-				continue
-			}
-			log.Printf("Error finding user %d: %v", userID, err)
-			failedCount++
-			continue
-		}
-
-		// Calculate macronutrient targets based on goal type
-		var targetProtein, targetCarbs, targetFats float64
-		caloriesFromProtein := 4.0 // 4 calories per gram of protein
-		caloriesFromCarbs := 4.0   // 4 calories per gram of carbs
-		caloriesFromFats := 9.0    // 9 calories per gram of fat
-
-		switch user.GoalType {
-		case "lose":
-			// For weight loss: protein 35%, fats 35%, carbs 30%
-			targetProtein = float64(user.DailyCalorieGoal) * 0.35 / caloriesFromProtein
-			targetFats = float64(user.DailyCalorieGoal) * 0.35 / caloriesFromFats
-			targetCarbs = float64(user.DailyCalorieGoal) * 0.30 / caloriesFromCarbs
-		case "maintain":
-			// For weight maintenance: protein 25%, fats 25%, carbs 50%
-			targetProtein = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromProtein
-			targetFats = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromFats
-			targetCarbs = float64(user.DailyCalorieGoal) * 0.50 / caloriesFromCarbs
-		case "gain":
-			// For weight gain: protein 30%, fats 25%, carbs 45%
-			targetProtein = float64(user.DailyCalorieGoal) * 0.30 / caloriesFromProtein
-			targetFats = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromFats
-			targetCarbs = float64(user.DailyCalorieGoal) * 0.45 / caloriesFromCarbs
-		default:
-			// Default to maintenance if goal type is invalid
-			targetProtein = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromProtein
-			targetFats = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromFats
-			targetCarbs = float64(user.DailyCalorieGoal) * 0.50 / caloriesFromCarbs
-		}
-
-		// Create or update user goals
-		goals := &models.UserGoals{
-			UserID:         user.ID,
-			TargetCalories: user.DailyCalorieGoal,
-			TargetProtein:  targetProtein,
-			TargetCarbs:    targetCarbs,
-			TargetFats:     targetFats,
-			TargetWeight:   user.Weight,
-		}
-
-		// Use the repository's UpdateUserGoals method
-		err = ac.userRepo.UpdateUserGoals(c.Request.Context(), goals)
-		if err != nil {
-			log.Printf("Error updating goals for user %d: %v", user.ID, err)
-			failedCount++
-		} else {
-			updatedCount++
-		}
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"message": "User goals recalculated",
-		"updated": updatedCount,
-		"failed":  failedCount,
-	})
-}
@@ -0,0 +1,277 @@
+package Controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	models "HabitBite/backend/Models"
+	repositories "HabitBite/backend/Repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxCommentRange limits how many days of comments one request can list.
+const maxCommentRange = 92
+
+type CommentController struct {
+	commentRepo   repositories.CommentRepository
+	foodEntryRepo repositories.FoodEntryRepository
+	userRepo      repositories.UserRepository
+}
+
+func NewCommentController(commentRepo repositories.CommentRepository, foodEntryRepo repositories.FoodEntryRepository, userRepo repositories.UserRepository) *CommentController {
+	return &CommentController{
+		commentRepo:   commentRepo,
+		foodEntryRepo: foodEntryRepo,
+		userRepo:      userRepo,
+	}
+}
+
+// GetComments lists the threads on the caller's food log from ?from= to
+// ?to=, both YYYY-MM-DD and today by default.
+func (c *CommentController) GetComments(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	c.getComments(ctx, int(userID.(float64)), 0)
+}
+
+// Reply answers a dietitian's thread on the caller's food log. The
+// dietitian must still be subscribed.
+func (c *CommentController) Reply(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	clientID := int(userID.(float64))
+
+	thread, ok := c.findThread(ctx, clientID, 0)
+	if !ok {
+		return
+	}
+
+	isSubscribed, err := c.userRepo.IsUserSubscribedToDietitian(ctx.Request.Context(), strconv.Itoa(clientID), thread.DietitianID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check subscription"})
+		return
+	}
+	if !isSubscribed {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "You are no longer subscribed to this dietitian"})
+		return
+	}
+
+	c.reply(ctx, thread, clientID)
+}
+
+// DeleteComment deletes one of the caller's replies on their food log.
+func (c *CommentController) DeleteComment(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	clientID := int(userID.(float64))
+
+	c.deleteComment(ctx, clientID, clientID, 0)
+}
+
+// DietitianGetComments lists the caller's threads on a client's food log.
+func (c *CommentController) DietitianGetComments(ctx *gin.Context) {
+	if dietitianID, clientID, ok := authorizeDietitian(ctx, c.userRepo); ok {
+		c.getComments(ctx, clientID, dietitianID)
+	}
+}
+
+// DietitianCreateComment starts a thread on one of the client's food entries
+// or, without an entryId, on the given date.
+func (c *CommentController) DietitianCreateComment(ctx *gin.Context) {
+	dietitianID, clientID, ok := authorizeDietitian(ctx, c.userRepo)
+	if !ok {
+		return
+	}
+
+	var req models.CommentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	comment := &models.Comment{
+		UserID:      clientID,
+		DietitianID: dietitianID,
+		AuthorID:    dietitianID,
+		Body:        req.Body,
+	}
+	switch {
+	case req.EntryID != nil:
+		if _, err := c.foodEntryRepo.GetFoodEntry(ctx.Request.Context(), clientID, *req.EntryID); err != nil {
+			if errors.Is(err, repositories.ErrFoodEntryNotFound) || errors.Is(err, repositories.ErrFoodEntryForbidden) {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "Food entry not found"})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch food entry"})
+			return
+		}
+		comment.EntryID = req.EntryID
+	case req.Date != "":
+		comment.Date = req.Date
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Either entryId or date is required"})
+		return
+	}
+
+	if err := c.commentRepo.CreateComment(ctx.Request.Context(), comment); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add comment"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, comment)
+}
+
+// DietitianReply answers in one of the caller's threads.
+func (c *CommentController) DietitianReply(ctx *gin.Context) {
+	dietitianID, clientID, ok := authorizeDietitian(ctx, c.userRepo)
+	if !ok {
+		return
+	}
+
+	if thread, ok := c.findThread(ctx, clientID, dietitianID); ok {
+		c.reply(ctx, thread, dietitianID)
+	}
+}
+
+// DietitianDeleteComment deletes one of the caller's comments; deleting the
+// comment that started a thread deletes its replies too.
+func (c *CommentController) DietitianDeleteComment(ctx *gin.Context) {
+	if dietitianID, clientID, ok := authorizeDietitian(ctx, c.userRepo); ok {
+		c.deleteComment(ctx, clientID, dietitianID, dietitianID)
+	}
+}
+
+// getComments responds with the threads on the owner's log, limited to those
+// of dietitianID unless it is 0.
+func (c *CommentController) getComments(ctx *gin.Context, ownerID, dietitianID int) {
+	from := models.DateOnly(time.Now())
+	to := from
+	var err error
+	if value := ctx.Query("from"); value != "" {
+		if from, err = time.Parse("2006-01-02", value); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+			return
+		}
+	}
+	if value := ctx.Query("to"); value != "" {
+		if to, err = time.Parse("2006-01-02", value); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+			return
+		}
+	}
+	if to.Before(from) || to.Sub(from) >= maxCommentRange*24*time.Hour {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Date range must span 1 to 92 days"})
+		return
+	}
+
+	comments, err := c.commentRepo.GetComments(ctx.Request.Context(), ownerID, dietitianID, from, to)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.CommentThreads(comments))
+}
+
+// findThread loads the thread the :commentId parameter belongs to. Replying
+// to a reply answers in its thread, since threads are one level deep.
+func (c *CommentController) findThread(ctx *gin.Context, ownerID, dietitianID int) (*models.Comment, bool) {
+	commentID, err := strconv.Atoi(ctx.Param("commentId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return nil, false
+	}
+
+	comment, err := c.commentRepo.GetComment(ctx.Request.Context(), ownerID, commentID)
+	if err == nil && comment.ParentID != nil {
+		comment, err = c.commentRepo.GetComment(ctx.Request.Context(), ownerID, *comment.ParentID)
+	}
+	if err != nil {
+		if errors.Is(err, repositories.ErrCommentNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+			return nil, false
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comment"})
+		return nil, false
+	}
+	if dietitianID != 0 && comment.DietitianID != dietitianID {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return nil, false
+	}
+
+	return comment, true
+}
+
+func (c *CommentController) reply(ctx *gin.Context, thread *models.Comment, authorID int) {
+	var req models.ReplyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	reply := &models.Comment{
+		UserID:      thread.UserID,
+		DietitianID: thread.DietitianID,
+		AuthorID:    authorID,
+		ParentID:    &thread.ID,
+		EntryID:     thread.EntryID,
+		Date:        thread.Date,
+		Body:        req.Body,
+	}
+	if err := c.commentRepo.CreateComment(ctx.Request.Context(), reply); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add reply"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, reply)
+}
+
+func (c *CommentController) deleteComment(ctx *gin.Context, ownerID, authorID, dietitianID int) {
+	commentID, err := strconv.Atoi(ctx.Param("commentId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	comment, err := c.commentRepo.GetComment(ctx.Request.Context(), ownerID, commentID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrCommentNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comment"})
+		return
+	}
+	if dietitianID != 0 && comment.DietitianID != dietitianID {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+	if comment.AuthorID != authorID {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own comments"})
+		return
+	}
+
+	if err := c.commentRepo.DeleteComment(ctx.Request.Context(), ownerID, commentID); err != nil {
+		if errors.Is(err, repositories.ErrCommentNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comment"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
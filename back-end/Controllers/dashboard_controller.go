@@ -0,0 +1,282 @@
+package Controllers
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	models "HabitBite/backend/Models"
+	repositories "HabitBite/backend/Repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dashboardDays is the longest period the dashboard averages over.
+const dashboardDays = 30
+
+type DashboardController struct {
+	userRepo      repositories.UserRepository
+	foodEntryRepo repositories.FoodEntryRepository
+	weightRepo    repositories.WeightRepository
+	alertRepo     repositories.AlertRepository
+}
+
+func NewDashboardController(userRepo repositories.UserRepository, foodEntryRepo repositories.FoodEntryRepository, weightRepo repositories.WeightRepository, alertRepo repositories.AlertRepository) *DashboardController {
+	return &DashboardController{
+		userRepo:      userRepo,
+		foodEntryRepo: foodEntryRepo,
+		weightRepo:    weightRepo,
+		alertRepo:     alertRepo,
+	}
+}
+
+// dashboardFilter holds the query options of GetDashboard.
+type dashboardFilter struct {
+	search       string
+	hasAlerts    *bool
+	inactiveDays int
+	maxAdherence *float64
+	sort         string
+	descending   bool
+}
+
+// GetDashboard returns one row per client of the caller. The whole caseload
+// is loaded in a fixed number of queries, whatever its size.
+//
+// Options: ?q= matches the name or email; ?hasAlerts=true|false; ?inactiveDays=n
+// keeps clients who have not logged for n days or more; ?maxAdherence=0.8
+// keeps clients whose 7-day calorie adherence is at most the value; ?sort=
+// name (default), lastLog, adherence, weightChange or alerts, with
+// ?order=asc (default) or desc. Clients without a value sort last.
+func (c *DashboardController) GetDashboard(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	dietitianID := int(userID.(float64))
+
+	filter, ok := parseDashboardFilter(ctx)
+	if !ok {
+		return
+	}
+
+	reqCtx := ctx.Request.Context()
+	now := time.Now()
+	today := models.DateOnly(now)
+	end := today.AddDate(0, 0, -1)
+	start := today.AddDate(0, 0, -dashboardDays)
+
+	clients, err := c.userRepo.GetSubscribedUsers(reqCtx, dietitianID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get subscribed users"})
+		return
+	}
+	userIDs := make([]int, len(clients))
+	for i, client := range clients {
+		userIDs[i] = client.ID
+	}
+
+	goals, err := c.userRepo.GetGoalsForUsers(reqCtx, userIDs)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch goals"})
+		return
+	}
+	totals, err := c.foodEntryRepo.GetDailyTotalsForUsers(reqCtx, userIDs, start, end)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch nutrition history"})
+		return
+	}
+	lastLogs, err := c.foodEntryRepo.GetLastLogDates(reqCtx, userIDs, today)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch nutrition history"})
+		return
+	}
+	weights, err := c.weightRepo.GetWeightEntriesForUsers(reqCtx, userIDs, now.AddDate(0, 0, -dashboardDays))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch weight entries"})
+		return
+	}
+	alerts, err := c.alertRepo.GetAlerts(reqCtx, dietitianID, models.AlertOpen, 0, now)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alerts"})
+		return
+	}
+
+	days := make(map[int]map[string]models.DailyTotals)
+	for _, day := range totals {
+		if days[day.UserID] == nil {
+			days[day.UserID] = make(map[string]models.DailyTotals)
+		}
+		days[day.UserID][day.Date] = day
+	}
+	firstWeight := make(map[int]models.WeightEntry)
+	lastWeight := make(map[int]models.WeightEntry)
+	for _, entry := range weights {
+		if _, ok := firstWeight[entry.UserID]; !ok {
+			firstWeight[entry.UserID] = entry
+		}
+		lastWeight[entry.UserID] = entry
+	}
+	alertRules := make(map[int][]string)
+	for _, alert := range alerts {
+		alertRules[alert.UserID] = append(alertRules[alert.UserID], alert.Rule)
+	}
+
+	rows := []models.DashboardRow{}
+	for _, client := range clients {
+		row := models.DashboardRow{
+			UserID:     client.ID,
+			FullName:   client.FullName,
+			Email:      client.Email,
+			Weight:     client.Weight,
+			OpenAlerts: len(alertRules[client.ID]),
+			AlertRules: alertRules[client.ID],
+		}
+		if row.AlertRules == nil {
+			row.AlertRules = []string{}
+		}
+
+		var clientGoals *models.UserGoals
+		if stored, ok := goals[client.ID]; ok {
+			clientGoals = &stored
+		}
+		row.Adherence7 = models.Adherence(clientGoals, days[client.ID], today.AddDate(0, 0, -7), end)
+		row.Adherence30 = models.Adherence(clientGoals, days[client.ID], start, end)
+
+		if lastLog, ok := lastLogs[client.ID]; ok {
+			date := lastLog.Format("2006-01-02")
+			row.LastLogDate = &date
+		}
+		if first, last := firstWeight[client.ID], lastWeight[client.ID]; last.ID != first.ID {
+			change := math.Round((last.Weight-first.Weight)*100) / 100
+			row.WeightChange = &change
+		}
+
+		if filter.matches(&row, lastLogs, today) {
+			rows = append(rows, row)
+		}
+	}
+
+	sortDashboard(rows, filter.sort, filter.descending)
+
+	ctx.JSON(http.StatusOK, rows)
+}
+
+func parseDashboardFilter(ctx *gin.Context) (*dashboardFilter, bool) {
+	filter := &dashboardFilter{
+		search: strings.ToLower(strings.TrimSpace(ctx.Query("q"))),
+		sort:   ctx.DefaultQuery("sort", "name"),
+	}
+
+	if value := ctx.Query("hasAlerts"); value != "" {
+		hasAlerts, err := strconv.ParseBool(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid hasAlerts value"})
+			return nil, false
+		}
+		filter.hasAlerts = &hasAlerts
+	}
+	if value := ctx.Query("inactiveDays"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "inactiveDays must be a positive number"})
+			return nil, false
+		}
+		filter.inactiveDays = days
+	}
+	if value := ctx.Query("maxAdherence"); value != "" {
+		adherence, err := strconv.ParseFloat(value, 64)
+		if err != nil || adherence < 0 || adherence > 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "maxAdherence must be between 0 and 1"})
+			return nil, false
+		}
+		filter.maxAdherence = &adherence
+	}
+
+	switch filter.sort {
+	case "name", "lastLog", "adherence", "weightChange", "alerts":
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort field"})
+		return nil, false
+	}
+	switch ctx.DefaultQuery("order", "asc") {
+	case "asc":
+	case "desc":
+		filter.descending = true
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort order"})
+		return nil, false
+	}
+
+	return filter, true
+}
+
+func (f *dashboardFilter) matches(row *models.DashboardRow, lastLogs map[int]time.Time, today time.Time) bool {
+	if f.search != "" &&
+		!strings.Contains(strings.ToLower(row.FullName), f.search) &&
+		!strings.Contains(strings.ToLower(row.Email), f.search) {
+		return false
+	}
+	if f.hasAlerts != nil && *f.hasAlerts != (row.OpenAlerts > 0) {
+		return false
+	}
+	if f.inactiveDays > 0 {
+		if lastLog, ok := lastLogs[row.UserID]; ok && lastLog.After(today.AddDate(0, 0, -f.inactiveDays)) {
+			return false
+		}
+	}
+	if f.maxAdherence != nil {
+		if row.Adherence7.Calories == nil || *row.Adherence7.Calories > *f.maxAdherence {
+			return false
+		}
+	}
+	return true
+}
+
+// sortDashboard orders rows by field, then by name. Rows without a value for
+// field come last in both orders.
+func sortDashboard(rows []models.DashboardRow, field string, descending bool) {
+	key := func(row *models.DashboardRow) (float64, bool) {
+		switch field {
+		case "lastLog":
+			if row.LastLogDate == nil {
+				return 0, false
+			}
+			date, _ := time.Parse("2006-01-02", *row.LastLogDate)
+			return float64(date.Unix()), true
+		case "adherence":
+			if row.Adherence7.Calories == nil {
+				return 0, false
+			}
+			return *row.Adherence7.Calories, true
+		case "weightChange":
+			if row.WeightChange == nil {
+				return 0, false
+			}
+			return *row.WeightChange, true
+		case "alerts":
+			return float64(row.OpenAlerts), true
+		}
+		return 0, true
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, aOK := key(&rows[i])
+		b, bOK := key(&rows[j])
+		if aOK != bOK {
+			return aOK
+		}
+		if a != b {
+			return (a < b) != descending
+		}
+		nameA, nameB := strings.ToLower(rows[i].FullName), strings.ToLower(rows[j].FullName)
+		if field == "name" && descending {
+			return nameA > nameB
+		}
+		return nameA < nameB
+	})
+}
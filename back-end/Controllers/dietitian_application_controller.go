@@ -0,0 +1,253 @@
+package Controllers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	models "HabitBite/backend/Models"
+	repositories "HabitBite/backend/Repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DietitianApplicationController struct {
+	dietitianRepo repositories.DietitianRepository
+	userRepo      repositories.UserRepository
+	auditRepo     repositories.AuditRepository
+}
+
+func NewDietitianApplicationController(dietitianRepo repositories.DietitianRepository, userRepo repositories.UserRepository, auditRepo repositories.AuditRepository) *DietitianApplicationController {
+	return &DietitianApplicationController{
+		dietitianRepo: dietitianRepo,
+		userRepo:      userRepo,
+		auditRepo:     auditRepo,
+	}
+}
+
+// Apply submits the caller's application to become a dietitian.
+func (c *DietitianApplicationController) Apply(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	var req models.DietitianApplicationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	user, err := c.userRepo.FindByID(ctx.Request.Context(), int(userID.(float64)))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if user.Role != models.RoleUser {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Only regular users can apply to become a dietitian"})
+		return
+	}
+
+	application := &models.DietitianApplication{
+		UserID:        user.ID,
+		Credentials:   req.Credentials,
+		LicenseNumber: req.LicenseNumber,
+		Bio:           req.Bio,
+	}
+	if err := c.dietitianRepo.CreateApplication(ctx.Request.Context(), application); err != nil {
+		if errors.Is(err, repositories.ErrApplicationPending) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "You already have an application waiting for review"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit application"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, application)
+}
+
+// GetMyApplications lists the caller's applications, newest first.
+func (c *DietitianApplicationController) GetMyApplications(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	applications, err := c.dietitianRepo.GetUserApplications(ctx.Request.Context(), int(userID.(float64)))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch applications"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, applications)
+}
+
+// GetApplications lists applications for review, optionally filtered with
+// ?status=pending|approved|rejected.
+func (c *DietitianApplicationController) GetApplications(ctx *gin.Context) {
+	status := ctx.Query("status")
+	switch status {
+	case "", models.ApplicationPending, models.ApplicationApproved, models.ApplicationRejected:
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+
+	applications, err := c.dietitianRepo.GetApplications(ctx.Request.Context(), status)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch applications"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, applications)
+}
+
+func (c *DietitianApplicationController) GetApplication(ctx *gin.Context) {
+	applicationID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	application, err := c.dietitianRepo.GetApplication(ctx.Request.Context(), applicationID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrApplicationNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch application"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, application)
+}
+
+// ApproveApplication makes the applicant a dietitian and publishes their
+// profile. The reason is optional.
+func (c *DietitianApplicationController) ApproveApplication(ctx *gin.Context) {
+	c.review(ctx, models.AuditApplicationApprove)
+}
+
+// RejectApplication declines an application. A reason is required so the
+// applicant knows what to fix before applying again.
+func (c *DietitianApplicationController) RejectApplication(ctx *gin.Context) {
+	c.review(ctx, models.AuditApplicationReject)
+}
+
+func (c *DietitianApplicationController) review(ctx *gin.Context, action string) {
+	reviewerID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	applicationID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	var req models.ApplicationReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+	if action == models.AuditApplicationReject && req.Reason == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required to reject an application"})
+		return
+	}
+
+	var application *models.DietitianApplication
+	if action == models.AuditApplicationApprove {
+		application, err = c.dietitianRepo.ApproveApplication(ctx.Request.Context(), applicationID, int(reviewerID.(float64)), req.Reason)
+	} else {
+		application, err = c.dietitianRepo.RejectApplication(ctx.Request.Context(), applicationID, int(reviewerID.(float64)), req.Reason)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrApplicationNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		case errors.Is(err, repositories.ErrApplicationNotPending):
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Application has already been reviewed"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to review application"})
+		}
+		return
+	}
+
+	audit := &models.AuditEntry{
+		ActorID:      int(reviewerID.(float64)),
+		ActorRole:    ctx.GetString("userRole"),
+		Action:       action,
+		TargetUserID: application.UserID,
+		EntityID:     &application.ID,
+		IPAddress:    ctx.ClientIP(),
+	}
+	if err := c.auditRepo.RecordAudit(ctx.Request.Context(), audit); err != nil {
+		// The review is already committed, so only log the failure.
+		log.Printf("Failed to record audit entry for %s by user %d: %v", action, audit.ActorID, err)
+	}
+
+	ctx.JSON(http.StatusOK, application)
+}
+
+// GetDietitianProfile returns the public profile of a dietitian.
+func (c *DietitianApplicationController) GetDietitianProfile(ctx *gin.Context) {
+	dietitianID, err := strconv.Atoi(ctx.Param("dietitianId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dietitian ID"})
+		return
+	}
+
+	profile, err := c.dietitianRepo.GetProfile(ctx.Request.Context(), dietitianID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrDietitianProfileMissing) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Dietitian profile not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch dietitian profile"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, profile)
+}
+
+// UpdateProfile lets a dietitian edit their own public profile and client
+// capacity. The license number is kept from the approved application.
+func (c *DietitianApplicationController) UpdateProfile(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	var req models.DietitianProfileRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	profile, err := c.dietitianRepo.GetProfile(ctx.Request.Context(), int(userID.(float64)))
+	if err != nil {
+		if !errors.Is(err, repositories.ErrDietitianProfileMissing) {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch dietitian profile"})
+			return
+		}
+		profile = &models.DietitianProfile{UserID: int(userID.(float64))}
+	}
+
+	profile.DisplayName = req.DisplayName
+	profile.Credentials = req.Credentials
+	profile.Bio = req.Bio
+	profile.ClientCapacity = req.ClientCapacity
+	if err := c.dietitianRepo.SaveProfile(ctx.Request.Context(), profile); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update dietitian profile"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, profile)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	Models "HabitBite/backend/Models"
 	Repositories "HabitBite/backend/Repositories"
@@ -13,12 +14,14 @@ import (
 )
 
 type DietitianController struct {
-	userRepo Repositories.UserRepository
+	userRepo    Repositories.UserRepository
+	commentRepo Repositories.CommentRepository
 }
 
-func NewDietitianController(userRepo Repositories.UserRepository) *DietitianController {
+func NewDietitianController(userRepo Repositories.UserRepository, commentRepo Repositories.CommentRepository) *DietitianController {
 	return &DietitianController{
-		userRepo: userRepo,
+		userRepo:    userRepo,
+		commentRepo: commentRepo,
 	}
 }
 
@@ -155,10 +158,10 @@ func (dc *DietitianController) SubscribeToDietitian(c *gin.Context) {
 
 	fmt.Printf("Attempting to subscribe user %d to dietitian %d\n", userID, dietitianID)
 
-	err = dc.userRepo.SubscribeUserToDietitian(c.Request.Context(), userID, dietitianID)
+	subscription, err := dc.userRepo.SubscribeUserToDietitian(c.Request.Context(), userID, dietitianID)
 	if err != nil {
 		fmt.Printf("Error subscribing user to dietitian: %v\n", err)
-		if errors.Is(err, errors.New("dietitian not found")) {
+		if errors.Is(err, Repositories.ErrDietitianNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Dietitian not found"})
 			return
 		}
@@ -166,7 +169,11 @@ func (dc *DietitianController) SubscribeToDietitian(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Successfully subscribed to dietitian"})
+	if subscription.Status == Models.SubscriptionAccepted {
+		c.JSON(http.StatusOK, gin.H{"message": "Already subscribed to dietitian", "subscription": subscription})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "Subscription request sent to dietitian", "subscription": subscription})
 }
 
 func (dc *DietitianController) UnsubscribeFromDietitian(c *gin.Context) {
@@ -204,6 +211,10 @@ func (dc *DietitianController) UnsubscribeFromDietitian(c *gin.Context) {
 	// Unsubscribe user from dietitian
 	err = dc.userRepo.UnsubscribeUserFromDietitian(c.Request.Context(), userID, dietitianID)
 	if err != nil {
+		if errors.Is(err, Repositories.ErrSubscriptionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not subscribed to this dietitian"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unsubscribe from dietitian"})
 		return
 	}
@@ -212,49 +223,8 @@ func (dc *DietitianController) UnsubscribeFromDietitian(c *gin.Context) {
 }
 
 func (dc *DietitianController) GetUserGoals(c *gin.Context) {
-	userIDValue, exists := c.Get("userID")
-	if !exists {
-		fmt.Println("User ID not found in context")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	var dietitianID int
-	switch v := userIDValue.(type) {
-	case float64:
-		dietitianID = int(v)
-	case string:
-		id, err := strconv.Atoi(v)
-		if err != nil {
-			fmt.Printf("Error converting string userID to int: %v\n", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
-			return
-		}
-		dietitianID = id
-	case int:
-		dietitianID = v
-	default:
-		fmt.Printf("Unexpected userID type: %T\n", userIDValue)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
-		return
-	}
-
-	if dietitianID == 0 {
-		fmt.Println("Dietitian ID is zero after conversion")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	userIDParam := c.Param("userId")
-	userID, err := strconv.Atoi(userIDParam)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
-		return
-	}
-
-	isSubscribed, err := dc.userRepo.IsUserSubscribedToDietitian(c.Request.Context(), userIDParam, dietitianID)
-	if err != nil || !isSubscribed {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized to view this user's goals"})
+	_, userID, ok := authorizeDietitian(c, dc.userRepo)
+	if !ok {
 		return
 	}
 
@@ -287,49 +257,8 @@ func (dc *DietitianController) GetUserGoals(c *gin.Context) {
 }
 
 func (dc *DietitianController) UpdateUserGoals(c *gin.Context) {
-	userIDValue, exists := c.Get("userID")
-	if !exists {
-		fmt.Println("User ID not found in context")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	var dietitianID int
-	switch v := userIDValue.(type) {
-	case float64:
-		dietitianID = int(v)
-	case string:
-		id, err := strconv.Atoi(v)
-		if err != nil {
-			fmt.Printf("Error converting string userID to int: %v\n", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
-			return
-		}
-		dietitianID = id
-	case int:
-		dietitianID = v
-	default:
-		fmt.Printf("Unexpected userID type: %T\n", userIDValue)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
-		return
-	}
-
-	if dietitianID == 0 {
-		fmt.Println("Dietitian ID is zero after conversion")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	userIDParam := c.Param("userId")
-	userID, err := strconv.Atoi(userIDParam)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
-		return
-	}
-
-	isSubscribed, err := dc.userRepo.IsUserSubscribedToDietitian(c.Request.Context(), userIDParam, dietitianID)
-	if err != nil || !isSubscribed {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized to update this user's goals"})
+	_, userID, ok := authorizeDietitian(c, dc.userRepo)
+	if !ok {
 		return
 	}
 
@@ -358,8 +287,7 @@ func (dc *DietitianController) UpdateUserGoals(c *gin.Context) {
 	}
 
 	// Update the user's goals
-	err = dc.userRepo.UpdateUserGoals(c.Request.Context(), goals)
-	if err != nil {
+	if err := dc.userRepo.UpdateUserGoals(c.Request.Context(), goals); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user goals"})
 		return
 	}
@@ -428,49 +356,11 @@ func (dc *DietitianController) UpdateUserGoals(c *gin.Context) {
 }
 
 func (dc *DietitianController) GetUserProgress(c *gin.Context) {
-	// Handle different possible types from JWT claims
-	userIDValue, exists := c.Get("userID")
-	if !exists {
-		fmt.Println("User ID not found in context")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	// Convert userID to int based on its type
-	var dietitianID int
-	switch v := userIDValue.(type) {
-	case float64:
-		dietitianID = int(v)
-	case string:
-		id, err := strconv.Atoi(v)
-		if err != nil {
-			fmt.Printf("Error converting string userID to int: %v\n", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
-			return
-		}
-		dietitianID = id
-	case int:
-		dietitianID = v
-	default:
-		fmt.Printf("Unexpected userID type: %T\n", userIDValue)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
-		return
-	}
-
-	if dietitianID == 0 {
-		fmt.Println("Dietitian ID is zero after conversion")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	userID := c.Param("userId")
-
-	// Verify the user is subscribed to this dietitian
-	isSubscribed, err := dc.userRepo.IsUserSubscribedToDietitian(c.Request.Context(), userID, dietitianID)
-	if err != nil || !isSubscribed {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized to view this user's progress"})
+	dietitianID, userIDInt, ok := authorizeDietitian(c, dc.userRepo)
+	if !ok {
 		return
 	}
+	userID := strconv.Itoa(userIDInt)
 
 	// Get user's progress (nutrition history, weight changes, etc.)
 	progress, err := dc.userRepo.GetUserProgress(c.Request.Context(), userID)
@@ -480,7 +370,6 @@ func (dc *DietitianController) GetUserProgress(c *gin.Context) {
 	}
 
 	// Get user details to include in the response
-	userIDInt, _ := strconv.Atoi(userID)
 	user, err := dc.userRepo.FindByID(c.Request.Context(), userIDInt)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user details"})
@@ -499,6 +388,21 @@ func (dc *DietitianController) GetUserProgress(c *gin.Context) {
 			nutritionHistory["fats"] = []float64{}
 		}
 
+		// The dietitian's threads on the days of the history
+		comments := []Models.Comment{}
+		if dates, ok := nutritionHistory["dates"].([]string); ok && len(dates) > 0 {
+			from, errFrom := time.Parse("2006-01-02", dates[0])
+			to, errTo := time.Parse("2006-01-02", dates[len(dates)-1])
+			if errFrom == nil && errTo == nil {
+				flat, err := dc.commentRepo.GetComments(c.Request.Context(), userIDInt, dietitianID, from, to)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get comments"})
+					return
+				}
+				comments = Models.CommentThreads(flat)
+			}
+		}
+
 		camelCaseProgress := gin.H{
 			"nutritionHistory": gin.H{
 				"dates":    nutritionHistory["dates"],
@@ -514,6 +418,7 @@ func (dc *DietitianController) GetUserProgress(c *gin.Context) {
 				"goalType":         user.GoalType,
 				"dailyCalorieGoal": user.DailyCalorieGoal,
 			},
+			"comments": comments,
 		}
 		c.JSON(http.StatusOK, camelCaseProgress)
 	} else {
@@ -532,7 +437,150 @@ func (dc *DietitianController) GetUserProgress(c *gin.Context) {
 				"goalType":         user.GoalType,
 				"dailyCalorieGoal": user.DailyCalorieGoal,
 			},
+			"comments": []Models.Comment{},
 		}
 		c.JSON(http.StatusOK, defaultProgress)
 	}
 }
+
+// GetMySubscriptions lists the caller's subscriptions to dietitians in every
+// state, so a client can see whether a request is still pending.
+func (dc *DietitianController) GetMySubscriptions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	subscriptions, err := dc.userRepo.GetUserSubscriptions(c.Request.Context(), int(userID.(float64)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get subscriptions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+// GetSubscriptionRequests lists the pending requests of the calling
+// dietitian, oldest first.
+func (dc *DietitianController) GetSubscriptionRequests(c *gin.Context) {
+	dietitianID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	requests, err := dc.userRepo.GetSubscriptionRequests(c.Request.Context(), int(dietitianID.(float64)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get subscription requests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, requests)
+}
+
+func (dc *DietitianController) AcceptSubscription(c *gin.Context) {
+	dc.respondToSubscription(c, true)
+}
+
+func (dc *DietitianController) DeclineSubscription(c *gin.Context) {
+	dc.respondToSubscription(c, false)
+}
+
+func (dc *DietitianController) respondToSubscription(c *gin.Context, accept bool) {
+	dietitianID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	subscription, err := dc.userRepo.RespondToSubscription(c.Request.Context(), userID, int(dietitianID.(float64)), accept)
+	if err != nil {
+		switch {
+		case errors.Is(err, Repositories.ErrSubscriptionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Subscription request not found"})
+		case errors.Is(err, Repositories.ErrSubscriptionNotPending):
+			c.JSON(http.StatusConflict, gin.H{"error": "Subscription request has already been answered"})
+		case errors.Is(err, Repositories.ErrDietitianAtCapacity):
+			c.JSON(http.StatusConflict, gin.H{"error": "You have reached your client capacity"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to answer subscription request"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// EndSubscription lets a dietitian end the relationship with a client, or
+// withdraw from a request they have not answered.
+func (dc *DietitianController) EndSubscription(c *gin.Context) {
+	dietitianID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	err = dc.userRepo.UnsubscribeUserFromDietitian(c.Request.Context(), userID, int(dietitianID.(float64)))
+	if err != nil {
+		if errors.Is(err, Repositories.ErrSubscriptionNotFound) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "User is not subscribed to you"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to end subscription"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Subscription ended"})
+}
+
+// authorizeDietitian resolves the client named by the userId parameter and
+// checks that they have an accepted subscription to the calling dietitian.
+// It writes the error response itself and returns false when the request
+// must not continue.
+func authorizeDietitian(c *gin.Context, userRepo Repositories.UserRepository) (int, int, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return 0, 0, false
+	}
+	dietitianID := int(userID.(float64))
+
+	clientID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return 0, 0, false
+	}
+
+	if !requireClient(c, userRepo, clientID, dietitianID) {
+		return 0, 0, false
+	}
+	return dietitianID, clientID, true
+}
+
+// requireClient checks that the client has an accepted subscription to the
+// dietitian and otherwise answers with 403, like authorizeDietitian.
+func requireClient(c *gin.Context, userRepo Repositories.UserRepository, clientID, dietitianID int) bool {
+	isSubscribed, err := userRepo.IsUserSubscribedToDietitian(c.Request.Context(), strconv.Itoa(clientID), dietitianID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check subscription"})
+		return false
+	}
+	if !isSubscribed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "User is not subscribed to you"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package Controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	models "HabitBite/backend/Models"
+	repositories "HabitBite/backend/Repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultFoodSearchPageSize = 10
+	maxFoodSearchPageSize     = 50
+)
+
+type FoodController struct {
+	foodRepo repositories.FoodRepository
+}
+
+func NewFoodController(repo repositories.FoodRepository) *FoodController {
+	return &FoodController{
+		foodRepo: repo,
+	}
+}
+
+// SearchFoods searches the local food catalog. The response has the same
+// shape the front-end used to build from the USDA search API, plus
+// totalHitsCapped, set when only the best candidates were ranked and
+// totalHits and totalPages undercount the matches.
+func (fc *FoodController) SearchFoods(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Search query is required"})
+		return
+	}
+
+	page := 1
+	if pageStr := c.Query("page"); pageStr != "" {
+		p, err := strconv.Atoi(pageStr)
+		if err != nil || p < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+			return
+		}
+		page = p
+	}
+
+	pageSize := defaultFoodSearchPageSize
+	if pageSizeStr := c.Query("pageSize"); pageSizeStr != "" {
+		ps, err := strconv.Atoi(pageSizeStr)
+		if err != nil || ps < 1 || ps > maxFoodSearchPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page size"})
+			return
+		}
+		pageSize = ps
+	}
+	// Only the best SearchCandidateLimit matches are ranked, so later pages
+	// are always empty.
+	if page > repositories.SearchCandidateLimit/pageSize+1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+
+	result, err := fc.foodRepo.SearchFoods(c.Request.Context(), query, page, pageSize)
+	if err != nil {
+		log.Printf("Error searching foods: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search foods"})
+		return
+	}
+
+	results := make([]*models.FDCSearchFood, 0, len(result.Foods))
+	for _, food := range result.Foods {
+		results = append(results, food.ToSearchFood())
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"foods":           results,
+		"totalHits":       result.TotalHits,
+		"totalHitsCapped": result.Capped,
+		"currentPage":     page,
+		"totalPages":      (result.TotalHits + pageSize - 1) / pageSize,
+	})
+}
@@ -1,7 +1,9 @@
 package Controllers
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
 	"time"
@@ -14,11 +16,19 @@ import (
 
 type FoodEntryController struct {
 	foodEntryRepo repositories.FoodEntryRepository
+	foodRepo      repositories.FoodRepository
+	userRepo      repositories.UserRepository
+	auditRepo     repositories.AuditRepository
+	commentRepo   repositories.CommentRepository
 }
 
-func NewFoodEntryController(repo repositories.FoodEntryRepository) *FoodEntryController {
+func NewFoodEntryController(repo repositories.FoodEntryRepository, foodRepo repositories.FoodRepository, userRepo repositories.UserRepository, auditRepo repositories.AuditRepository, commentRepo repositories.CommentRepository) *FoodEntryController {
 	return &FoodEntryController{
 		foodEntryRepo: repo,
+		foodRepo:      foodRepo,
+		userRepo:      userRepo,
+		auditRepo:     auditRepo,
+		commentRepo:   commentRepo,
 	}
 }
 
@@ -36,17 +46,39 @@ func (c *FoodEntryController) AddFoodEntry(ctx *gin.Context) {
 		return
 	}
 
+	if req.Meal == "" {
+		req.Meal = models.MealSnack
+	}
+	if !models.IsValidMeal(req.Meal) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meal type"})
+		return
+	}
+
+	fdcID, err := strconv.Atoi(req.FoodID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid food ID"})
+		return
+	}
+
+	food, err := c.foodRepo.FindByFdcID(ctx.Request.Context(), fdcID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrFoodNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Food not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up food"})
+		return
+	}
+
 	entry := &models.FoodEntry{
-		UserID:   int(userID.(float64)),
-		FoodID:   req.FoodID,
-		Name:     req.Name,
-		Amount:   req.Amount,
-		Calories: req.Calories,
-		Protein:  req.Protein,
-		Carbs:    req.Carbs,
-		Fat:      req.Fat,
-		Date:     req.Date,
+		UserID: int(userID.(float64)),
+		FoodID: req.FoodID,
+		Name:   food.Description,
+		Amount: req.Amount,
+		Meal:   req.Meal,
+		Date:   req.Date,
 	}
+	entry.Calories, entry.Protein, entry.Carbs, entry.Fat = food.NutritionFor(req.Amount)
 
 	if err := c.foodEntryRepo.CreateFoodEntry(ctx.Request.Context(), entry); err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add food entry"})
@@ -63,6 +95,10 @@ func (c *FoodEntryController) GetDailyEntries(ctx *gin.Context) {
 		return
 	}
 
+	c.getDailyEntries(ctx, int(userID.(float64)))
+}
+
+func (c *FoodEntryController) getDailyEntries(ctx *gin.Context, ownerID int) {
 	dateStr := ctx.Query("date")
 	if dateStr == "" {
 		dateStr = time.Now().Format("2006-01-02")
@@ -70,17 +106,11 @@ func (c *FoodEntryController) GetDailyEntries(ctx *gin.Context) {
 
 	date, err := time.Parse("2006-01-02", dateStr)
 	if err != nil {
-		fmt.Printf("[ERROR GetDailyEntries] Invalid date format: %v\n", err)
+		log.Printf("Invalid date %q for daily entries: %v", dateStr, err)
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
 		return
 	}
 
-	entries, err := c.foodEntryRepo.GetDailyEntries(ctx.Request.Context(), int(userID.(float64)), date)
-	if err != nil {
-		fmt.Printf("[ERROR GetDailyEntries] Error fetching entries: %v\n", err)
-		ctx.JSON(http.StatusOK, []interface{}{})
-		return
-	}
 	type EntryResponse struct {
 		ID        int       `json:"id"`
 		FoodName  string    `json:"food_name"`
@@ -89,12 +119,55 @@ func (c *FoodEntryController) GetDailyEntries(ctx *gin.Context) {
 		Protein   float64   `json:"protein"`
 		Carbs     float64   `json:"carbs"`
 		Fat       float64   `json:"fat"`
+		Meal      string    `json:"meal"`
 		EntryDate time.Time `json:"entry_date"`
+
+		Comments []models.Comment `json:"comments,omitempty"`
+	}
+
+	type MealResponse struct {
+		Meal    string          `json:"meal"`
+		Entries []EntryResponse `json:"entries"`
+	}
+
+	meals := make([]*MealResponse, 0, len(models.MealTypes))
+	mealIndex := make(map[string]*MealResponse, len(models.MealTypes))
+	for _, mealType := range models.MealTypes {
+		meal := &MealResponse{Meal: mealType, Entries: []EntryResponse{}}
+		meals = append(meals, meal)
+		mealIndex[mealType] = meal
+	}
+
+	// Comments are shown to the client and, of those, a dietitian sees their
+	// own threads.
+	scope := 0
+	if actorID, _ := ctx.Get("userID"); ctx.GetString("userRole") == models.RoleDietitian && int(actorID.(float64)) != ownerID {
+		scope = int(actorID.(float64))
+	}
+	comments, err := c.commentRepo.GetComments(ctx.Request.Context(), ownerID, scope, date, date)
+	if err != nil {
+		log.Printf("Failed to fetch comments for user %d on %s: %v", ownerID, dateStr, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get comments"})
+		return
+	}
+	dayComments, entryComments := models.GroupComments(models.CommentThreads(comments))
+	if dayComments[dateStr] == nil {
+		dayComments[dateStr] = []models.Comment{}
+	}
+
+	entries, err := c.foodEntryRepo.GetDailyEntries(ctx.Request.Context(), ownerID, date)
+	if err != nil {
+		log.Printf("Failed to fetch entries for user %d on %s: %v", ownerID, dateStr, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get food entries"})
+		return
 	}
 
-	var response []EntryResponse
 	for _, e := range entries {
-		response = append(response, EntryResponse{
+		meal, ok := mealIndex[e.Meal]
+		if !ok {
+			meal = mealIndex[models.MealSnack]
+		}
+		meal.Entries = append(meal.Entries, EntryResponse{
 			ID:        e.ID,
 			FoodName:  e.Name,
 			Quantity:  e.Amount,
@@ -102,11 +175,13 @@ func (c *FoodEntryController) GetDailyEntries(ctx *gin.Context) {
 			Protein:   e.Protein,
 			Carbs:     e.Carbs,
 			Fat:       e.Fat,
+			Meal:      e.Meal,
 			EntryDate: e.Date,
+			Comments:  entryComments[e.ID],
 		})
 	}
 
-	ctx.JSON(http.StatusOK, response)
+	ctx.JSON(http.StatusOK, gin.H{"date": dateStr, "meals": meals, "comments": dayComments[dateStr]})
 }
 
 func (c *FoodEntryController) GetDailyNutrition(ctx *gin.Context) {
@@ -136,10 +211,114 @@ func (c *FoodEntryController) GetDailyNutrition(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, nutrition)
 }
 
+// UpdateFoodEntry edits the amount, meal, date or food of an existing entry
+func (c *FoodEntryController) UpdateFoodEntry(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	entryID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entry ID"})
+		return
+	}
+
+	c.updateFoodEntry(ctx, int(userID.(float64)), entryID)
+}
+
+func (c *FoodEntryController) updateFoodEntry(ctx *gin.Context, ownerID, entryID int) {
+	var req models.FoodEntryUpdateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	entry, err := c.foodEntryRepo.GetFoodEntry(ctx.Request.Context(), ownerID, entryID)
+	if err != nil {
+		respondFoodEntryError(ctx, err, "Failed to get food entry")
+		return
+	}
+
+	if req.Meal != nil {
+		if !models.IsValidMeal(*req.Meal) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meal type"})
+			return
+		}
+		entry.Meal = *req.Meal
+	}
+
+	if req.Date != nil {
+		entry.Date = *req.Date
+	}
+
+	if req.FoodID != nil && *req.FoodID != entry.FoodID {
+		fdcID, err := strconv.Atoi(*req.FoodID)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid food ID"})
+			return
+		}
+
+		food, err := c.foodRepo.FindByFdcID(ctx.Request.Context(), fdcID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrFoodNotFound) {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "Food not found"})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up food"})
+			return
+		}
+
+		if req.Amount != nil {
+			entry.Amount = *req.Amount
+		}
+		entry.FoodID = *req.FoodID
+		entry.Name = food.Description
+		entry.Calories, entry.Protein, entry.Carbs, entry.Fat = food.NutritionFor(entry.Amount)
+	} else if req.Amount != nil {
+		if err := rescaleEntry(ctx.Request.Context(), c.foodRepo, entry, *req.Amount); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up food"})
+			return
+		}
+	}
+
+	if err := c.foodEntryRepo.UpdateFoodEntry(ctx.Request.Context(), entry); err != nil {
+		respondFoodEntryError(ctx, err, "Failed to update food entry")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entry)
+}
+
+// rescaleEntry changes the amount of the entry and recalculates its macros
+// from the catalog food, so repeated edits don't compound rounding. Entries
+// whose food is not in the catalog are scaled from their stored values.
+func rescaleEntry(ctx context.Context, foodRepo repositories.FoodRepository, entry *models.FoodEntry, amount float64) error {
+	fdcID, err := strconv.Atoi(entry.FoodID)
+	if err != nil {
+		entry.Rescale(amount)
+		return nil
+	}
+
+	food, err := foodRepo.FindByFdcID(ctx, fdcID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrFoodNotFound) {
+			entry.Rescale(amount)
+			return nil
+		}
+		return err
+	}
+
+	entry.Amount = amount
+	entry.Calories, entry.Protein, entry.Carbs, entry.Fat = food.NutritionFor(amount)
+	return nil
+}
+
 // DeleteFoodEntry deletes a food entry
 func (c *FoodEntryController) DeleteFoodEntry(ctx *gin.Context) {
-	// Verify user is authenticated
-	if _, exists := ctx.Get("userID"); !exists {
+	userID, exists := ctx.Get("userID")
+	if !exists {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
 		return
 	}
@@ -150,14 +329,31 @@ func (c *FoodEntryController) DeleteFoodEntry(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.foodEntryRepo.DeleteFoodEntry(ctx.Request.Context(), entryID); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete food entry"})
+	c.deleteFoodEntry(ctx, int(userID.(float64)), entryID)
+}
+
+func (c *FoodEntryController) deleteFoodEntry(ctx *gin.Context, ownerID, entryID int) {
+	if err := c.foodEntryRepo.DeleteFoodEntry(ctx.Request.Context(), ownerID, entryID); err != nil {
+		respondFoodEntryError(ctx, err, "Failed to delete food entry")
 		return
 	}
 
 	ctx.Status(http.StatusNoContent)
 }
 
+// respondFoodEntryError maps repository errors to a response: 404 when the
+// entry does not exist, 403 when it belongs to a different user.
+func respondFoodEntryError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repositories.ErrFoodEntryNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Food entry not found"})
+	case errors.Is(err, repositories.ErrFoodEntryForbidden):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Food entry belongs to another user"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
 // GetNutritionHistory retrieves nutrition data for a date range
 func (c *FoodEntryController) GetNutritionHistory(ctx *gin.Context) {
 	userID, exists := ctx.Get("userID")
@@ -209,3 +405,131 @@ func (c *FoodEntryController) GetNutritionHistory(ctx *gin.Context) {
 	}
 	ctx.JSON(http.StatusOK, history)
 }
+
+// Admins can manage the food log of any user and dietitians the logs of
+// their subscribed clients. These routes take the owner from the path, and
+// every access is written to the audit log before it is carried out.
+
+// AdminGetDailyEntries returns a user's entries for a day on behalf of an admin
+func (c *FoodEntryController) AdminGetDailyEntries(ctx *gin.Context) {
+	ownerID, ok := c.authorizeOverride(ctx, "id", models.AuditFoodEntryRead, nil)
+	if !ok {
+		return
+	}
+	c.getDailyEntries(ctx, ownerID)
+}
+
+// AdminUpdateFoodEntry edits a user's entry on behalf of an admin
+func (c *FoodEntryController) AdminUpdateFoodEntry(ctx *gin.Context) {
+	entryID, err := strconv.Atoi(ctx.Param("entryId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entry ID"})
+		return
+	}
+
+	ownerID, ok := c.authorizeOverride(ctx, "id", models.AuditFoodEntryUpdate, &entryID)
+	if !ok {
+		return
+	}
+	c.updateFoodEntry(ctx, ownerID, entryID)
+}
+
+// AdminDeleteFoodEntry deletes a user's entry on behalf of an admin
+func (c *FoodEntryController) AdminDeleteFoodEntry(ctx *gin.Context) {
+	entryID, err := strconv.Atoi(ctx.Param("entryId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entry ID"})
+		return
+	}
+
+	ownerID, ok := c.authorizeOverride(ctx, "id", models.AuditFoodEntryDelete, &entryID)
+	if !ok {
+		return
+	}
+	c.deleteFoodEntry(ctx, ownerID, entryID)
+}
+
+// DietitianGetDailyEntries returns a client's entries for a day
+func (c *FoodEntryController) DietitianGetDailyEntries(ctx *gin.Context) {
+	ownerID, ok := c.authorizeOverride(ctx, "userId", models.AuditFoodEntryRead, nil)
+	if !ok {
+		return
+	}
+	c.getDailyEntries(ctx, ownerID)
+}
+
+// DietitianUpdateFoodEntry edits an entry in a client's food log
+func (c *FoodEntryController) DietitianUpdateFoodEntry(ctx *gin.Context) {
+	entryID, err := strconv.Atoi(ctx.Param("entryId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entry ID"})
+		return
+	}
+
+	ownerID, ok := c.authorizeOverride(ctx, "userId", models.AuditFoodEntryUpdate, &entryID)
+	if !ok {
+		return
+	}
+	c.updateFoodEntry(ctx, ownerID, entryID)
+}
+
+// DietitianDeleteFoodEntry deletes an entry from a client's food log
+func (c *FoodEntryController) DietitianDeleteFoodEntry(ctx *gin.Context) {
+	entryID, err := strconv.Atoi(ctx.Param("entryId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entry ID"})
+		return
+	}
+
+	ownerID, ok := c.authorizeOverride(ctx, "userId", models.AuditFoodEntryDelete, &entryID)
+	if !ok {
+		return
+	}
+	c.deleteFoodEntry(ctx, ownerID, entryID)
+}
+
+// authorizeOverride resolves the user named by the path parameter, checks
+// that the caller may act on their food log and records the access. It
+// writes the error response itself and returns false when the request must
+// not continue.
+func (c *FoodEntryController) authorizeOverride(ctx *gin.Context, userParam, action string, entryID *int) (int, bool) {
+	actorID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return 0, false
+	}
+	actorRole := ctx.GetString("userRole")
+
+	ownerID, err := strconv.Atoi(ctx.Param(userParam))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return 0, false
+	}
+
+	switch actorRole {
+	case models.RoleAdmin:
+	case models.RoleDietitian:
+		if !requireClient(ctx, c.userRepo, ownerID, int(actorID.(float64))) {
+			return 0, false
+		}
+	default:
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		return 0, false
+	}
+
+	audit := &models.AuditEntry{
+		ActorID:      int(actorID.(float64)),
+		ActorRole:    actorRole,
+		Action:       action,
+		TargetUserID: ownerID,
+		EntityID:     entryID,
+		IPAddress:    ctx.ClientIP(),
+	}
+	if err := c.auditRepo.RecordAudit(ctx.Request.Context(), audit); err != nil {
+		log.Printf("Failed to record audit entry for %s by user %d: %v", action, audit.ActorID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record access"})
+		return 0, false
+	}
+
+	return ownerID, true
+}
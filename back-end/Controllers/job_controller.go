@@ -0,0 +1,105 @@
+package Controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	jobs "HabitBite/backend/Jobs"
+	models "HabitBite/backend/Models"
+	repositories "HabitBite/backend/Repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JobController struct {
+	runner  *jobs.Runner
+	jobRepo repositories.JobRepository
+}
+
+func NewJobController(runner *jobs.Runner, jobRepo repositories.JobRepository) *JobController {
+	return &JobController{
+		runner:  runner,
+		jobRepo: jobRepo,
+	}
+}
+
+// StartRecalculateGoals queues a goal recalculation and returns the job to
+// poll with GetJob. The body is optional: {"dryRun": true, "role": "user"}.
+func (c *JobController) StartRecalculateGoals(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	var params models.RecalculateGoalsParams
+	if err := ctx.ShouldBindJSON(&params); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	job, err := c.runner.StartRecalculateGoals(ctx.Request.Context(), int(userID.(float64)), params)
+	if err != nil {
+		if errors.Is(err, jobs.ErrJobActive) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "A goal recalculation is already in progress"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start goal recalculation"})
+		return
+	}
+
+	ctx.Header("Location", "/api/admin/jobs/"+strconv.Itoa(job.ID))
+	ctx.JSON(http.StatusAccepted, job)
+}
+
+// StartEvaluateAlerts runs the nightly alert evaluation now. The body is
+// optional: {"asOf": "2024-05-01"} evaluates the rules on that day instead of
+// yesterday.
+func (c *JobController) StartEvaluateAlerts(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	var params models.EvaluateAlertsParams
+	if err := ctx.ShouldBindJSON(&params); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	job, err := c.runner.StartEvaluateAlerts(ctx.Request.Context(), int(userID.(float64)), params)
+	if err != nil {
+		if errors.Is(err, jobs.ErrJobActive) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "An alert evaluation is already in progress"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start alert evaluation"})
+		return
+	}
+
+	ctx.Header("Location", "/api/admin/jobs/"+strconv.Itoa(job.ID))
+	ctx.JSON(http.StatusAccepted, job)
+}
+
+func (c *JobController) GetJob(ctx *gin.Context) {
+	jobID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	job, err := c.jobRepo.GetJob(ctx.Request.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrJobNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch job"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
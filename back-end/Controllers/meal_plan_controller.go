@@ -0,0 +1,454 @@
+package Controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	models "HabitBite/backend/Models"
+	repositories "HabitBite/backend/Repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxMealPlanLength limits how long a plan can be assigned for, in days.
+const maxMealPlanLength = 366
+
+type MealPlanController struct {
+	mealPlanRepo  repositories.MealPlanRepository
+	foodRepo      repositories.FoodRepository
+	foodEntryRepo repositories.FoodEntryRepository
+	userRepo      repositories.UserRepository
+}
+
+func NewMealPlanController(mealPlanRepo repositories.MealPlanRepository, foodRepo repositories.FoodRepository, foodEntryRepo repositories.FoodEntryRepository, userRepo repositories.UserRepository) *MealPlanController {
+	return &MealPlanController{
+		mealPlanRepo:  mealPlanRepo,
+		foodRepo:      foodRepo,
+		foodEntryRepo: foodEntryRepo,
+		userRepo:      userRepo,
+	}
+}
+
+// GetMealPlans lists the caller's meal plans without their items.
+func (c *MealPlanController) GetMealPlans(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	c.getMealPlans(ctx, int(userID.(float64)))
+}
+
+func (c *MealPlanController) GetMealPlan(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	if plan, ok := c.findMealPlan(ctx, int(userID.(float64))); ok {
+		ctx.JSON(http.StatusOK, plan)
+	}
+}
+
+// GetTodayPlan returns the items planned for the caller on ?date=YYYY-MM-DD,
+// today by default, grouped by meal and marked as logged or not.
+func (c *MealPlanController) GetTodayPlan(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	ownerID := int(userID.(float64))
+
+	date := time.Now()
+	if dateStr := ctx.Query("date"); dateStr != "" {
+		parsed, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+			return
+		}
+		date = parsed
+	}
+
+	plan, err := c.mealPlanRepo.GetActiveMealPlan(ctx.Request.Context(), ownerID, date)
+	if err != nil {
+		if errors.Is(err, repositories.ErrMealPlanNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "No meal plan for this date"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch meal plan"})
+		return
+	}
+
+	entries, err := c.foodEntryRepo.GetEntriesByDay(ctx.Request.Context(), ownerID, date, date)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch food entries"})
+		return
+	}
+
+	key := date.Format("2006-01-02")
+	items := plan.ItemsFor(date)
+	matched := models.MatchPlannedItems(items, entries[key])
+
+	meals := make([]models.PlannedMeal, 0, len(models.MealTypes))
+	mealIndex := make(map[string]int, len(models.MealTypes))
+	for _, mealType := range models.MealTypes {
+		mealIndex[mealType] = len(meals)
+		meals = append(meals, models.PlannedMeal{Meal: mealType, Items: []models.PlannedItem{}})
+	}
+	for i, item := range items {
+		planned := models.PlannedItem{MealPlanItem: item}
+		if matched[i] != nil {
+			planned.Logged = true
+			planned.FoodEntryID = &matched[i].ID
+		}
+		meal := &meals[mealIndex[item.Meal]]
+		meal.Items = append(meal.Items, planned)
+	}
+
+	day, _ := plan.DayOf(date)
+	plan.Items = nil
+	ctx.JSON(http.StatusOK, models.DayPlan{Date: key, Day: day, Plan: plan, Meals: meals})
+}
+
+// LogPlanItem adds a planned item to the caller's food log. The item must be
+// planned for the date of the entry; the amount defaults to the planned one.
+func (c *MealPlanController) LogPlanItem(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	ownerID := int(userID.(float64))
+
+	itemID, err := strconv.Atoi(ctx.Param("itemId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		return
+	}
+
+	var req models.LogPlanItemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	item, err := c.mealPlanRepo.GetMealPlanItem(ctx.Request.Context(), ownerID, itemID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrMealPlanItemNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Meal plan item not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch meal plan item"})
+		return
+	}
+
+	plan, err := c.mealPlanRepo.GetMealPlan(ctx.Request.Context(), ownerID, item.PlanID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch meal plan"})
+		return
+	}
+
+	// Entries for another day are logged at the current time of day on it.
+	date := time.Now()
+	if req.Date != "" {
+		day, _ := time.ParseInLocation("2006-01-02", req.Date, time.Local)
+		date = time.Date(day.Year(), day.Month(), day.Day(), date.Hour(), date.Minute(), date.Second(), 0, time.Local)
+	}
+	if day, ok := plan.DayOf(date); !ok || day != item.Day {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "This item is not planned for that date"})
+		return
+	}
+
+	entry := &models.FoodEntry{
+		UserID:   ownerID,
+		FoodID:   item.FoodID,
+		Name:     item.Name,
+		Amount:   item.Amount,
+		Calories: item.Calories,
+		Protein:  item.Protein,
+		Carbs:    item.Carbs,
+		Fat:      item.Fat,
+		Meal:     item.Meal,
+		Date:     date,
+	}
+	if req.Amount != nil {
+		if err := rescaleEntry(ctx.Request.Context(), c.foodRepo, entry, *req.Amount); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up food"})
+			return
+		}
+	}
+
+	if err := c.foodEntryRepo.CreateFoodEntry(ctx.Request.Context(), entry); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add food entry"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, entry)
+}
+
+// GetAdherence compares one of the caller's plans with their food log.
+func (c *MealPlanController) GetAdherence(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	c.getAdherence(ctx, int(userID.(float64)))
+}
+
+func (c *MealPlanController) DietitianGetMealPlans(ctx *gin.Context) {
+	if _, clientID, ok := authorizeDietitian(ctx, c.userRepo); ok {
+		c.getMealPlans(ctx, clientID)
+	}
+}
+
+func (c *MealPlanController) DietitianGetMealPlan(ctx *gin.Context) {
+	_, clientID, ok := authorizeDietitian(ctx, c.userRepo)
+	if !ok {
+		return
+	}
+
+	if plan, ok := c.findMealPlan(ctx, clientID); ok {
+		ctx.JSON(http.StatusOK, plan)
+	}
+}
+
+func (c *MealPlanController) DietitianGetAdherence(ctx *gin.Context) {
+	if _, clientID, ok := authorizeDietitian(ctx, c.userRepo); ok {
+		c.getAdherence(ctx, clientID)
+	}
+}
+
+// DietitianCreateMealPlan assigns a new plan to a subscribed client.
+func (c *MealPlanController) DietitianCreateMealPlan(ctx *gin.Context) {
+	dietitianID, clientID, ok := authorizeDietitian(ctx, c.userRepo)
+	if !ok {
+		return
+	}
+
+	plan, ok := c.bindMealPlan(ctx)
+	if !ok {
+		return
+	}
+	plan.UserID = clientID
+	plan.DietitianID = dietitianID
+
+	if err := c.mealPlanRepo.CreateMealPlan(ctx.Request.Context(), plan); err != nil {
+		respondMealPlanError(ctx, err, "Failed to create meal plan")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, plan)
+}
+
+// DietitianUpdateMealPlan replaces a plan the caller wrote, items included.
+func (c *MealPlanController) DietitianUpdateMealPlan(ctx *gin.Context) {
+	dietitianID, clientID, ok := authorizeDietitian(ctx, c.userRepo)
+	if !ok {
+		return
+	}
+
+	existing, ok := c.findMealPlan(ctx, clientID)
+	if !ok {
+		return
+	}
+	if existing.DietitianID != dietitianID {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Meal plan was written by another dietitian"})
+		return
+	}
+
+	plan, ok := c.bindMealPlan(ctx)
+	if !ok {
+		return
+	}
+	plan.ID = existing.ID
+	plan.UserID = clientID
+	plan.DietitianID = dietitianID
+
+	if err := c.mealPlanRepo.ReplaceMealPlan(ctx.Request.Context(), plan); err != nil {
+		respondMealPlanError(ctx, err, "Failed to update meal plan")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, plan)
+}
+
+func (c *MealPlanController) DietitianDeleteMealPlan(ctx *gin.Context) {
+	dietitianID, clientID, ok := authorizeDietitian(ctx, c.userRepo)
+	if !ok {
+		return
+	}
+
+	existing, ok := c.findMealPlan(ctx, clientID)
+	if !ok {
+		return
+	}
+	if existing.DietitianID != dietitianID {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Meal plan was written by another dietitian"})
+		return
+	}
+
+	if err := c.mealPlanRepo.DeleteMealPlan(ctx.Request.Context(), clientID, existing.ID); err != nil {
+		respondMealPlanError(ctx, err, "Failed to delete meal plan")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Meal plan deleted successfully"})
+}
+
+func (c *MealPlanController) getMealPlans(ctx *gin.Context, ownerID int) {
+	plans, err := c.mealPlanRepo.GetMealPlans(ctx.Request.Context(), ownerID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch meal plans"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, plans)
+}
+
+func (c *MealPlanController) findMealPlan(ctx *gin.Context, ownerID int) (*models.MealPlan, bool) {
+	planID, err := strconv.Atoi(ctx.Param("planId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meal plan ID"})
+		return nil, false
+	}
+
+	plan, err := c.mealPlanRepo.GetMealPlan(ctx.Request.Context(), ownerID, planID)
+	if err != nil {
+		respondMealPlanError(ctx, err, "Failed to fetch meal plan")
+		return nil, false
+	}
+	return plan, true
+}
+
+// getAdherence reports adherence from ?from to ?to, YYYY-MM-DD, clamped to
+// the plan. The range defaults to the start of the plan up to today.
+func (c *MealPlanController) getAdherence(ctx *gin.Context, ownerID int) {
+	plan, ok := c.findMealPlan(ctx, ownerID)
+	if !ok {
+		return
+	}
+
+	from, to := models.DateOnly(plan.StartDate), models.DateOnly(plan.EndDate)
+	if today := models.DateOnly(time.Now()); today.Before(to) {
+		to = today
+	}
+	var err error
+	if value := ctx.Query("from"); value != "" {
+		if from, err = time.Parse("2006-01-02", value); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+			return
+		}
+	}
+	if value := ctx.Query("to"); value != "" {
+		if to, err = time.Parse("2006-01-02", value); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+			return
+		}
+	}
+	if from.Before(models.DateOnly(plan.StartDate)) {
+		from = models.DateOnly(plan.StartDate)
+	}
+	if to.After(models.DateOnly(plan.EndDate)) {
+		to = models.DateOnly(plan.EndDate)
+	}
+
+	entries, err := c.foodEntryRepo.GetEntriesByDay(ctx.Request.Context(), ownerID, from, to)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch food entries"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, plan.Adherence(entries, from, to))
+}
+
+// bindMealPlan validates a MealPlanRequest and resolves its foods from the
+// catalog, copying their name and macros into the items.
+func (c *MealPlanController) bindMealPlan(ctx *gin.Context) (*models.MealPlan, bool) {
+	var req models.MealPlanRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return nil, false
+	}
+
+	startDate, _ := time.Parse("2006-01-02", req.StartDate)
+	endDate, _ := time.Parse("2006-01-02", req.EndDate)
+	if endDate.Before(startDate) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "End date must not be before start date"})
+		return nil, false
+	}
+	if endDate.Sub(startDate).Hours()/24 >= maxMealPlanLength {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Meal plans can last at most a year"})
+		return nil, false
+	}
+
+	plan := &models.MealPlan{
+		Name:      req.Name,
+		Notes:     req.Notes,
+		Days:      req.Days,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Items:     make([]models.MealPlanItem, 0, len(req.Items)),
+	}
+
+	foods := make(map[int]*models.Food)
+	for _, itemReq := range req.Items {
+		if itemReq.Day > req.Days {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Item day is beyond the length of the plan"})
+			return nil, false
+		}
+		if !models.IsValidMeal(itemReq.Meal) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meal type"})
+			return nil, false
+		}
+
+		fdcID, err := strconv.Atoi(itemReq.FoodID)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid food ID"})
+			return nil, false
+		}
+		food, ok := foods[fdcID]
+		if !ok {
+			food, err = c.foodRepo.FindByFdcID(ctx.Request.Context(), fdcID)
+			if err != nil {
+				if errors.Is(err, repositories.ErrFoodNotFound) {
+					ctx.JSON(http.StatusNotFound, gin.H{"error": "Food not found: " + itemReq.FoodID})
+					return nil, false
+				}
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up food"})
+				return nil, false
+			}
+			foods[fdcID] = food
+		}
+
+		item := models.MealPlanItem{
+			Day:    itemReq.Day,
+			Meal:   itemReq.Meal,
+			FoodID: itemReq.FoodID,
+			Name:   food.Description,
+			Amount: itemReq.Amount,
+		}
+		item.Calories, item.Protein, item.Carbs, item.Fat = food.NutritionFor(itemReq.Amount)
+		plan.Items = append(plan.Items, item)
+	}
+
+	return plan, true
+}
+
+func respondMealPlanError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repositories.ErrMealPlanNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Meal plan not found"})
+	case errors.Is(err, repositories.ErrMealPlanOverlap):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Meal plan overlaps another plan of this client"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
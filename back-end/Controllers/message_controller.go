@@ -0,0 +1,332 @@
+package Controllers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	messaging "HabitBite/backend/Messaging"
+	models "HabitBite/backend/Models"
+	repositories "HabitBite/backend/Repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultMessagePageSize = 50
+	maxMessagePageSize     = 100
+
+	// streamHeartbeat is how often a stream sends a ping event, so proxies
+	// keep an idle connection open. Each heartbeat also checks that the
+	// caller may still read the conversation.
+	streamHeartbeat = 25 * time.Second
+)
+
+type MessageController struct {
+	messageRepo      repositories.MessageRepository
+	userRepo         repositories.UserRepository
+	refreshTokenRepo repositories.RefreshTokenRepository
+	hub              *messaging.Hub
+}
+
+func NewMessageController(messageRepo repositories.MessageRepository, userRepo repositories.UserRepository, refreshTokenRepo repositories.RefreshTokenRepository, hub *messaging.Hub) *MessageController {
+	return &MessageController{
+		messageRepo:      messageRepo,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		hub:              hub,
+	}
+}
+
+// GetConversations lists the caller's conversations with the last message
+// and the number of messages they have not read.
+func (c *MessageController) GetConversations(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	conversations, err := c.messageRepo.GetConversations(ctx.Request.Context(), int(userID.(float64)))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch conversations"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, conversations)
+}
+
+// StartConversation returns the conversation between the caller and one of
+// their dietitians or, for a dietitian, one of their clients, creating it if
+// needed. The subscription between them must be accepted.
+func (c *MessageController) StartConversation(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	callerID := int(userID.(float64))
+
+	var req models.ConversationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	clientID, dietitianID := callerID, req.ParticipantID
+	if ctx.GetString("userRole") == models.RoleDietitian {
+		clientID, dietitianID = req.ParticipantID, callerID
+	}
+	if !c.checkSubscribed(ctx, clientID, dietitianID) {
+		return
+	}
+
+	conversation, err := c.messageRepo.GetOrCreateConversation(ctx.Request.Context(), clientID, dietitianID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open conversation"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, conversation)
+}
+
+// GetMessages returns a page of a conversation's history, newest first. Pass
+// the nextCursor of a page as ?before= to get the older messages; ?limit=
+// defaults to 50 and is at most 100.
+func (c *MessageController) GetMessages(ctx *gin.Context) {
+	conversation, _, ok := c.findConversation(ctx)
+	if !ok {
+		return
+	}
+
+	limit := defaultMessagePageSize
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		limit = min(parsed, maxMessagePageSize)
+	}
+
+	before := 0
+	if beforeStr := ctx.Query("before"); beforeStr != "" {
+		parsed, err := strconv.Atoi(beforeStr)
+		if err != nil || parsed < 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+		before = parsed
+	}
+
+	// Fetch one extra message to know whether there is an older page.
+	messages, err := c.messageRepo.GetMessages(ctx.Request.Context(), conversation.ID, before, limit+1)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch messages"})
+		return
+	}
+
+	page := models.MessagePage{Messages: messages}
+	if len(messages) > limit {
+		page.Messages = messages[:limit]
+		cursor := page.Messages[limit-1].ID
+		page.NextCursor = &cursor
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// SendMessage posts a message to a conversation and delivers it to the open
+// streams. Only participants whose subscription is still accepted can send.
+func (c *MessageController) SendMessage(ctx *gin.Context) {
+	conversation, callerID, ok := c.findConversation(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.MessageRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Message cannot be empty"})
+		return
+	}
+
+	if !c.checkSubscribed(ctx, conversation.UserID, conversation.DietitianID) {
+		return
+	}
+
+	message := &models.Message{
+		ConversationID: conversation.ID,
+		SenderID:       callerID,
+		Body:           body,
+	}
+	if err := c.messageRepo.CreateMessage(ctx.Request.Context(), message); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
+		return
+	}
+	c.hub.Publish(conversation.ID, messaging.Event{Type: messaging.EventMessage, Data: message})
+
+	ctx.JSON(http.StatusCreated, message)
+}
+
+// MarkRead marks the messages the caller received in a conversation as read,
+// up to the optional upTo message ID, and notifies the sender's streams.
+func (c *MessageController) MarkRead(ctx *gin.Context) {
+	conversation, callerID, ok := c.findConversation(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.MarkReadRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+	upTo := 0
+	if req.UpTo != nil {
+		upTo = *req.UpTo
+	}
+
+	receipt, err := c.messageRepo.MarkRead(ctx.Request.Context(), conversation.ID, callerID, upTo)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark messages as read"})
+		return
+	}
+	if receipt.Count > 0 {
+		c.hub.Publish(conversation.ID, messaging.Event{Type: messaging.EventRead, Data: receipt})
+	}
+
+	ctx.JSON(http.StatusOK, receipt)
+}
+
+// StreamConversation sends the new messages and read receipts of a
+// conversation as server-sent events until the client disconnects. Clients
+// load the history first and then listen here; after a reconnect they fetch
+// the first page again to catch up. The stream sends an end event and closes
+// once the caller's session is signed out or the subscription ends.
+func (c *MessageController) StreamConversation(ctx *gin.Context) {
+	conversation, _, ok := c.findConversation(ctx)
+	if !ok {
+		return
+	}
+	if !c.checkSubscribed(ctx, conversation.UserID, conversation.DietitianID) {
+		return
+	}
+	sessionID := ctx.GetString("sessionID")
+
+	// The server's write timeout would cut the stream; it only ends when
+	// either side goes away.
+	_ = http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{})
+
+	events, unsubscribe := c.hub.Subscribe(conversation.ID)
+	defer unsubscribe()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.SSEvent("ready", gin.H{"conversationId": conversation.ID})
+	ctx.Writer.Flush()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			ctx.SSEvent(event.Type, event.Data)
+			return true
+		case <-heartbeat.C:
+			if reason := c.streamEndReason(ctx, conversation, sessionID); reason != "" {
+				ctx.SSEvent("end", gin.H{"reason": reason})
+				return false
+			}
+			ctx.SSEvent("ping", gin.H{"time": time.Now()})
+			return true
+		case <-ctx.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// streamEndReason re-checks the access an open stream was granted with and
+// returns why the stream must end, or "" while it may go on. A failed check
+// ends the stream too; the client reconnects and is checked from scratch.
+func (c *MessageController) streamEndReason(ctx *gin.Context, conversation *models.Conversation, sessionID string) string {
+	if sessionID != "" {
+		active, err := c.refreshTokenRepo.IsSessionActive(ctx.Request.Context(), sessionID, time.Now())
+		if err != nil {
+			log.Printf("Error checking session of stream: %v", err)
+			return "error"
+		}
+		if !active {
+			return "signed_out"
+		}
+	}
+
+	isSubscribed, err := c.userRepo.IsUserSubscribedToDietitian(ctx.Request.Context(), strconv.Itoa(conversation.UserID), conversation.DietitianID)
+	if err != nil {
+		log.Printf("Error checking subscription of stream: %v", err)
+		return "error"
+	}
+	if !isSubscribed {
+		return "unsubscribed"
+	}
+	return ""
+}
+
+// findConversation loads the conversation of the :id parameter and checks
+// that the caller takes part in it. Others get a 404, so conversation IDs
+// can't be probed.
+func (c *MessageController) findConversation(ctx *gin.Context) (*models.Conversation, int, bool) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return nil, 0, false
+	}
+	callerID := int(userID.(float64))
+
+	conversationID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conversation ID"})
+		return nil, 0, false
+	}
+
+	conversation, err := c.messageRepo.GetConversation(ctx.Request.Context(), conversationID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrConversationNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+			return nil, 0, false
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch conversation"})
+		return nil, 0, false
+	}
+	if !conversation.HasParticipant(callerID) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return nil, 0, false
+	}
+
+	return conversation, callerID, true
+}
+
+func (c *MessageController) checkSubscribed(ctx *gin.Context, clientID, dietitianID int) bool {
+	isSubscribed, err := c.userRepo.IsUserSubscribedToDietitian(ctx.Request.Context(), strconv.Itoa(clientID), dietitianID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check subscription"})
+		return false
+	}
+	if !isSubscribed {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Messaging requires an accepted subscription between client and dietitian"})
+		return false
+	}
+	return true
+}
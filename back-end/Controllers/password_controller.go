@@ -0,0 +1,156 @@
+package Controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	config "HabitBite/backend/Config"
+	mailer "HabitBite/backend/Mailer"
+	models "HabitBite/backend/Models"
+	repositories "HabitBite/backend/Repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// passwordResetMailTimeout bounds the lookup and delivery of a reset email,
+// which run after the response is sent.
+const passwordResetMailTimeout = 30 * time.Second
+
+type PasswordController struct {
+	userRepo         repositories.UserRepository
+	userTokenRepo    repositories.UserTokenRepository
+	refreshTokenRepo repositories.RefreshTokenRepository
+	mailer           mailer.Mailer
+	config           *config.Config
+}
+
+func NewPasswordController(userRepo repositories.UserRepository, userTokenRepo repositories.UserTokenRepository, refreshTokenRepo repositories.RefreshTokenRepository, mail mailer.Mailer, cfg *config.Config) *PasswordController {
+	return &PasswordController{
+		userRepo:         userRepo,
+		userTokenRepo:    userTokenRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		mailer:           mail,
+		config:           cfg,
+	}
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+// ForgotPassword mails a password reset link to the account of the email,
+// at most once per PasswordResetResendInterval. The response is the same whether or not the account exists, and is sent
+// before the account is looked up, so its timing doesn't tell either.
+func (c *PasswordController) ForgotPassword(ctx *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "A valid email is required"})
+		return
+	}
+
+	go c.sendResetLink(strings.ToLower(strings.TrimSpace(req.Email)))
+
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"message": "If an account exists for this email, a password reset link has been sent",
+	})
+}
+
+func (c *PasswordController) sendResetLink(email string) {
+	reqCtx, cancel := context.WithTimeout(context.Background(), passwordResetMailTimeout)
+	defer cancel()
+
+	user, err := c.userRepo.FindByEmail(reqCtx, email)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrUserNotFound) {
+			log.Printf("Error finding user for password reset: %v", err)
+		}
+		return
+	}
+
+	latest, err := c.userTokenRepo.LatestUserTokenAt(reqCtx, user.ID, models.TokenPasswordReset)
+	if err != nil {
+		log.Printf("Error checking password reset emails of user %d: %v", user.ID, err)
+		return
+	}
+	if latest != nil && time.Since(*latest) < models.PasswordResetResendInterval {
+		log.Printf("Password reset email for user %d throttled", user.ID)
+		return
+	}
+
+	token, stored, err := models.NewUserToken(user.ID, models.TokenPasswordReset, models.PasswordResetLifetime, time.Now())
+	if err != nil {
+		log.Printf("Error generating password reset token: %v", err)
+		return
+	}
+	if err := c.userTokenRepo.CreateUserToken(reqCtx, stored); err != nil {
+		log.Printf("Error storing password reset token for user %d: %v", user.ID, err)
+		return
+	}
+
+	link := c.config.AppURL + "/reset-password?token=" + url.QueryEscape(token)
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your HabitBite password",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Someone asked to reset the password of your HabitBite account. "+
+			"Open the link below within %d minutes to choose a new one:\n\n%s\n\n"+
+			"If you didn't ask for this, you can ignore this email; your password won't change.\n",
+			user.FullName, int(models.PasswordResetLifetime.Minutes()), link),
+	}
+	if err := c.mailer.Send(reqCtx, msg); err != nil {
+		log.Printf("Error sending password reset email to user %d: %v", user.ID, err)
+	}
+}
+
+// ResetPassword sets a new password with the token of a reset link. The token
+// can be used once and the user's other reset links stop working, and every
+// session of the user is signed out.
+func (c *PasswordController) ResetPassword(ctx *gin.Context) {
+	var req ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request format",
+			"details": validationErrors(err),
+		})
+		return
+	}
+
+	reqCtx := ctx.Request.Context()
+	now := time.Now()
+
+	// Hash first, so the token is consumed together with the update.
+	passwordHash, err := models.HashPassword(req.Password)
+	if err != nil {
+		log.Printf("Error hashing password: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process password"})
+		return
+	}
+
+	token, err := c.userTokenRepo.ResetPassword(reqCtx, models.HashToken(req.Token), passwordHash, now)
+	if err != nil {
+		if errors.Is(err, repositories.ErrUserTokenInvalid) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+		log.Printf("Error resetting password: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	if _, err := c.refreshTokenRepo.RevokeSessions(reqCtx, token.UserID, now); err != nil {
+		log.Printf("Error revoking sessions of user %d after password reset: %v", token.UserID, err)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Password has been reset, please log in again"})
+}
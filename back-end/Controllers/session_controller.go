@@ -0,0 +1,222 @@
+package Controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	config "HabitBite/backend/Config"
+	models "HabitBite/backend/Models"
+	repositories "HabitBite/backend/Repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionController struct {
+	refreshTokenRepo repositories.RefreshTokenRepository
+	userRepo         repositories.UserRepository
+	auditRepo        repositories.AuditRepository
+	config           *config.Config
+}
+
+func NewSessionController(refreshTokenRepo repositories.RefreshTokenRepository, userRepo repositories.UserRepository, auditRepo repositories.AuditRepository, cfg *config.Config) *SessionController {
+	return &SessionController{
+		refreshTokenRepo: refreshTokenRepo,
+		userRepo:         userRepo,
+		auditRepo:        auditRepo,
+		config:           cfg,
+	}
+}
+
+// GetSessions lists the devices signed in to the caller's account. The
+// session of the request is marked current.
+func (c *SessionController) GetSessions(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	sessions, err := c.refreshTokenRepo.GetSessions(ctx.Request.Context(), int(userID.(float64)), time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	current := ctx.GetString("sessionID")
+	for i := range sessions {
+		sessions[i].Current = current != "" && sessions[i].FamilyID == current
+	}
+
+	ctx.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession signs one of the caller's devices out. Revoking the current
+// session also clears the caller's cookies.
+func (c *SessionController) RevokeSession(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	ownerID := int(userID.(float64))
+
+	sessionID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	// Look the session up first to know whether it is the current one.
+	sessions, err := c.refreshTokenRepo.GetSessions(ctx.Request.Context(), ownerID, time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+	var current bool
+	for _, session := range sessions {
+		if session.ID == sessionID {
+			current = session.FamilyID == ctx.GetString("sessionID")
+		}
+	}
+
+	if !c.revokeSession(ctx, ownerID, sessionID) {
+		return
+	}
+	if current {
+		c.clearCookies(ctx)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+// RevokeAllSessions signs the caller out everywhere, including the current
+// session.
+func (c *SessionController) RevokeAllSessions(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	revoked, err := c.refreshTokenRepo.RevokeSessions(ctx.Request.Context(), int(userID.(float64)), time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+	c.clearCookies(ctx)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Signed out of all sessions", "revoked": revoked})
+}
+
+// AdminGetSessions lists the active sessions of any user.
+func (c *SessionController) AdminGetSessions(ctx *gin.Context) {
+	targetID, ok := c.authorizeAdmin(ctx, models.AuditSessionRead, nil)
+	if !ok {
+		return
+	}
+
+	sessions, err := c.refreshTokenRepo.GetSessions(ctx.Request.Context(), targetID, time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sessions)
+}
+
+// AdminRevokeSession signs one device of any user out.
+func (c *SessionController) AdminRevokeSession(ctx *gin.Context) {
+	sessionID, err := strconv.Atoi(ctx.Param("sessionId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	targetID, ok := c.authorizeAdmin(ctx, models.AuditSessionRevoke, &sessionID)
+	if !ok {
+		return
+	}
+	if !c.revokeSession(ctx, targetID, sessionID) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+// AdminRevokeAllSessions signs a user out everywhere, for instance when the
+// account is compromised.
+func (c *SessionController) AdminRevokeAllSessions(ctx *gin.Context) {
+	targetID, ok := c.authorizeAdmin(ctx, models.AuditSessionRevokeAll, nil)
+	if !ok {
+		return
+	}
+
+	revoked, err := c.refreshTokenRepo.RevokeSessions(ctx.Request.Context(), targetID, time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Signed out of all sessions", "revoked": revoked})
+}
+
+// authorizeAdmin resolves the :id user and records the admin's action in the
+// audit log.
+func (c *SessionController) authorizeAdmin(ctx *gin.Context, action string, sessionID *int) (int, bool) {
+	actorID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return 0, false
+	}
+
+	targetID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return 0, false
+	}
+	if _, err := c.userRepo.FindByID(ctx.Request.Context(), targetID); err != nil {
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return 0, false
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return 0, false
+	}
+
+	audit := &models.AuditEntry{
+		ActorID:      int(actorID.(float64)),
+		ActorRole:    ctx.GetString("userRole"),
+		Action:       action,
+		TargetUserID: targetID,
+		EntityID:     sessionID,
+		IPAddress:    ctx.ClientIP(),
+	}
+	if err := c.auditRepo.RecordAudit(ctx.Request.Context(), audit); err != nil {
+		log.Printf("Failed to record audit entry for %s by user %d: %v", action, audit.ActorID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record access"})
+		return 0, false
+	}
+
+	return targetID, true
+}
+
+func (c *SessionController) revokeSession(ctx *gin.Context, ownerID, sessionID int) bool {
+	if err := c.refreshTokenRepo.RevokeSession(ctx.Request.Context(), ownerID, sessionID, time.Now()); err != nil {
+		if errors.Is(err, repositories.ErrSessionNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return false
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return false
+	}
+	return true
+}
+
+// clearCookies removes the auth cookies the way Logout does.
+func (c *SessionController) clearCookies(ctx *gin.Context) {
+	ctx.SetCookie("refresh_token", "", -1, "/", "", true, true)
+	ctx.SetCookie("auth_token", "", -1, "/", c.config.CookieDomain, true, true)
+}
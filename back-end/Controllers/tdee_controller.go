@@ -0,0 +1,136 @@
+package Controllers
+
+import (
+	"net/http"
+	"time"
+
+	goals "HabitBite/backend/Goals"
+	models "HabitBite/backend/Models"
+	repositories "HabitBite/backend/Repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TDEEController serves the adaptive expenditure estimate, which is backed out
+// of logged intake and the weight trend instead of the static formula used at
+// registration.
+type TDEEController struct {
+	foodEntryRepo repositories.FoodEntryRepository
+	weightRepo    repositories.WeightRepository
+	userRepo      repositories.UserRepository
+	planner       *goals.Planner
+}
+
+func NewTDEEController(foodEntryRepo repositories.FoodEntryRepository, weightRepo repositories.WeightRepository, userRepo repositories.UserRepository, planner *goals.Planner) *TDEEController {
+	return &TDEEController{
+		foodEntryRepo: foodEntryRepo,
+		weightRepo:    weightRepo,
+		userRepo:      userRepo,
+		planner:       planner,
+	}
+}
+
+func (c *TDEEController) GetTDEE(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	c.getTDEE(ctx, int(userID.(float64)))
+}
+
+// AcceptSuggestedGoal makes the suggested goal of a fresh estimate the
+// user's daily calorie goal.
+func (c *TDEEController) AcceptSuggestedGoal(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	c.acceptSuggestedGoal(ctx, int(userID.(float64)))
+}
+
+func (c *TDEEController) DietitianGetTDEE(ctx *gin.Context) {
+	if _, clientID, ok := authorizeDietitian(ctx, c.userRepo); ok {
+		c.getTDEE(ctx, clientID)
+	}
+}
+
+func (c *TDEEController) DietitianAcceptSuggestedGoal(ctx *gin.Context) {
+	if _, clientID, ok := authorizeDietitian(ctx, c.userRepo); ok {
+		c.acceptSuggestedGoal(ctx, clientID)
+	}
+}
+
+func (c *TDEEController) getTDEE(ctx *gin.Context, ownerID int) {
+	estimate, err := c.estimate(ctx, ownerID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to estimate TDEE"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"estimate":  estimate,
+		"canAccept": estimate.CanAccept(),
+	})
+}
+
+func (c *TDEEController) acceptSuggestedGoal(ctx *gin.Context, ownerID int) {
+	estimate, err := c.estimate(ctx, ownerID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to estimate TDEE"})
+		return
+	}
+	if !estimate.CanAccept() {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error":    "Not enough logged meals and weigh-ins to suggest a goal yet",
+			"estimate": estimate,
+		})
+		return
+	}
+
+	if err := c.userRepo.SyncUserCalorieGoal(ctx.Request.Context(), ownerID, estimate.SuggestedGoal); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update calorie goal"})
+		return
+	}
+
+	userGoals, err := c.userRepo.GetUserGoals(ctx.Request.Context(), ownerID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user goals"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Calorie goal updated",
+		"goals":   userGoals,
+	})
+}
+
+// estimate builds the estimate for the windows ending yesterday, since the
+// current day is usually only partly logged.
+func (c *TDEEController) estimate(ctx *gin.Context, ownerID int) (*models.TDEEEstimate, error) {
+	user, err := c.userRepo.FindByID(ctx.Request.Context(), ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	endDate, _ := time.Parse("2006-01-02", time.Now().AddDate(0, 0, -1).Format("2006-01-02"))
+	startDate := endDate.AddDate(0, 0, -(models.TDEEWindows[len(models.TDEEWindows)-1] - 1))
+
+	intake, err := c.foodEntryRepo.GetNutritionHistory(ctx.Request.Context(), ownerID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := c.weightRepo.GetWeightEntries(ctx.Request.Context(), ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := models.EstimateTDEE(intake, models.WeightTrend(entries), endDate)
+	estimate.CurrentGoal = user.DailyCalorieGoal
+	estimate.SuggestedGoal = c.planner.AdjustedGoal(float64(estimate.TDEE), user.GoalType)
+	return &estimate, nil
+}
@@ -0,0 +1,432 @@
+package Controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	config "HabitBite/backend/Config"
+	models "HabitBite/backend/Models"
+	repositories "HabitBite/backend/Repositories"
+	twofactor "HabitBite/backend/TwoFactor"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// twoFactorIssuer names the account in authenticator apps.
+	twoFactorIssuer = "HabitBite"
+
+	// twoFactorMaxFailures wrong codes in a row lock the user's second
+	// factor for twoFactorLockout, however many login attempts they span.
+	twoFactorMaxFailures = 10
+	twoFactorLockout     = 15 * time.Minute
+)
+
+var (
+	// errInvalidTwoFactorCode is returned for wrong, replayed and used codes
+	// alike, so the response doesn't tell them apart.
+	errInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	// errTwoFactorLocked is returned while too many wrong codes lock the
+	// user's second factor.
+	errTwoFactorLocked = errors.New("two-factor authentication is temporarily locked")
+)
+
+type TwoFactorController struct {
+	userRepo      repositories.UserRepository
+	twoFactorRepo repositories.TwoFactorRepository
+	secrets       *twofactor.Cipher
+	config        *config.Config
+}
+
+func NewTwoFactorController(userRepo repositories.UserRepository, twoFactorRepo repositories.TwoFactorRepository, secrets *twofactor.Cipher, cfg *config.Config) *TwoFactorController {
+	return &TwoFactorController{
+		userRepo:      userRepo,
+		twoFactorRepo: twoFactorRepo,
+		secrets:       secrets,
+		config:        cfg,
+	}
+}
+
+type EnrollTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password     string `json:"password" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
+}
+
+// GetStatus describes the caller's two-factor setup.
+func (c *TwoFactorController) GetStatus(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	id := int(userID.(float64))
+	reqCtx := ctx.Request.Context()
+
+	status := models.TwoFactorStatus{Required: c.config.TwoFactorRequired(ctx.GetString("userRole"))}
+	twoFactor, err := c.twoFactorRepo.GetTwoFactor(reqCtx, id)
+	if err != nil && !errors.Is(err, repositories.ErrTwoFactorNotFound) {
+		log.Printf("Error fetching two-factor setup of user %d: %v", id, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch two-factor status"})
+		return
+	}
+	if twoFactor != nil {
+		status.Enabled = twoFactor.EnabledAt != nil
+		status.Pending = twoFactor.EnabledAt == nil
+	}
+	if status.Enabled {
+		if status.RecoveryCodesRemaining, err = c.twoFactorRepo.CountRecoveryCodes(reqCtx, id); err != nil {
+			log.Printf("Error counting recovery codes of user %d: %v", id, err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch two-factor status"})
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, status)
+}
+
+// Enroll starts two-factor setup and returns the secret to add to an
+// authenticator app, both as text and as an otpauth URI for a QR code. The
+// setup takes effect once confirmed with a code. Enrolling again before that
+// replaces the secret.
+func (c *TwoFactorController) Enroll(ctx *gin.Context) {
+	var req EnrollTwoFactorRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Password is required"})
+		return
+	}
+	user, ok := c.authenticate(ctx, req.Password)
+	if !ok {
+		return
+	}
+
+	secret, err := twofactor.GenerateSecret()
+	if err != nil {
+		log.Printf("Error generating TOTP secret: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor setup"})
+		return
+	}
+	encrypted, err := c.secrets.Encrypt(secret)
+	if err != nil {
+		log.Printf("Error encrypting TOTP secret: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor setup"})
+		return
+	}
+
+	twoFactor := &models.TwoFactor{
+		UserID:          user.ID,
+		SecretEncrypted: encrypted,
+		CreatedAt:       time.Now(),
+	}
+	if err := c.twoFactorRepo.StartTwoFactor(ctx.Request.Context(), twoFactor); err != nil {
+		if errors.Is(err, repositories.ErrTwoFactorAlreadyEnabled) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+			return
+		}
+		log.Printf("Error storing two-factor setup of user %d: %v", user.ID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor setup"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"secret":          secret,
+		"provisioningUri": twofactor.ProvisioningURI(twoFactorIssuer, user.Email, secret),
+	})
+}
+
+// Confirm enables two-factor login with a first code from the authenticator
+// app and returns the recovery codes. They are shown only this once.
+func (c *TwoFactorController) Confirm(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	id := int(userID.(float64))
+
+	var req TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Code is required"})
+		return
+	}
+
+	reqCtx := ctx.Request.Context()
+	now := time.Now()
+
+	twoFactor, err := c.twoFactorRepo.GetTwoFactor(reqCtx, id)
+	if err != nil && !errors.Is(err, repositories.ErrTwoFactorNotFound) {
+		log.Printf("Error fetching two-factor setup of user %d: %v", id, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+	if twoFactor == nil || twoFactor.EnabledAt != nil {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "No two-factor setup is pending"})
+		return
+	}
+
+	step, err := c.checkCode(twoFactor, req.Code, now)
+	if err != nil {
+		c.codeError(ctx, id, err)
+		return
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		log.Printf("Error generating recovery codes: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+	if err := c.twoFactorRepo.EnableTwoFactor(reqCtx, id, step, hashes, now); err != nil {
+		if errors.Is(err, repositories.ErrTwoFactorNotFound) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "No two-factor setup is pending"})
+			return
+		}
+		log.Printf("Error enabling two-factor authentication of user %d: %v", id, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":       "Two-factor authentication enabled",
+		"recoveryCodes": codes,
+	})
+}
+
+// RegenerateRecoveryCodes replaces the caller's recovery codes, used or not,
+// after checking a code from the authenticator app.
+func (c *TwoFactorController) RegenerateRecoveryCodes(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	id := int(userID.(float64))
+
+	var req TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Code is required"})
+		return
+	}
+
+	reqCtx := ctx.Request.Context()
+	now := time.Now()
+
+	if err := c.VerifyCode(reqCtx, id, req.Code, "", now); err != nil {
+		c.codeError(ctx, id, err)
+		return
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		log.Printf("Error generating recovery codes: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+	if err := c.twoFactorRepo.ReplaceRecoveryCodes(reqCtx, id, hashes, now); err != nil {
+		log.Printf("Error storing recovery codes of user %d: %v", id, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"recoveryCodes": codes})
+}
+
+// Disable turns two-factor login off after checking the password and a code
+// or recovery code. Users whose role requires two-factor login can't.
+func (c *TwoFactorController) Disable(ctx *gin.Context) {
+	var req DisableTwoFactorRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Password is required"})
+		return
+	}
+	if c.config.TwoFactorRequired(ctx.GetString("userRole")) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is required for your role"})
+		return
+	}
+	user, ok := c.authenticate(ctx, req.Password)
+	if !ok {
+		return
+	}
+
+	reqCtx := ctx.Request.Context()
+	if err := c.VerifyCode(reqCtx, user.ID, req.Code, req.RecoveryCode, time.Now()); err != nil {
+		c.codeError(ctx, user.ID, err)
+		return
+	}
+	if err := c.twoFactorRepo.DisableTwoFactor(reqCtx, user.ID); err != nil {
+		log.Printf("Error disabling two-factor authentication of user %d: %v", user.ID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// IsEnabled reports whether logging the user in takes a second factor.
+func (c *TwoFactorController) IsEnabled(ctx context.Context, userID int) (bool, error) {
+	return c.twoFactorRepo.IsTwoFactorEnabled(ctx, userID)
+}
+
+// StartChallenge stores the challenge of a login waiting for the user's
+// second factor and returns its ID.
+func (c *TwoFactorController) StartChallenge(ctx context.Context, userID int, lifetime time.Duration, now time.Time) (string, error) {
+	challenge, err := models.NewMFAChallenge(userID, lifetime, now)
+	if err != nil {
+		return "", err
+	}
+	if err := c.twoFactorRepo.CreateMFAChallenge(ctx, challenge); err != nil {
+		return "", err
+	}
+	return challenge.ID, nil
+}
+
+// AttemptChallenge counts an attempt at a login challenge, failing with
+// repositories.ErrMFAChallengeInvalid once it can't take any more.
+func (c *TwoFactorController) AttemptChallenge(ctx context.Context, id string, userID, maxAttempts int, now time.Time) error {
+	return c.twoFactorRepo.AttemptMFAChallenge(ctx, id, userID, maxAttempts, now)
+}
+
+// UseChallenge uses up a login challenge after its code was accepted.
+func (c *TwoFactorController) UseChallenge(ctx context.Context, id string, now time.Time) error {
+	return c.twoFactorRepo.UseMFAChallenge(ctx, id, now)
+}
+
+// VerifyCode checks a code from the user's authenticator app or, if code is
+// empty, one of their recovery codes, and uses it up. It returns
+// errInvalidTwoFactorCode unless two-factor login is enabled and the code is
+// valid, and errTwoFactorLocked while too many wrong codes lock it.
+func (c *TwoFactorController) VerifyCode(ctx context.Context, userID int, code, recoveryCode string, now time.Time) error {
+	twoFactor, err := c.twoFactorRepo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrTwoFactorNotFound) {
+			return errInvalidTwoFactorCode
+		}
+		return err
+	}
+	if twoFactor.EnabledAt == nil {
+		return errInvalidTwoFactorCode
+	}
+	if twoFactor.LockedUntil != nil && now.Before(*twoFactor.LockedUntil) {
+		return errTwoFactorLocked
+	}
+
+	err = c.useCode(ctx, twoFactor, code, recoveryCode, now)
+	if errors.Is(err, errInvalidTwoFactorCode) {
+		if err := c.twoFactorRepo.RecordTwoFactorFailure(ctx, userID, twoFactorMaxFailures, now.Add(twoFactorLockout)); err != nil {
+			return err
+		}
+		return errInvalidTwoFactorCode
+	}
+	if err != nil {
+		return err
+	}
+	if twoFactor.FailedAttempts > 0 {
+		return c.twoFactorRepo.ResetTwoFactorFailures(ctx, userID)
+	}
+	return nil
+}
+
+// useCode checks and uses up a code of an enabled enrollment.
+func (c *TwoFactorController) useCode(ctx context.Context, twoFactor *models.TwoFactor, code, recoveryCode string, now time.Time) error {
+	userID := twoFactor.UserID
+	if code == "" {
+		if recoveryCode == "" {
+			return errInvalidTwoFactorCode
+		}
+		codeHash := models.HashToken(twofactor.NormalizeRecoveryCode(recoveryCode))
+		err := c.twoFactorRepo.UseRecoveryCode(ctx, userID, codeHash, now)
+		if errors.Is(err, repositories.ErrRecoveryCodeInvalid) {
+			return errInvalidTwoFactorCode
+		}
+		return err
+	}
+
+	step, err := c.checkCode(twoFactor, code, now)
+	if err != nil {
+		return err
+	}
+	err = c.twoFactorRepo.UseTwoFactorStep(ctx, userID, step)
+	if errors.Is(err, repositories.ErrTwoFactorStepUsed) {
+		return errInvalidTwoFactorCode
+	}
+	return err
+}
+
+// checkCode validates code against the secret of twoFactor and returns its
+// time step, which must be later than the last one used.
+func (c *TwoFactorController) checkCode(twoFactor *models.TwoFactor, code string, now time.Time) (int64, error) {
+	secret, err := c.secrets.Decrypt(twoFactor.SecretEncrypted)
+	if err != nil {
+		return 0, err
+	}
+	step, ok := twofactor.Validate(secret, code, now)
+	if !ok || step <= twoFactor.LastUsedStep {
+		return 0, errInvalidTwoFactorCode
+	}
+	return step, nil
+}
+
+// authenticate checks the caller's password before a change to their second
+// factor. A wrong password is 403, like a wrong code.
+func (c *TwoFactorController) authenticate(ctx *gin.Context, password string) (*models.User, bool) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return nil, false
+	}
+
+	user, err := c.userRepo.FindByID(ctx.Request.Context(), int(userID.(float64)))
+	if err != nil {
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			return nil, false
+		}
+		log.Printf("Error finding user: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+		return nil, false
+	}
+	if !user.CheckPassword(password) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Invalid password"})
+		return nil, false
+	}
+	return user, true
+}
+
+// codeError answers a failed code check. Wrong codes are 403 rather than 401,
+// which would end the caller's session in the front-end.
+func (c *TwoFactorController) codeError(ctx *gin.Context, userID int, err error) {
+	if errors.Is(err, errInvalidTwoFactorCode) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Invalid two-factor code"})
+		return
+	}
+	if errors.Is(err, errTwoFactorLocked) {
+		ctx.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many invalid codes, please try again later"})
+		return
+	}
+	log.Printf("Error checking two-factor code of user %d: %v", userID, err)
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check two-factor code"})
+}
+
+// generateRecoveryCodes returns new recovery codes and the hashes to store.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes, err := twofactor.GenerateRecoveryCodes(twofactor.RecoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = models.HashToken(twofactor.NormalizeRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
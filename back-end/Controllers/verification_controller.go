@@ -0,0 +1,146 @@
+package Controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	config "HabitBite/backend/Config"
+	mailer "HabitBite/backend/Mailer"
+	models "HabitBite/backend/Models"
+	repositories "HabitBite/backend/Repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// verificationMailTimeout bounds the lookup and delivery of a verification
+// email, which run after the response is sent.
+const verificationMailTimeout = 30 * time.Second
+
+type VerificationController struct {
+	userRepo      repositories.UserRepository
+	userTokenRepo repositories.UserTokenRepository
+	mailer        mailer.Mailer
+	config        *config.Config
+}
+
+func NewVerificationController(userRepo repositories.UserRepository, userTokenRepo repositories.UserTokenRepository, mail mailer.Mailer, cfg *config.Config) *VerificationController {
+	return &VerificationController{
+		userRepo:      userRepo,
+		userTokenRepo: userTokenRepo,
+		mailer:        mail,
+		config:        cfg,
+	}
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// SendVerificationEmail mails a verification link to a new user. It returns
+// immediately; failures are logged and the user can ask for another link.
+func (c *VerificationController) SendVerificationEmail(user *models.User) {
+	go func(user models.User) {
+		reqCtx, cancel := context.WithTimeout(context.Background(), verificationMailTimeout)
+		defer cancel()
+		c.sendVerificationLink(reqCtx, &user)
+	}(*user)
+}
+
+// VerifyEmail verifies the email of the account a verification link was sent
+// to. The token can be used once; it stays usable if verifying fails.
+func (c *VerificationController) VerifyEmail(ctx *gin.Context) {
+	token := ctx.Query("token")
+	if token == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Token is required"})
+		return
+	}
+
+	if _, err := c.userTokenRepo.VerifyEmail(ctx.Request.Context(), models.HashToken(token), time.Now()); err != nil {
+		if errors.Is(err, repositories.ErrUserTokenInvalid) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+		log.Printf("Error verifying email: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+// ResendVerification mails a new verification link to the account of the
+// email, at most once per EmailVerificationResendInterval. Like
+// ForgotPassword, it answers the same way whether or not the account exists
+// or is verified, before looking it up.
+func (c *VerificationController) ResendVerification(ctx *gin.Context) {
+	var req ResendVerificationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "A valid email is required"})
+		return
+	}
+
+	go c.resendVerificationLink(strings.ToLower(strings.TrimSpace(req.Email)))
+
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"message": "If an unverified account exists for this email, a verification link has been sent",
+	})
+}
+
+func (c *VerificationController) resendVerificationLink(email string) {
+	reqCtx, cancel := context.WithTimeout(context.Background(), verificationMailTimeout)
+	defer cancel()
+
+	user, err := c.userRepo.FindByEmail(reqCtx, email)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrUserNotFound) {
+			log.Printf("Error finding user for email verification: %v", err)
+		}
+		return
+	}
+	if user.EmailVerifiedAt != nil {
+		return
+	}
+
+	latest, err := c.userTokenRepo.LatestUserTokenAt(reqCtx, user.ID, models.TokenEmailVerification)
+	if err != nil {
+		log.Printf("Error checking verification emails of user %d: %v", user.ID, err)
+		return
+	}
+	if latest != nil && time.Since(*latest) < models.EmailVerificationResendInterval {
+		log.Printf("Verification email for user %d throttled", user.ID)
+		return
+	}
+
+	c.sendVerificationLink(reqCtx, user)
+}
+
+func (c *VerificationController) sendVerificationLink(reqCtx context.Context, user *models.User) {
+	token, stored, err := models.NewUserToken(user.ID, models.TokenEmailVerification, models.EmailVerificationLifetime, time.Now())
+	if err != nil {
+		log.Printf("Error generating email verification token: %v", err)
+		return
+	}
+	if err := c.userTokenRepo.CreateUserToken(reqCtx, stored); err != nil {
+		log.Printf("Error storing email verification token for user %d: %v", user.ID, err)
+		return
+	}
+
+	link := c.config.AppURL + "/verify-email?token=" + url.QueryEscape(token)
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your HabitBite email address",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Welcome to HabitBite! Open the link below within %d hours to confirm your email address:\n\n%s\n\n"+
+			"If you didn't create an account, you can ignore this email.\n",
+			user.FullName, int(models.EmailVerificationLifetime.Hours()), link),
+	}
+	if err := c.mailer.Send(reqCtx, msg); err != nil {
+		log.Printf("Error sending verification email to user %d: %v", user.ID, err)
+	}
+}
@@ -0,0 +1,149 @@
+package Controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	models "HabitBite/backend/Models"
+	repositories "HabitBite/backend/Repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WeightController struct {
+	weightRepo repositories.WeightRepository
+	userRepo   repositories.UserRepository
+}
+
+func NewWeightController(weightRepo repositories.WeightRepository, userRepo repositories.UserRepository) *WeightController {
+	return &WeightController{
+		weightRepo: weightRepo,
+		userRepo:   userRepo,
+	}
+}
+
+// AddWeightEntry logs a weigh-in and makes it the user's current weight if it
+// is the most recent one.
+func (c *WeightController) AddWeightEntry(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	var req models.WeightEntryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	recordedAt := time.Now()
+	if req.RecordedAt != nil {
+		if req.RecordedAt.After(recordedAt.Add(24 * time.Hour)) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Weigh-ins cannot be in the future"})
+			return
+		}
+		recordedAt = *req.RecordedAt
+	}
+
+	entry := &models.WeightEntry{
+		UserID:     int(userID.(float64)),
+		Weight:     req.Weight,
+		RecordedAt: recordedAt,
+	}
+	if err := c.weightRepo.CreateWeightEntry(ctx.Request.Context(), entry); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add weight entry"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, entry)
+}
+
+// GetWeightEntries returns the weigh-ins of the last ?days= days (default 90)
+// with their trend weight, the weekly rate of change and the projected date
+// the target weight from the user's goals is reached.
+func (c *WeightController) GetWeightEntries(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	ownerID := int(userID.(float64))
+
+	days := 90
+	if daysStr := ctx.Query("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil || parsed < 1 || parsed > 3650 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 3650"})
+			return
+		}
+		days = parsed
+	}
+
+	entries, err := c.weightRepo.GetWeightEntries(ctx.Request.Context(), ownerID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch weight entries"})
+		return
+	}
+
+	goals, err := c.userRepo.GetUserGoals(ctx.Request.Context(), ownerID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user goals"})
+		return
+	}
+
+	// The trend is computed over the whole history so the first points of the
+	// window are already smoothed.
+	points := models.WeightTrend(entries)
+	since := time.Now().AddDate(0, 0, -days)
+	window := []models.WeightTrendPoint{}
+	for _, point := range points {
+		if !point.RecordedAt.Before(since) {
+			window = append(window, point)
+		}
+	}
+
+	response := gin.H{
+		"entries":       window,
+		"targetWeight":  goals.TargetWeight,
+		"trendWeight":   nil,
+		"latestWeight":  nil,
+		"weeklyChange":  models.WeeklyWeightChange(points),
+		"projectedDate": models.ProjectTargetDate(points, goals.TargetWeight),
+	}
+	if len(points) > 0 {
+		latest := points[len(points)-1]
+		response["trendWeight"] = latest.Trend
+		response["latestWeight"] = latest.Weight
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *WeightController) DeleteWeightEntry(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	entryID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid weight entry ID"})
+		return
+	}
+
+	err = c.weightRepo.DeleteWeightEntry(ctx.Request.Context(), int(userID.(float64)), entryID)
+	switch {
+	case errors.Is(err, repositories.ErrWeightEntryNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Weight entry not found"})
+	case errors.Is(err, repositories.ErrWeightEntryForbidden):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Weight entry belongs to another user"})
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete weight entry"})
+	default:
+		ctx.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,116 @@
+package goals
+
+import (
+	"time"
+
+	models "HabitBite/backend/Models"
+)
+
+// Names of the built-in BMR formulas, as accepted by BMR_FORMULA.
+const (
+	FormulaMifflinStJeor  = "mifflin_st_jeor"
+	FormulaHarrisBenedict = "harris_benedict"
+	FormulaKatchMcArdle   = "katch_mcardle"
+)
+
+// Profile is the part of a user the targets are calculated from.
+type Profile struct {
+	Gender        string
+	Age           int
+	Weight        float64 // kg
+	Height        float64 // cm
+	BodyFat       *float64
+	ActivityLevel string
+	GoalType      string
+}
+
+// ProfileOf returns the profile of user with the age as of now.
+func ProfileOf(user *models.User, now time.Time) Profile {
+	return Profile{
+		Gender:        user.Gender,
+		Age:           age(user.Birthdate, now),
+		Weight:        user.Weight,
+		Height:        user.Height,
+		BodyFat:       user.BodyFat,
+		ActivityLevel: user.ActivityLevel,
+		GoalType:      user.GoalType,
+	}
+}
+
+// LeanMass returns the fat-free mass in kg, or 0 when the body fat percentage
+// is not known.
+func (p Profile) LeanMass() float64 {
+	if p.BodyFat == nil {
+		return 0
+	}
+	return p.Weight * (1 - *p.BodyFat/100)
+}
+
+// Formula estimates the basal metabolic rate in kcal per day. ok is false when
+// the profile lacks something the formula needs.
+type Formula func(p Profile) (bmr float64, ok bool)
+
+// Formulas are the BMR formulas that can be selected by name.
+var Formulas = map[string]Formula{
+	FormulaMifflinStJeor:  MifflinStJeor,
+	FormulaHarrisBenedict: HarrisBenedict,
+	FormulaKatchMcArdle:   KatchMcArdle,
+}
+
+// MifflinStJeor is the Mifflin-St Jeor equation. Anyone not recorded as male
+// gets the female constant.
+func MifflinStJeor(p Profile) (float64, bool) {
+	bmr := 10*p.Weight + 6.25*p.Height - 5*float64(p.Age)
+	if p.Gender == "male" {
+		return bmr + 5, true
+	}
+	return bmr - 161, true
+}
+
+// HarrisBenedict is the Harris-Benedict equation as revised by Roza and
+// Shizgal.
+func HarrisBenedict(p Profile) (float64, bool) {
+	if p.Gender == "male" {
+		return 88.362 + 13.397*p.Weight + 4.799*p.Height - 5.677*float64(p.Age), true
+	}
+	return 447.593 + 9.247*p.Weight + 3.098*p.Height - 4.330*float64(p.Age), true
+}
+
+// KatchMcArdle is the Katch-McArdle equation, which works from lean mass and
+// so needs the body fat percentage.
+func KatchMcArdle(p Profile) (float64, bool) {
+	leanMass := p.LeanMass()
+	if leanMass <= 0 {
+		return 0, false
+	}
+	return 370 + 21.6*leanMass, true
+}
+
+// activityMultipliers scale the BMR to total daily energy expenditure.
+var activityMultipliers = map[string]float64{
+	models.ActivitySedentary:  1.2,
+	models.ActivityLight:      1.375,
+	models.ActivityModerate:   1.55,
+	models.ActivityActive:     1.725,
+	models.ActivityVeryActive: 1.9,
+}
+
+// ActivityMultiplier returns the TDEE multiplier for an activity level,
+// treating unknown levels as sedentary.
+func ActivityMultiplier(activityLevel string) float64 {
+	if multiplier, ok := activityMultipliers[activityLevel]; ok {
+		return multiplier
+	}
+	return activityMultipliers[models.ActivitySedentary]
+}
+
+func age(birthdate, now time.Time) int {
+	if birthdate.IsZero() {
+		return 0
+	}
+	years := now.Year() - birthdate.Year()
+	if now.Month() < birthdate.Month() || (now.Month() == birthdate.Month() && now.Day() < birthdate.Day()) {
+		years--
+	}
+	return max(years, 0)
+}
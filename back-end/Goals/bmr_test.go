@@ -0,0 +1,118 @@
+package goals
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	models "HabitBite/backend/Models"
+)
+
+// baselineBMR is the Mifflin-St Jeor calculation registration used before
+// the planner existed.
+func baselineBMR(weight, height float64, gender string, age int) float64 {
+	if gender == "male" {
+		return 10*weight + 6.25*height - 5*float64(age) + 5
+	}
+	return 10*weight + 6.25*height - 5*float64(age) - 161
+}
+
+func baselineActivityMultiplier(activityLevel string) float64 {
+	switch activityLevel {
+	case "light":
+		return 1.375
+	case "moderate":
+		return 1.55
+	case "active":
+		return 1.725
+	case "very_active":
+		return 1.9
+	}
+	return 1.2
+}
+
+func TestDefaultBMRMatchesBaseline(t *testing.T) {
+	planner := DefaultPlanner()
+	bodyFat := 25.0
+
+	for _, gender := range []string{"male", "female", "other"} {
+		for _, activity := range []string{models.ActivitySedentary, models.ActivityLight, models.ActivityModerate, models.ActivityActive, models.ActivityVeryActive, "unknown"} {
+			for _, size := range []struct{ weight, height float64 }{{50, 155}, {72.5, 178}, {130, 190}} {
+				for _, age := range []int{18, 45, 80} {
+					profile := Profile{Gender: gender, Age: age, Weight: size.weight, Height: size.height, ActivityLevel: activity}
+					// Body fat is ignored unless a lean mass formula is chosen.
+					withBodyFat := profile
+					withBodyFat.BodyFat = &bodyFat
+
+					want := baselineBMR(size.weight, size.height, gender, age)
+					for _, p := range []Profile{profile, withBodyFat} {
+						if got := planner.BMR(p); got != want {
+							t.Errorf("BMR(%+v) = %v, want %v", p, got, want)
+						}
+						if got, want := planner.TDEE(p), want*baselineActivityMultiplier(activity); got != want {
+							t.Errorf("TDEE(%+v) = %v, want %v", p, got, want)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestKatchMcArdle(t *testing.T) {
+	planner, err := NewPlanner(FormulaKatchMcArdle, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bodyFat := 20.0
+	profile := Profile{Gender: "male", Age: 30, Weight: 80, Height: 180, BodyFat: &bodyFat}
+
+	// 64 kg of lean mass.
+	if got, want := planner.BMR(profile), 370+21.6*64; math.Abs(got-want) > 1e-9 {
+		t.Errorf("BMR with body fat = %v, want %v", got, want)
+	}
+
+	// Without body fat it falls back to Mifflin-St Jeor.
+	profile.BodyFat = nil
+	if got, want := planner.BMR(profile), baselineBMR(80, 180, "male", 30); got != want {
+		t.Errorf("BMR without body fat = %v, want %v", got, want)
+	}
+}
+
+func TestHarrisBenedict(t *testing.T) {
+	tests := []struct {
+		gender string
+		want   float64
+	}{
+		{"male", 88.362 + 13.397*80 + 4.799*180 - 5.677*30},
+		{"female", 447.593 + 9.247*80 + 3.098*180 - 4.330*30},
+	}
+	for _, tt := range tests {
+		bmr, ok := HarrisBenedict(Profile{Gender: tt.gender, Age: 30, Weight: 80, Height: 180})
+		if !ok || math.Abs(bmr-tt.want) > 1e-9 {
+			t.Errorf("HarrisBenedict for %s = %v, %v, want %v", tt.gender, bmr, ok, tt.want)
+		}
+	}
+}
+
+func TestAge(t *testing.T) {
+	birthdate := time.Date(1990, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		now  time.Time
+		want int
+	}{
+		{time.Date(2026, 6, 14, 0, 0, 0, 0, time.UTC), 35},
+		{time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC), 36},
+		{time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC), 36},
+		{time.Date(1989, 1, 1, 0, 0, 0, 0, time.UTC), 0},
+	}
+	for _, tt := range tests {
+		if got := age(birthdate, tt.now); got != tt.want {
+			t.Errorf("age on %s = %d, want %d", tt.now.Format("2006-01-02"), got, tt.want)
+		}
+	}
+	if got := age(time.Time{}, time.Now()); got != 0 {
+		t.Errorf("age without a birthdate = %d, want 0", got)
+	}
+}
@@ -0,0 +1,260 @@
+// Package goals turns a user's profile into the calorie and macronutrient
+// targets stored in user_goals. Every code path that writes those targets goes
+// through a Planner so they are all calculated the same way.
+package goals
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	models "HabitBite/backend/Models"
+)
+
+const (
+	// MinCalorieGoal is the lowest daily calorie goal that will be set.
+	MinCalorieGoal = 1200
+
+	caloriesPerGramProtein = 4.0
+	caloriesPerGramCarbs   = 4.0
+	caloriesPerGramFat     = 9.0
+
+	// maxProteinShare caps how much of the calories a protein-per-kg rule can
+	// claim, so a heavy user on a low goal still gets carbs and fats.
+	maxProteinShare = 0.5
+)
+
+// goalAdjustments are the daily deficit or surplus applied to the TDEE for
+// each goal type.
+var goalAdjustments = map[string]float64{
+	models.GoalLose: -500,
+	models.GoalGain: 500,
+}
+
+// MacroSplit is the share of calories from each macronutrient. The shares add
+// up to 1.
+type MacroSplit struct {
+	Protein float64
+	Carbs   float64
+	Fats    float64
+}
+
+// Planner calculates calorie and macronutrient targets.
+type Planner struct {
+	// Formula is used for the BMR. When it lacks something it needs, such as
+	// the body fat of KatchMcArdle, Mifflin-St Jeor is used instead.
+	Formula Formula
+	// Presets are the macro splits per goal type. Unknown goal types use the
+	// maintenance preset.
+	Presets map[string]MacroSplit
+	// ProteinPerKg is the minimum grams of protein per kg of body weight per
+	// goal type. When the preset gives less, the protein target is raised and
+	// the remaining calories are shared between carbs and fats in the preset's
+	// ratio. Zero or missing disables the rule.
+	ProteinPerKg map[string]float64
+}
+
+// RecommendedProteinPerKg are the protein rules selected by
+// PROTEIN_PER_KG=recommended.
+var RecommendedProteinPerKg = map[string]float64{
+	models.GoalLose:     1.6,
+	models.GoalMaintain: 1.2,
+	models.GoalGain:     1.6,
+}
+
+// DefaultPlanner returns a planner using Mifflin-St Jeor and the percentage
+// presets alone, which is how targets were always calculated. Lean mass and
+// protein rules are opted into through NewPlanner.
+func DefaultPlanner() *Planner {
+	return &Planner{
+		Formula: MifflinStJeor,
+		Presets: map[string]MacroSplit{
+			models.GoalLose:     {Protein: 0.35, Carbs: 0.30, Fats: 0.35},
+			models.GoalMaintain: {Protein: 0.25, Carbs: 0.50, Fats: 0.25},
+			models.GoalGain:     {Protein: 0.30, Carbs: 0.45, Fats: 0.25},
+		},
+		ProteinPerKg: map[string]float64{},
+	}
+}
+
+// NewPlanner returns the default planner with the given overrides, each of
+// which may be empty:
+//
+//	formula:      a key of Formulas; katch_mcardle uses lean mass when the
+//	              body fat is known
+//	presets:      "lose=35/30/35,gain=30/45/25" as protein/carbs/fats percent
+//	proteinPerKg: "lose=2.0,maintain=0", or "recommended" for
+//	              RecommendedProteinPerKg
+func NewPlanner(formula, presets, proteinPerKg string) (*Planner, error) {
+	planner := DefaultPlanner()
+
+	if formula != "" {
+		f, ok := Formulas[formula]
+		if !ok {
+			return nil, fmt.Errorf("unknown BMR formula %q", formula)
+		}
+		planner.Formula = f
+	}
+
+	err := parseGoalList(presets, func(goalType, value string) error {
+		split, err := parseMacroSplit(value)
+		if err != nil {
+			return err
+		}
+		planner.Presets[goalType] = split
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid macro presets: %v", err)
+	}
+
+	if strings.TrimSpace(proteinPerKg) == "recommended" {
+		for goalType, grams := range RecommendedProteinPerKg {
+			planner.ProteinPerKg[goalType] = grams
+		}
+		return planner, nil
+	}
+	err = parseGoalList(proteinPerKg, func(goalType, value string) error {
+		grams, err := strconv.ParseFloat(value, 64)
+		if err != nil || grams < 0 || grams > 4 {
+			return fmt.Errorf("%s: grams per kg must be between 0 and 4", goalType)
+		}
+		planner.ProteinPerKg[goalType] = grams
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid protein per kg: %v", err)
+	}
+
+	return planner, nil
+}
+
+// BMR returns the basal metabolic rate of the profile in kcal per day.
+func (p *Planner) BMR(profile Profile) float64 {
+	if bmr, ok := p.Formula(profile); ok {
+		return bmr
+	}
+	bmr, _ := MifflinStJeor(profile)
+	return bmr
+}
+
+// TDEE returns the estimated total daily energy expenditure of the profile.
+func (p *Planner) TDEE(profile Profile) float64 {
+	return p.BMR(profile) * ActivityMultiplier(profile.ActivityLevel)
+}
+
+// CalorieGoal returns the daily calorie goal for the profile.
+func (p *Planner) CalorieGoal(profile Profile) int {
+	return p.AdjustedGoal(p.TDEE(profile), profile.GoalType)
+}
+
+// AdjustedGoal applies the deficit or surplus of the goal type to an
+// expenditure, rounded to 10 kcal and never below MinCalorieGoal.
+func (p *Planner) AdjustedGoal(tdee float64, goalType string) int {
+	if tdee <= 0 {
+		return 0
+	}
+	goal := tdee + goalAdjustments[goalType]
+	return max(int(math.Round(goal/10))*10, MinCalorieGoal)
+}
+
+// Macros splits a calorie target into grams of protein, carbs and fats for
+// the profile's goal type.
+func (p *Planner) Macros(profile Profile, calories int) (protein, carbs, fats float64) {
+	split, ok := p.Presets[profile.GoalType]
+	if !ok {
+		split = p.Presets[models.GoalMaintain]
+	}
+
+	total := float64(calories)
+	protein = total * split.Protein / caloriesPerGramProtein
+	carbs = total * split.Carbs / caloriesPerGramCarbs
+	fats = total * split.Fats / caloriesPerGramFat
+
+	perKg := p.ProteinPerKg[profile.GoalType]
+	if perKg <= 0 || profile.Weight <= 0 || split.Carbs+split.Fats <= 0 {
+		return protein, carbs, fats
+	}
+
+	minProtein := math.Min(perKg*profile.Weight, total*maxProteinShare/caloriesPerGramProtein)
+	if minProtein <= protein {
+		return protein, carbs, fats
+	}
+
+	rest := total - minProtein*caloriesPerGramProtein
+	carbShare := split.Carbs / (split.Carbs + split.Fats)
+	return minProtein,
+		rest * carbShare / caloriesPerGramCarbs,
+		rest * (1 - carbShare) / caloriesPerGramFat
+}
+
+// Goals returns the targets for user at the given calorie goal, with the
+// current weight as the target weight.
+func (p *Planner) Goals(user *models.User, calories int) *models.UserGoals {
+	goals := &models.UserGoals{
+		UserID:         user.ID,
+		TargetCalories: calories,
+		TargetWeight:   user.Weight,
+	}
+	p.FillMacros(goals, user)
+	return goals
+}
+
+// FillMacros sets the macro targets of goals from its calorie target.
+func (p *Planner) FillMacros(goals *models.UserGoals, user *models.User) {
+	goals.TargetProtein, goals.TargetCarbs, goals.TargetFats = p.Macros(ProfileOf(user, time.Now()), goals.TargetCalories)
+}
+
+// parseGoalList calls set for every goalType=value pair of a comma separated
+// list.
+func parseGoalList(list string, set func(goalType, value string) error) error {
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		goalType, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return fmt.Errorf("%q is not goal=value", item)
+		}
+		goalType = strings.TrimSpace(goalType)
+		switch goalType {
+		case models.GoalLose, models.GoalMaintain, models.GoalGain:
+		default:
+			return fmt.Errorf("unknown goal type %q", goalType)
+		}
+		if err := set(goalType, strings.TrimSpace(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseMacroSplit(value string) (MacroSplit, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) != 3 {
+		return MacroSplit{}, fmt.Errorf("%q is not protein/carbs/fats", value)
+	}
+
+	var percents [3]float64
+	var sum float64
+	for i, part := range parts {
+		percent, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || percent < 0 {
+			return MacroSplit{}, fmt.Errorf("%q is not protein/carbs/fats", value)
+		}
+		percents[i] = percent
+		sum += percent
+	}
+	if math.Abs(sum-100) > 0.01 {
+		return MacroSplit{}, fmt.Errorf("%q does not add up to 100", value)
+	}
+
+	return MacroSplit{
+		Protein: percents[0] / 100,
+		Carbs:   percents[1] / 100,
+		Fats:    percents[2] / 100,
+	}, nil
+}
@@ -0,0 +1,147 @@
+package goals
+
+import (
+	"math"
+	"testing"
+
+	models "HabitBite/backend/Models"
+)
+
+func TestAdjustedGoal(t *testing.T) {
+	planner := DefaultPlanner()
+
+	tests := []struct {
+		tdee     float64
+		goalType string
+		want     int
+	}{
+		{0, models.GoalMaintain, 0},
+		{-100, models.GoalLose, 0},
+		{2500, models.GoalMaintain, 2500},
+		{2504, models.GoalMaintain, 2500},
+		{2505, models.GoalMaintain, 2510},
+		{2500, models.GoalLose, 2000},
+		{2500, models.GoalGain, 3000},
+		{2500, "unknown", 2500},
+		// Goals never drop below MinCalorieGoal.
+		{1690, models.GoalLose, 1200},
+		{1000, models.GoalMaintain, 1200},
+		{1, models.GoalLose, 1200},
+	}
+	for _, tt := range tests {
+		if got := planner.AdjustedGoal(tt.tdee, tt.goalType); got != tt.want {
+			t.Errorf("AdjustedGoal(%v, %s) = %d, want %d", tt.tdee, tt.goalType, got, tt.want)
+		}
+	}
+}
+
+// baselineMacros is the split the goals were stored with before the planner
+// existed.
+func baselineMacros(goalType string, calories int) (protein, carbs, fats float64) {
+	switch goalType {
+	case "lose":
+		return float64(calories) * 0.35 / 4.0, float64(calories) * 0.30 / 4.0, float64(calories) * 0.35 / 9.0
+	case "gain":
+		return float64(calories) * 0.30 / 4.0, float64(calories) * 0.45 / 4.0, float64(calories) * 0.25 / 9.0
+	}
+	return float64(calories) * 0.25 / 4.0, float64(calories) * 0.50 / 4.0, float64(calories) * 0.25 / 9.0
+}
+
+func TestDefaultMacrosMatchBaseline(t *testing.T) {
+	planner := DefaultPlanner()
+
+	for _, goalType := range []string{models.GoalLose, models.GoalMaintain, models.GoalGain, "unknown"} {
+		for _, calories := range []int{1200, 1850, 3400} {
+			// A heavy user would be raised by a protein rule; the defaults have none.
+			for _, weight := range []float64{55, 160} {
+				protein, carbs, fats := planner.Macros(Profile{Weight: weight, GoalType: goalType}, calories)
+				wantProtein, wantCarbs, wantFats := baselineMacros(goalType, calories)
+				if protein != wantProtein || carbs != wantCarbs || fats != wantFats {
+					t.Errorf("Macros(%s, %d kcal, %v kg) = %v/%v/%v, want %v/%v/%v", goalType, calories, weight,
+						protein, carbs, fats, wantProtein, wantCarbs, wantFats)
+				}
+			}
+		}
+	}
+}
+
+func TestProteinPerKg(t *testing.T) {
+	planner, err := NewPlanner("", "", "recommended")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		goalType    string
+		weight      float64
+		wantProtein float64
+	}{
+		// The lose preset gives 157.5 g of 1800 kcal.
+		{"preset is enough", models.GoalLose, 60, 157.5},
+		{"raised", models.GoalLose, 100, 160},
+		{"capped at half the calories", models.GoalLose, 150, 225},
+		{"unknown weight", models.GoalLose, 0, 157.5},
+		// The maintain preset gives 112.5 g, 1.2 g/kg of 100 kg is 120 g.
+		{"maintain", models.GoalMaintain, 100, 120},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			protein, carbs, fats := planner.Macros(Profile{Weight: tt.weight, GoalType: tt.goalType}, 1800)
+			if math.Abs(protein-tt.wantProtein) > 1e-9 {
+				t.Errorf("protein = %v g, want %v", protein, tt.wantProtein)
+			}
+			if total := protein*4 + carbs*4 + fats*9; math.Abs(total-1800) > 1e-9 {
+				t.Errorf("macros add up to %v kcal, want 1800", total)
+			}
+			// The rest keeps the preset's carbs to fats ratio.
+			split := planner.Presets[tt.goalType]
+			if got, want := carbs*4/(fats*9), split.Carbs/split.Fats; math.Abs(got-want) > 1e-9 {
+				t.Errorf("carbs to fats = %v, want %v", got, want)
+			}
+		})
+	}
+
+	// A rule of zero turns it off for that goal type.
+	planner, err = NewPlanner("", "", "lose=0,gain=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if protein, _, _ := planner.Macros(Profile{Weight: 100, GoalType: models.GoalLose}, 1800); protein != 157.5 {
+		t.Errorf("lose protein with the rule off = %v g, want 157.5", protein)
+	}
+	if protein, _, _ := planner.Macros(Profile{Weight: 130, GoalType: models.GoalGain}, 3000); protein != 260 {
+		t.Errorf("gain protein at 2 g/kg = %v g, want 260", protein)
+	}
+}
+
+func TestNewPlanner(t *testing.T) {
+	planner, err := NewPlanner(FormulaHarrisBenedict, "lose=40/40/20", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (MacroSplit{Protein: 0.4, Carbs: 0.4, Fats: 0.2}); planner.Presets[models.GoalLose] != want {
+		t.Errorf("lose preset = %+v, want %+v", planner.Presets[models.GoalLose], want)
+	}
+	if planner.Presets[models.GoalGain] != DefaultPlanner().Presets[models.GoalGain] {
+		t.Error("gain preset changed without an override")
+	}
+
+	invalid := []struct {
+		formula, presets, proteinPerKg string
+	}{
+		{"unknown", "", ""},
+		{"", "lose=40/40/30", ""},
+		{"", "lose=40/60", ""},
+		{"", "bulk=30/40/30", ""},
+		{"", "lose", ""},
+		{"", "", "lose=5"},
+		{"", "", "lose=-1"},
+		{"", "", "lose=much"},
+	}
+	for _, tt := range invalid {
+		if _, err := NewPlanner(tt.formula, tt.presets, tt.proteinPerKg); err == nil {
+			t.Errorf("NewPlanner(%q, %q, %q) succeeded", tt.formula, tt.presets, tt.proteinPerKg)
+		}
+	}
+}
@@ -0,0 +1,388 @@
+package importer
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	models "HabitBite/backend/Models"
+	repositories "HabitBite/backend/Repositories"
+)
+
+// FDC nutrient IDs for the values we keep in the catalog.
+const (
+	nutrientEnergy         = 1008
+	nutrientEnergyAtwater  = 2047
+	nutrientEnergySpecific = 2048
+	nutrientProtein        = 1003
+	nutrientFat            = 1004
+	nutrientCarbs          = 1005
+	nutrientCarbsSummation = 1050
+)
+
+const defaultBatchSize = 500
+
+// csvDataTypes maps the data_type column of food.csv to the names used by the
+// FDC API and JSON downloads. Rows of any other type (sample and acquisition
+// records) are not meant for end users and are skipped.
+var csvDataTypes = map[string]string{
+	"foundation_food":   models.FoodTypeFoundation,
+	"sr_legacy_food":    models.FoodTypeSRLegacy,
+	"survey_fndds_food": models.FoodTypeSurvey,
+	"branded_food":      models.FoodTypeBranded,
+}
+
+// FDCImporter loads USDA FoodData Central bulk downloads into the food catalog.
+type FDCImporter struct {
+	foodRepo  repositories.FoodRepository
+	batchSize int
+}
+
+func NewFDCImporter(repo repositories.FoodRepository) *FDCImporter {
+	return &FDCImporter{
+		foodRepo:  repo,
+		batchSize: defaultBatchSize,
+	}
+}
+
+// macros accumulates the nutrient values of a single food while reading.
+type macros struct {
+	energy, energyAtwater, energySpecific float64
+	protein, fat, carbs, carbsSummation   float64
+	hasEnergy, hasAtwater, hasSpecific    bool
+	hasCarbs                              bool
+}
+
+func (m *macros) add(nutrientID int, amount float64) {
+	switch nutrientID {
+	case nutrientEnergy:
+		m.energy, m.hasEnergy = amount, true
+	case nutrientEnergyAtwater:
+		m.energyAtwater, m.hasAtwater = amount, true
+	case nutrientEnergySpecific:
+		m.energySpecific, m.hasSpecific = amount, true
+	case nutrientProtein:
+		m.protein = amount
+	case nutrientFat:
+		m.fat = amount
+	case nutrientCarbs:
+		m.carbs, m.hasCarbs = amount, true
+	case nutrientCarbsSummation:
+		m.carbsSummation = amount
+	}
+}
+
+// apply copies the accumulated values onto the food. Foundation foods often
+// report energy only through the Atwater factors, so those are used as a
+// fallback before deriving calories from the macros themselves.
+func (m *macros) apply(food *models.Food) {
+	food.Protein = m.protein
+	food.Fat = m.fat
+	food.Carbs = m.carbs
+	if !m.hasCarbs {
+		food.Carbs = m.carbsSummation
+	}
+
+	switch {
+	case m.hasEnergy:
+		food.Calories = m.energy
+	case m.hasAtwater:
+		food.Calories = m.energyAtwater
+	case m.hasSpecific:
+		food.Calories = m.energySpecific
+	default:
+		food.Calories = 4*food.Protein + 4*food.Carbs + 9*food.Fat
+	}
+}
+
+// ImportCSV imports an extracted FDC CSV download. The directory must contain
+// food.csv and food_nutrient.csv; branded_food.csv is read when present to
+// fill in brand owners.
+func (i *FDCImporter) ImportCSV(ctx context.Context, dir string) (int, error) {
+	nutrients, err := readNutrientsCSV(filepath.Join(dir, "food_nutrient.csv"))
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("Loaded nutrients for %d foods", len(nutrients))
+
+	brands, err := readBrandsCSV(filepath.Join(dir, "branded_food.csv"))
+	if err != nil {
+		return 0, err
+	}
+
+	file, err := os.Open(filepath.Join(dir, "food.csv"))
+	if err != nil {
+		return 0, fmt.Errorf("could not open food.csv: %v", err)
+	}
+	defer file.Close()
+
+	reader, columns, err := newCSVReader(file, "fdc_id", "data_type", "description")
+	if err != nil {
+		return 0, fmt.Errorf("food.csv: %v", err)
+	}
+
+	imported := 0
+	batch := make([]*models.Food, 0, i.batchSize)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return imported, fmt.Errorf("failed to read food.csv: %v", err)
+		}
+
+		dataType, ok := csvDataTypes[record[columns["data_type"]]]
+		if !ok {
+			continue
+		}
+
+		fdcID, err := strconv.Atoi(record[columns["fdc_id"]])
+		if err != nil {
+			continue
+		}
+
+		food := &models.Food{
+			FdcID:       fdcID,
+			Description: strings.TrimSpace(record[columns["description"]]),
+			DataType:    dataType,
+			BrandOwner:  brands[fdcID],
+		}
+		if m, ok := nutrients[fdcID]; ok {
+			m.apply(food)
+		}
+
+		batch = append(batch, food)
+		if len(batch) == i.batchSize {
+			if err := i.flush(ctx, batch); err != nil {
+				return imported, err
+			}
+			imported += len(batch)
+			batch = batch[:0]
+		}
+	}
+
+	if err := i.flush(ctx, batch); err != nil {
+		return imported, err
+	}
+	imported += len(batch)
+
+	return imported, nil
+}
+
+// fdcJSONFood is the subset of an FDC JSON food record used by the catalog.
+type fdcJSONFood struct {
+	FdcID         int    `json:"fdcId"`
+	Description   string `json:"description"`
+	DataType      string `json:"dataType"`
+	BrandOwner    string `json:"brandOwner"`
+	FoodNutrients []struct {
+		Nutrient struct {
+			ID int `json:"id"`
+		} `json:"nutrient"`
+		Amount float64 `json:"amount"`
+	} `json:"foodNutrients"`
+}
+
+// ImportJSON imports an FDC JSON download such as
+// FoodData_Central_foundation_food_json_*.json. The files wrap a single array
+// of foods in an object ({"FoundationFoods": [...]}), which is streamed so the
+// multi-gigabyte branded download does not have to fit in memory.
+func (i *FDCImporter) ImportJSON(ctx context.Context, path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("could not open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	if err := expectDelim(decoder, '{'); err != nil {
+		return 0, err
+	}
+	if _, err := decoder.Token(); err != nil {
+		return 0, fmt.Errorf("failed to read JSON key: %v", err)
+	}
+	if err := expectDelim(decoder, '['); err != nil {
+		return 0, err
+	}
+
+	imported := 0
+	batch := make([]*models.Food, 0, i.batchSize)
+	for decoder.More() {
+		var record fdcJSONFood
+		if err := decoder.Decode(&record); err != nil {
+			return imported, fmt.Errorf("failed to decode food: %v", err)
+		}
+
+		var m macros
+		for _, n := range record.FoodNutrients {
+			m.add(n.Nutrient.ID, n.Amount)
+		}
+
+		food := &models.Food{
+			FdcID:       record.FdcID,
+			Description: strings.TrimSpace(record.Description),
+			DataType:    record.DataType,
+			BrandOwner:  strings.TrimSpace(record.BrandOwner),
+		}
+		m.apply(food)
+
+		batch = append(batch, food)
+		if len(batch) == i.batchSize {
+			if err := i.flush(ctx, batch); err != nil {
+				return imported, err
+			}
+			imported += len(batch)
+			batch = batch[:0]
+		}
+	}
+
+	if err := i.flush(ctx, batch); err != nil {
+		return imported, err
+	}
+	imported += len(batch)
+
+	return imported, nil
+}
+
+func (i *FDCImporter) flush(ctx context.Context, batch []*models.Food) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := i.foodRepo.UpsertFoods(ctx, batch); err != nil {
+		return fmt.Errorf("failed to store foods: %v", err)
+	}
+	return nil
+}
+
+func readNutrientsCSV(path string) (map[int]*macros, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open food_nutrient.csv: %v", err)
+	}
+	defer file.Close()
+
+	reader, columns, err := newCSVReader(file, "fdc_id", "nutrient_id", "amount")
+	if err != nil {
+		return nil, fmt.Errorf("food_nutrient.csv: %v", err)
+	}
+
+	nutrients := make(map[int]*macros)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read food_nutrient.csv: %v", err)
+		}
+
+		nutrientID, err := strconv.Atoi(record[columns["nutrient_id"]])
+		if err != nil {
+			continue
+		}
+		fdcID, err := strconv.Atoi(record[columns["fdc_id"]])
+		if err != nil {
+			continue
+		}
+		amount, err := strconv.ParseFloat(record[columns["amount"]], 64)
+		if err != nil {
+			continue
+		}
+
+		m, ok := nutrients[fdcID]
+		if !ok {
+			m = &macros{}
+			nutrients[fdcID] = m
+		}
+		m.add(nutrientID, amount)
+	}
+
+	return nutrients, nil
+}
+
+func readBrandsCSV(path string) (map[int]string, error) {
+	brands := make(map[int]string)
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return brands, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open branded_food.csv: %v", err)
+	}
+	defer file.Close()
+
+	reader, columns, err := newCSVReader(file, "fdc_id", "brand_owner")
+	if err != nil {
+		return nil, fmt.Errorf("branded_food.csv: %v", err)
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read branded_food.csv: %v", err)
+		}
+
+		fdcID, err := strconv.Atoi(record[columns["fdc_id"]])
+		if err != nil {
+			continue
+		}
+		brands[fdcID] = strings.TrimSpace(record[columns["brand_owner"]])
+	}
+
+	return brands, nil
+}
+
+// newCSVReader reads the header row and returns the index of each required
+// column.
+func newCSVReader(r io.Reader, required ...string) (*csv.Reader, map[string]int, error) {
+	buffered := bufio.NewReader(r)
+	if bom, err := buffered.Peek(3); err == nil && string(bom) == "\ufeff" {
+		buffered.Discard(3)
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read header: %v", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for idx, name := range header {
+		columns[name] = idx
+	}
+
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	return reader, columns, nil
+}
+
+func expectDelim(decoder *json.Decoder, want json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("failed to read JSON: %v", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("unexpected JSON token %v, expected %v", token, want)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package jobs
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	alerts "HabitBite/backend/Alerts"
+	models "HabitBite/backend/Models"
+)
+
+// evaluateAlerts walks the clients in batches of ascending ID, evaluates the
+// alert rules on the day of params.AsOf and records the findings for each of
+// their dietitians. Alerts of clients without a dietitian are resolved.
+func (r *Runner) evaluateAlerts(job *models.Job) error {
+	var params models.EvaluateAlertsParams
+	if err := job.Params.Unmarshal(&params); err != nil {
+		return err
+	}
+	asOf, err := time.Parse("2006-01-02", params.AsOf)
+	if err != nil {
+		return err
+	}
+	start := asOf.AddDate(0, 0, 1-r.rules.Window())
+
+	if job.Total == 0 {
+		total, err := r.store.Users.CountUsers(r.ctx, "user")
+		if err != nil {
+			return err
+		}
+		job.Total = total
+		r.save(job)
+	}
+
+	for {
+		users, err := r.store.Users.GetUsersAfter(r.ctx, job.LastID, "user", r.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			return nil
+		}
+
+		userIDs := make([]int, len(users))
+		for i, user := range users {
+			userIDs[i] = user.ID
+		}
+		dietitians, err := r.store.Users.GetDietitiansForUsers(r.ctx, userIDs)
+		if err != nil {
+			return err
+		}
+		goals, err := r.store.Users.GetGoalsForUsers(r.ctx, userIDs)
+		if err != nil {
+			return err
+		}
+		totals, err := r.store.FoodEntries.GetDailyTotalsForUsers(r.ctx, userIDs, start, asOf)
+		if err != nil {
+			return err
+		}
+		lastLogs, err := r.store.FoodEntries.GetLastLogDates(r.ctx, userIDs, asOf)
+		if err != nil {
+			return err
+		}
+
+		days := make(map[int]map[string]models.DailyTotals)
+		for _, day := range totals {
+			if days[day.UserID] == nil {
+				days[day.UserID] = make(map[string]models.DailyTotals)
+			}
+			days[day.UserID][day.Date] = day
+		}
+
+		now := time.Now()
+		for i := range users {
+			if r.ctx.Err() != nil {
+				return r.ctx.Err()
+			}
+			user := &users[i]
+
+			client := &alerts.Client{Joined: user.CreatedAt, Days: days[user.ID]}
+			if userGoals, ok := goals[user.ID]; ok {
+				client.Goals = &userGoals
+			}
+			if lastLog, ok := lastLogs[user.ID]; ok {
+				client.LastLog = &lastLog
+			}
+
+			var findings []models.AlertFinding
+			if len(dietitians[user.ID]) > 0 {
+				findings = r.rules.Evaluate(client, asOf)
+			}
+			opened, err := r.store.Alerts.SyncAlerts(r.ctx, user.ID, dietitians[user.ID], findings, now)
+			if err != nil {
+				log.Printf("Job %d: error saving alerts for user %d: %v", job.ID, user.ID, err)
+				job.Failed++
+			} else {
+				job.Changed += opened
+			}
+
+			job.Processed++
+			job.LastID = user.ID
+		}
+
+		r.save(job)
+	}
+}
+
+// ScheduleAlerts starts the alert evaluation of the previous day every night
+// at the given hour, local time, until the runner shuts down.
+func (r *Runner) ScheduleAlerts(hour int) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		for {
+			now := time.Now()
+			next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+			if !next.After(now) {
+				next = next.AddDate(0, 0, 1)
+			}
+
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-r.ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			job, err := r.StartEvaluateAlerts(r.ctx, 0, models.EvaluateAlertsParams{})
+			switch {
+			case errors.Is(err, ErrJobActive):
+				log.Printf("Skipping nightly alert evaluation: the previous one is still running")
+			case err != nil:
+				log.Printf("Error starting nightly alert evaluation: %v", err)
+			default:
+				log.Printf("Started nightly alert evaluation as job %d", job.ID)
+			}
+		}
+	}()
+}
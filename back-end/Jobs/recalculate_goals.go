@@ -0,0 +1,85 @@
+package jobs
+
+import (
+	"log"
+	"math"
+
+	models "HabitBite/backend/Models"
+)
+
+// recalculateGoals walks the users in batches of ascending ID and updates the
+// macro targets that no longer match the planner for their calorie goal. The
+// calorie goal and target weight are kept. Users without goals get generated
+// ones.
+func (r *Runner) recalculateGoals(job *models.Job) error {
+	var params models.RecalculateGoalsParams
+	if err := job.Params.Unmarshal(&params); err != nil {
+		return err
+	}
+
+	if job.Total == 0 {
+		total, err := r.store.Users.CountUsers(r.ctx, params.Role)
+		if err != nil {
+			return err
+		}
+		job.Total = total
+		r.save(job)
+	}
+
+	for {
+		users, err := r.store.Users.GetUsersAfter(r.ctx, job.LastID, params.Role, r.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			return nil
+		}
+
+		userIDs := make([]int, len(users))
+		for i, user := range users {
+			userIDs[i] = user.ID
+		}
+		stored, err := r.store.Users.GetGoalsForUsers(r.ctx, userIDs)
+		if err != nil {
+			return err
+		}
+
+		for i := range users {
+			if r.ctx.Err() != nil {
+				return r.ctx.Err()
+			}
+			user := &users[i]
+
+			current, ok := stored[user.ID]
+			updated := r.store.Goals.Goals(user, user.DailyCalorieGoal)
+			if ok {
+				updated.TargetWeight = current.TargetWeight
+			}
+
+			if !ok || !sameTargets(&current, updated) {
+				if params.DryRun {
+					job.Changed++
+				} else if err := r.store.Users.UpdateUserGoals(r.ctx, updated); err != nil {
+					log.Printf("Job %d: error updating goals for user %d: %v", job.ID, user.ID, err)
+					job.Failed++
+				} else {
+					job.Changed++
+				}
+			}
+
+			job.Processed++
+			job.LastID = user.ID
+		}
+
+		r.save(job)
+	}
+}
+
+// sameTargets compares goals at the precision the database stores them with.
+func sameTargets(a, b *models.UserGoals) bool {
+	const epsilon = 0.01
+	return a.TargetCalories == b.TargetCalories &&
+		math.Abs(a.TargetProtein-b.TargetProtein) < epsilon &&
+		math.Abs(a.TargetCarbs-b.TargetCarbs) < epsilon &&
+		math.Abs(a.TargetFats-b.TargetFats) < epsilon
+}
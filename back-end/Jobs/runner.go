@@ -0,0 +1,186 @@
+// Package jobs runs long administrative tasks in the background and records
+// their progress in the jobs table.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	alerts "HabitBite/backend/Alerts"
+	models "HabitBite/backend/Models"
+	repositories "HabitBite/backend/Repositories"
+)
+
+const defaultBatchSize = 200
+
+// ErrJobActive is returned when a job of the same type is already queued or
+// running.
+var ErrJobActive = errors.New("a job of this type is already active")
+
+// Runner executes jobs in goroutines of the server process. Stopping the
+// runner leaves a job running in the table; Resume picks it up again from its
+// last processed ID.
+type Runner struct {
+	store     *repositories.Store
+	rules     *alerts.Rules
+	batchSize int
+
+	mu     sync.Mutex // serializes starting jobs
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewRunner(store *repositories.Store, rules *alerts.Rules) *Runner {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Runner{
+		store:     store,
+		rules:     rules,
+		batchSize: defaultBatchSize,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Resume restarts the jobs left queued or running by a previous process.
+func (r *Runner) Resume(ctx context.Context) error {
+	jobs, err := r.store.Jobs.GetUnfinishedJobs(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range jobs {
+		log.Printf("Resuming job %d (%s) after ID %d", jobs[i].ID, jobs[i].Type, jobs[i].LastID)
+		r.start(&jobs[i])
+	}
+	return nil
+}
+
+// Shutdown stops the running jobs after their current user and waits for
+// their progress to be saved, or for ctx to expire.
+func (r *Runner) Shutdown(ctx context.Context) error {
+	r.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// StartRecalculateGoals queues a job that recalculates the macro targets of
+// every user, or of every user with params.Role, from their calorie goal.
+func (r *Runner) StartRecalculateGoals(ctx context.Context, createdBy int, params models.RecalculateGoalsParams) (*models.Job, error) {
+	return r.create(ctx, models.JobRecalculateGoals, createdBy, params)
+}
+
+// StartEvaluateAlerts queues a job that evaluates the alert rules for every
+// client on params.AsOf, yesterday by default.
+func (r *Runner) StartEvaluateAlerts(ctx context.Context, createdBy int, params models.EvaluateAlertsParams) (*models.Job, error) {
+	if params.AsOf == "" {
+		params.AsOf = time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	}
+	return r.create(ctx, models.JobEvaluateAlerts, createdBy, params)
+}
+
+func (r *Runner) create(ctx context.Context, jobType string, createdBy int, params interface{}) (*models.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	active, err := r.store.Jobs.GetUnfinishedJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range active {
+		if job.Type == jobType {
+			return nil, ErrJobActive
+		}
+	}
+
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.Job{
+		Type:      jobType,
+		Status:    models.JobQueued,
+		Params:    encoded,
+		CreatedBy: createdBy,
+	}
+	if err := r.store.Jobs.CreateJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	// The goroutine works on its own copy, the caller's is returned as queued.
+	running := *job
+	r.start(&running)
+	return job, nil
+}
+
+func (r *Runner) start(job *models.Job) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.run(job)
+	}()
+}
+
+func (r *Runner) run(job *models.Job) {
+	if job.Status == models.JobQueued {
+		now := time.Now()
+		job.Status = models.JobRunning
+		job.StartedAt = &now
+	}
+
+	var err error
+	switch job.Type {
+	case models.JobRecalculateGoals:
+		err = r.recalculateGoals(job)
+	case models.JobEvaluateAlerts:
+		err = r.evaluateAlerts(job)
+	default:
+		err = fmt.Errorf("unknown job type %q", job.Type)
+	}
+
+	if r.ctx.Err() != nil {
+		// Interrupted by Shutdown, the job continues on the next Resume.
+		r.save(job)
+		log.Printf("Job %d stopped after ID %d", job.ID, job.LastID)
+		return
+	}
+
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = models.JobSucceeded
+	if err != nil {
+		message := err.Error()
+		job.Status = models.JobFailed
+		job.ErrorMessage = &message
+		log.Printf("Job %d failed: %v", job.ID, err)
+	}
+	r.save(job)
+}
+
+// save writes the progress of job without the runner context, so progress is
+// kept even while shutting down.
+func (r *Runner) save(job *models.Job) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := r.store.Jobs.UpdateJob(ctx, job); err != nil {
+		log.Printf("Error saving progress of job %d: %v", job.ID, err)
+	}
+}
@@ -0,0 +1,75 @@
+// Package mailer sends the transactional emails of the application, such as
+// password reset links. Handlers depend on the Mailer interface only; SMTP
+// delivers real mail and the file mailer stands in during local development.
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Supported values of MAIL_DRIVER
+const (
+	DriverLog  = "log"
+	DriverFile = "file"
+	DriverSMTP = "smtp"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Settings configures New.
+type Settings struct {
+	Driver string
+	From   string
+	// File is where DriverFile appends messages.
+	File string
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUser     string
+	SMTPPassword string
+}
+
+// New returns the mailer of settings.Driver. DriverLog writes messages to the
+// standard logger.
+func New(settings Settings) (Mailer, error) {
+	switch settings.Driver {
+	case "", DriverLog:
+		return NewFileMailer(""), nil
+	case DriverFile:
+		if settings.File == "" {
+			return nil, errors.New("MAIL_FILE is required for the file mail driver")
+		}
+		return NewFileMailer(settings.File), nil
+	case DriverSMTP:
+		if settings.SMTPHost == "" {
+			return nil, errors.New("SMTP_HOST is required for the smtp mail driver")
+		}
+		return NewSMTPMailer(settings.SMTPHost, settings.SMTPPort, settings.SMTPUser, settings.SMTPPassword, settings.From), nil
+	default:
+		return nil, fmt.Errorf("unsupported MAIL_DRIVER %q", settings.Driver)
+	}
+}
+
+// validate rejects addresses and subjects that would inject headers.
+func (m Message) validate() error {
+	if m.To == "" {
+		return errors.New("message has no recipient")
+	}
+	if strings.ContainsAny(m.To+m.Subject, "\r\n") {
+		return errors.New("message header contains a line break")
+	}
+	return nil
+}
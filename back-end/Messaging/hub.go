@@ -0,0 +1,103 @@
+// Package messaging delivers conversation events to the clients streaming
+// them. Delivery is in-process only: with several server instances a client
+// receives the events of the instance it is connected to, and catches up on
+// the rest through the message history.
+package messaging
+
+import (
+	"sync"
+)
+
+// Event types sent to subscribers.
+const (
+	EventMessage = "message"
+	EventRead    = "read"
+)
+
+// subscriberBuffer is how many events a slow subscriber may fall behind
+// before further events are dropped for it.
+const subscriberBuffer = 16
+
+// Event is a change to a conversation. Data is sent to the client as JSON.
+type Event struct {
+	Type string
+	Data interface{}
+}
+
+// Hub fans out the events of each conversation to its subscribers.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[int]map[chan Event]struct{}
+	closed      bool
+}
+
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[int]map[chan Event]struct{})}
+}
+
+// Subscribe returns a channel receiving the events of the conversation, and
+// a function that unsubscribes. The channel is closed when the hub is
+// closed.
+func (h *Hub) Subscribe(conversationID int) (<-chan Event, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan Event, subscriberBuffer)
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	if h.subscribers[conversationID] == nil {
+		h.subscribers[conversationID] = make(map[chan Event]struct{})
+	}
+	h.subscribers[conversationID][ch] = struct{}{}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() { h.unsubscribe(conversationID, ch) })
+	}
+}
+
+func (h *Hub) unsubscribe(conversationID int, ch chan Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscribers[conversationID][ch]; !ok {
+		return
+	}
+	delete(h.subscribers[conversationID], ch)
+	if len(h.subscribers[conversationID]) == 0 {
+		delete(h.subscribers, conversationID)
+	}
+	close(ch)
+}
+
+// Publish sends event to the subscribers of the conversation without
+// waiting; a subscriber whose buffer is full misses it.
+func (h *Hub) Publish(conversationID int, event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers[conversationID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Close ends every subscription, so open streams return and the server can
+// shut down.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for conversationID, channels := range h.subscribers {
+		for ch := range channels {
+			close(ch)
+		}
+		delete(h.subscribers, conversationID)
+	}
+}
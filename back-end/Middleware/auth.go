@@ -31,6 +31,9 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
 			c.Set("userID", claims["sub"])
 			c.Set("userRole", claims["role"])
+			if sessionID, ok := claims["sid"].(string); ok {
+				c.Set("sessionID", sessionID)
+			}
 			// fmt.Println("claims: ", claims)
 
 			if _, err := c.Cookie("csrf_token"); err != nil {
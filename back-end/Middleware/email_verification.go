@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailVerificationChecker reports whether a user verified their email.
+type EmailVerificationChecker interface {
+	IsEmailVerified(ctx context.Context, userID int) (bool, error)
+}
+
+// RequireVerifiedEmail rejects writes by users who haven't verified their
+// email. Reads stay allowed, and so does everything under /api/auth/, so the
+// user can still manage their account and sessions. It must run after
+// AuthMiddleware.
+func RequireVerifiedEmail(users EmailVerificationChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if strings.HasPrefix(c.FullPath(), "/api/auth/") {
+			c.Next()
+			return
+		}
+
+		userID, exists := c.Get("userID")
+		if !exists {
+			c.Next()
+			return
+		}
+
+		verified, err := users.IsEmailVerified(c.Request.Context(), int(userID.(float64)))
+		if err != nil {
+			log.Printf("Error checking email verification: %v", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check email verification"})
+			return
+		}
+		if !verified {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Please verify your email address to make changes"})
+			return
+		}
+
+		c.Next()
+	}
+}
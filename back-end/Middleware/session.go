@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionChecker reports whether the session of a token family is active.
+type SessionChecker interface {
+	IsSessionActive(ctx context.Context, familyID string, now time.Time) (bool, error)
+}
+
+// SessionMiddleware rejects access tokens whose session was signed out, so
+// revoking a session takes effect before its access tokens expire. It must
+// run after AuthMiddleware. Tokens issued without a session are accepted.
+func SessionMiddleware(sessions SessionChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sessionID := c.GetString("sessionID")
+		if sessionID == "" {
+			c.Next()
+			return
+		}
+
+		active, err := sessions.IsSessionActive(c.Request.Context(), sessionID, time.Now())
+		if err != nil {
+			log.Printf("Error checking session: %v", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check session"})
+			return
+		}
+		if !active {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session has been signed out"})
+			return
+		}
+
+		c.Next()
+	}
+}
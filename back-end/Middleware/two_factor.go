@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	config "HabitBite/backend/Config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorChecker reports whether a user logs in with a second factor.
+type TwoFactorChecker interface {
+	IsTwoFactorEnabled(ctx context.Context, userID int) (bool, error)
+}
+
+// RequireTwoFactor rejects requests by users whose role must use two-factor
+// login until they have set it up. Everything under /api/auth/ stays allowed,
+// so they can do so. It must run after AuthMiddleware.
+func RequireTwoFactor(cfg *config.Config, twoFactor TwoFactorChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.TwoFactorRequired(c.GetString("userRole")) || strings.HasPrefix(c.FullPath(), "/api/auth/") {
+			c.Next()
+			return
+		}
+
+		userID, exists := c.Get("userID")
+		if !exists {
+			c.Next()
+			return
+		}
+
+		enabled, err := twoFactor.IsTwoFactorEnabled(c.Request.Context(), int(userID.(float64)))
+		if err != nil {
+			log.Printf("Error checking two-factor setup: %v", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check two-factor setup"})
+			return
+		}
+		if !enabled {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is required for your role, please set it up first"})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,78 @@
+-- Baseline schema. Tables are created only when missing so databases that
+-- were set up from the old habitbite.sql dump can adopt migrations as is.
+
+CREATE TABLE IF NOT EXISTS `users` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `email` varchar(100) NOT NULL,
+  `username` varchar(50) NOT NULL,
+  `password_hash` varchar(255) NOT NULL,
+  `full_name` varchar(100) NOT NULL,
+  `birthdate` date NOT NULL,
+  `gender` enum('male','female','other') NOT NULL,
+  `height` decimal(5,2) NOT NULL,
+  `weight` decimal(5,2) NOT NULL,
+  `goal_type` enum('lose','gain','maintain') NOT NULL,
+  `activity_level` enum('sedentary','light','moderate','active','very_active') NOT NULL,
+  `daily_calorie_goal` int(11) NOT NULL DEFAULT 2000,
+  `role` enum('admin','dietitian','user') NOT NULL DEFAULT 'user',
+  `created_at` timestamp NOT NULL DEFAULT current_timestamp(),
+  `updated_at` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `email` (`email`),
+  UNIQUE KEY `username` (`username`),
+  KEY `idx_users_email` (`email`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+CREATE TABLE IF NOT EXISTS `consumed_foods` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `user_id` int(11) NOT NULL,
+  `food_id` varchar(50) NOT NULL,
+  `food_name` varchar(255) NOT NULL,
+  `quantity` decimal(10,2) NOT NULL,
+  `calories` decimal(10,2) NOT NULL,
+  `protein` decimal(10,2) NOT NULL,
+  `carbs` decimal(10,2) NOT NULL,
+  `fats` decimal(10,2) NOT NULL,
+  `entry_date` datetime NOT NULL,
+  `created_at` datetime NOT NULL,
+  `updated_at` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_user_date` (`user_id`,`entry_date`),
+  CONSTRAINT `consumed_foods_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS `daily_entries` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `user_id` int(11) NOT NULL,
+  `entry_date` date NOT NULL,
+  `total_calories` int(11) NOT NULL,
+  `total_protein` decimal(5,2) DEFAULT NULL,
+  `total_carbs` decimal(5,2) DEFAULT NULL,
+  `total_fats` decimal(5,2) DEFAULT NULL,
+  `notes` text DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `user_id` (`user_id`,`entry_date`),
+  KEY `idx_entries_user_date` (`user_id`,`entry_date`),
+  CONSTRAINT `daily_entries_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+CREATE TABLE IF NOT EXISTS `user_dietitian` (
+  `user_id` int(11) NOT NULL,
+  `dietitian_id` int(11) NOT NULL,
+  `assigned_at` timestamp NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (`user_id`,`dietitian_id`),
+  KEY `dietitian_id` (`dietitian_id`),
+  CONSTRAINT `user_dietitian_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `user_dietitian_ibfk_2` FOREIGN KEY (`dietitian_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+CREATE TABLE IF NOT EXISTS `user_goals` (
+  `user_id` int(11) NOT NULL,
+  `target_calories` int(11) NOT NULL,
+  `target_protein` decimal(5,2) DEFAULT NULL,
+  `target_carbs` decimal(5,2) DEFAULT NULL,
+  `target_fats` decimal(5,2) DEFAULT NULL,
+  `target_weight` decimal(5,2) DEFAULT NULL,
+  PRIMARY KEY (`user_id`),
+  CONSTRAINT `user_goals_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
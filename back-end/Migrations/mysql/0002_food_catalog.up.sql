@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS `foods` (
+  `fdc_id` int(11) NOT NULL,
+  `description` varchar(255) NOT NULL,
+  `data_type` varchar(30) NOT NULL,
+  `brand_owner` varchar(255) NOT NULL DEFAULT '',
+  `calories` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT 'per 100 g',
+  `protein` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT 'per 100 g',
+  `carbs` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT 'per 100 g',
+  `fats` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT 'per 100 g',
+  `created_at` datetime NOT NULL,
+  `updated_at` datetime NOT NULL,
+  PRIMARY KEY (`fdc_id`),
+  KEY `idx_foods_data_type` (`data_type`),
+  FULLTEXT KEY `ft_foods_search` (`description`,`brand_owner`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `audit_log` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `actor_id` int(11) NOT NULL,
+  `actor_role` varchar(20) NOT NULL,
+  `action` varchar(50) NOT NULL,
+  `target_user_id` int(11) NOT NULL,
+  `entity_id` int(11) DEFAULT NULL,
+  `ip_address` varchar(45) NOT NULL DEFAULT '',
+  `created_at` timestamp NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (`id`),
+  KEY `idx_audit_target` (`target_user_id`,`id`),
+  KEY `idx_audit_actor` (`actor_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS `weight_entries` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `user_id` int(11) NOT NULL,
+  `weight` decimal(5,2) NOT NULL,
+  `recorded_at` datetime NOT NULL,
+  `created_at` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_weight_user_recorded` (`user_id`,`recorded_at`),
+  CONSTRAINT `weight_entries_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
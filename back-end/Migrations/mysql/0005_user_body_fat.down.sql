@@ -0,0 +1 @@
+ALTER TABLE `users` DROP COLUMN `body_fat`;
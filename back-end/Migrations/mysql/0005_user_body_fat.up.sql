@@ -0,0 +1 @@
+ALTER TABLE `users` ADD COLUMN `body_fat` decimal(4,1) DEFAULT NULL AFTER `weight`;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `dietitian_profiles`;
+DROP TABLE IF EXISTS `dietitian_applications`;
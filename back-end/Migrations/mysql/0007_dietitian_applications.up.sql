@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS `dietitian_applications` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `user_id` int(11) NOT NULL,
+  `credentials` varchar(500) NOT NULL,
+  `license_number` varchar(100) NOT NULL,
+  `bio` text NOT NULL,
+  `status` enum('pending','approved','rejected') NOT NULL DEFAULT 'pending',
+  `reviewed_by` int(11) DEFAULT NULL,
+  `review_reason` text DEFAULT NULL,
+  `created_at` datetime NOT NULL,
+  `reviewed_at` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_dietitian_applications_status` (`status`,`id`),
+  KEY `idx_dietitian_applications_user` (`user_id`),
+  CONSTRAINT `dietitian_applications_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS `dietitian_profiles` (
+  `user_id` int(11) NOT NULL,
+  `display_name` varchar(100) NOT NULL,
+  `credentials` varchar(500) NOT NULL,
+  `license_number` varchar(100) NOT NULL,
+  `bio` text NOT NULL,
+  `created_at` datetime NOT NULL,
+  `updated_at` datetime NOT NULL,
+  PRIMARY KEY (`user_id`),
+  CONSTRAINT `dietitian_profiles_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,8 @@
+ALTER TABLE `dietitian_profiles` DROP COLUMN `client_capacity`;
+
+DELETE FROM `user_dietitian` WHERE `status` <> 'accepted';
+ALTER TABLE `user_dietitian`
+  DROP KEY `idx_user_dietitian_status`,
+  DROP COLUMN `ended_at`,
+  DROP COLUMN `responded_at`,
+  DROP COLUMN `status`;
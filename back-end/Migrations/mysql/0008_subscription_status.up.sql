@@ -0,0 +1,10 @@
+ALTER TABLE `user_dietitian`
+  ADD COLUMN `status` enum('pending','accepted','declined','ended') NOT NULL DEFAULT 'pending' AFTER `dietitian_id`,
+  ADD COLUMN `responded_at` datetime DEFAULT NULL AFTER `assigned_at`,
+  ADD COLUMN `ended_at` datetime DEFAULT NULL AFTER `responded_at`,
+  ADD KEY `idx_user_dietitian_status` (`dietitian_id`,`status`);
+
+-- Relationships created before requests had to be accepted stay active.
+UPDATE `user_dietitian` SET `status` = 'accepted', `responded_at` = `assigned_at`;
+
+ALTER TABLE `dietitian_profiles` ADD COLUMN `client_capacity` int(11) DEFAULT NULL AFTER `bio`;
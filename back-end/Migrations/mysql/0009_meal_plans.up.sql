@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS `meal_plans` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `user_id` int(11) NOT NULL,
+  `dietitian_id` int(11) NOT NULL,
+  `name` varchar(100) NOT NULL,
+  `notes` text DEFAULT NULL,
+  `days` int(11) NOT NULL,
+  `start_date` date NOT NULL,
+  `end_date` date NOT NULL,
+  `created_at` datetime NOT NULL,
+  `updated_at` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_meal_plans_user_dates` (`user_id`,`start_date`,`end_date`),
+  CONSTRAINT `meal_plans_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `meal_plans_ibfk_2` FOREIGN KEY (`dietitian_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS `meal_plan_items` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `plan_id` int(11) NOT NULL,
+  `day_number` int(11) NOT NULL,
+  `meal` enum('breakfast','lunch','dinner','snack') NOT NULL,
+  `food_id` varchar(50) NOT NULL,
+  `food_name` varchar(255) NOT NULL,
+  `quantity` decimal(10,2) NOT NULL,
+  `calories` decimal(10,2) NOT NULL,
+  `protein` decimal(10,2) NOT NULL,
+  `carbs` decimal(10,2) NOT NULL,
+  `fats` decimal(10,2) NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_meal_plan_items_plan` (`plan_id`,`day_number`),
+  CONSTRAINT `meal_plan_items_ibfk_1` FOREIGN KEY (`plan_id`) REFERENCES `meal_plans` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
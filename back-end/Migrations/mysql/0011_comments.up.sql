@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS `comments` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `user_id` int(11) NOT NULL,
+  `dietitian_id` int(11) NOT NULL,
+  `author_id` int(11) NOT NULL,
+  `parent_id` int(11) DEFAULT NULL,
+  `entry_id` int(11) DEFAULT NULL,
+  `entry_date` date DEFAULT NULL,
+  `body` text NOT NULL,
+  `created_at` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_comments_user_date` (`user_id`,`entry_date`),
+  KEY `idx_comments_entry` (`entry_id`),
+  KEY `idx_comments_parent` (`parent_id`),
+  CONSTRAINT `comments_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `comments_ibfk_2` FOREIGN KEY (`dietitian_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `comments_ibfk_3` FOREIGN KEY (`author_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `comments_ibfk_4` FOREIGN KEY (`parent_id`) REFERENCES `comments` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `comments_ibfk_5` FOREIGN KEY (`entry_id`) REFERENCES `consumed_foods` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
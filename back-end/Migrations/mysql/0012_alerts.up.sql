@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS `alerts` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `dietitian_id` int(11) NOT NULL,
+  `user_id` int(11) NOT NULL,
+  `rule` varchar(50) NOT NULL,
+  `status` enum('open','acknowledged','resolved') NOT NULL DEFAULT 'open',
+  `message` varchar(255) NOT NULL,
+  `detected_at` datetime NOT NULL,
+  `last_seen_at` datetime NOT NULL,
+  `acknowledged_at` datetime DEFAULT NULL,
+  `snoozed_until` datetime DEFAULT NULL,
+  `resolved_at` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_alerts_rule` (`dietitian_id`,`user_id`,`rule`),
+  KEY `idx_alerts_user` (`user_id`),
+  KEY `idx_alerts_status` (`dietitian_id`,`status`),
+  CONSTRAINT `alerts_ibfk_1` FOREIGN KEY (`dietitian_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `alerts_ibfk_2` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS `sessions` (
+  `id` int(11) NOT NULL AUTO_INCREMENT,
+  `user_id` int(11) NOT NULL,
+  `family_id` char(32) NOT NULL,
+  `user_agent` varchar(255) NOT NULL DEFAULT '',
+  `ip_address` varchar(45) NOT NULL DEFAULT '',
+  `created_at` datetime NOT NULL,
+  `last_used_at` datetime NOT NULL,
+  `revoked_at` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_sessions_family` (`family_id`),
+  KEY `idx_sessions_user` (`user_id`,`revoked_at`),
+  CONSTRAINT `sessions_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO `sessions` (`user_id`, `family_id`, `created_at`, `last_used_at`, `revoked_at`)
+SELECT `user_id`, `family_id`, MIN(`created_at`), MAX(`created_at`), MAX(`revoked_at`)
+FROM `refresh_tokens`
+GROUP BY `user_id`, `family_id`;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `recovery_codes`;
+DROP TABLE IF EXISTS `user_two_factor`;
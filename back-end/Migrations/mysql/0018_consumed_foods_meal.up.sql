@@ -0,0 +1 @@
+ALTER TABLE `consumed_foods` ADD COLUMN `meal` enum('breakfast','lunch','dinner','snack') NOT NULL DEFAULT 'snack' AFTER `fats`;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `mfa_challenges`;
+ALTER TABLE `user_two_factor` DROP COLUMN `locked_until`, DROP COLUMN `failed_attempts`;
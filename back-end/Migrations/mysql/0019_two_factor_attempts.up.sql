@@ -0,0 +1,15 @@
+ALTER TABLE `user_two_factor`
+  ADD COLUMN `failed_attempts` int(11) NOT NULL DEFAULT 0 AFTER `last_used_step`,
+  ADD COLUMN `locked_until` datetime DEFAULT NULL AFTER `failed_attempts`;
+
+CREATE TABLE IF NOT EXISTS `mfa_challenges` (
+  `id` char(32) NOT NULL,
+  `user_id` int(11) NOT NULL,
+  `attempts` int(11) NOT NULL DEFAULT 0,
+  `created_at` datetime NOT NULL,
+  `expires_at` datetime NOT NULL,
+  `used_at` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_mfa_challenges_user` (`user_id`),
+  CONSTRAINT `mfa_challenges_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
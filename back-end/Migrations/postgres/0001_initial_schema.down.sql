@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS user_goals;
+DROP TABLE IF EXISTS user_dietitian;
+DROP TABLE IF EXISTS daily_entries;
+DROP TABLE IF EXISTS consumed_foods;
+DROP TABLE IF EXISTS users;
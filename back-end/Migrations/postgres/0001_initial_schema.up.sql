@@ -0,0 +1,66 @@
+CREATE TABLE IF NOT EXISTS users (
+  id SERIAL PRIMARY KEY,
+  email VARCHAR(100) NOT NULL UNIQUE,
+  username VARCHAR(50) NOT NULL UNIQUE,
+  password_hash VARCHAR(255) NOT NULL,
+  full_name VARCHAR(100) NOT NULL,
+  birthdate DATE NOT NULL,
+  gender VARCHAR(10) NOT NULL CHECK (gender IN ('male','female','other')),
+  height NUMERIC(5,2) NOT NULL,
+  weight NUMERIC(5,2) NOT NULL,
+  goal_type VARCHAR(10) NOT NULL CHECK (goal_type IN ('lose','gain','maintain')),
+  activity_level VARCHAR(20) NOT NULL CHECK (activity_level IN ('sedentary','light','moderate','active','very_active')),
+  daily_calorie_goal INTEGER NOT NULL DEFAULT 2000,
+  role VARCHAR(20) NOT NULL DEFAULT 'user' CHECK (role IN ('admin','dietitian','user')),
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS consumed_foods (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  food_id VARCHAR(50) NOT NULL,
+  food_name VARCHAR(255) NOT NULL,
+  quantity NUMERIC(10,2) NOT NULL,
+  calories NUMERIC(10,2) NOT NULL,
+  protein NUMERIC(10,2) NOT NULL,
+  carbs NUMERIC(10,2) NOT NULL,
+  fats NUMERIC(10,2) NOT NULL,
+  entry_date TIMESTAMP NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_date ON consumed_foods (user_id, entry_date);
+
+-- total_calories is NUMERIC rather than INTEGER because the rollup stores the
+-- unrounded sum, which PostgreSQL refuses to truncate implicitly.
+CREATE TABLE IF NOT EXISTS daily_entries (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  entry_date DATE NOT NULL,
+  total_calories NUMERIC(10,2) NOT NULL,
+  total_protein NUMERIC(7,2) DEFAULT NULL,
+  total_carbs NUMERIC(7,2) DEFAULT NULL,
+  total_fats NUMERIC(7,2) DEFAULT NULL,
+  notes TEXT DEFAULT NULL,
+  UNIQUE (user_id, entry_date)
+);
+
+CREATE TABLE IF NOT EXISTS user_dietitian (
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  dietitian_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  assigned_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (user_id, dietitian_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_dietitian_dietitian ON user_dietitian (dietitian_id);
+
+CREATE TABLE IF NOT EXISTS user_goals (
+  user_id INTEGER NOT NULL PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+  target_calories INTEGER NOT NULL,
+  target_protein NUMERIC(7,2) DEFAULT NULL,
+  target_carbs NUMERIC(7,2) DEFAULT NULL,
+  target_fats NUMERIC(7,2) DEFAULT NULL,
+  target_weight NUMERIC(5,2) DEFAULT NULL
+);
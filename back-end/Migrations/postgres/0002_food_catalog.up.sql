@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS foods (
+  fdc_id INTEGER NOT NULL PRIMARY KEY,
+  description VARCHAR(255) NOT NULL,
+  data_type VARCHAR(30) NOT NULL,
+  brand_owner VARCHAR(255) NOT NULL DEFAULT '',
+  calories NUMERIC(10,2) NOT NULL DEFAULT 0,
+  protein NUMERIC(10,2) NOT NULL DEFAULT 0,
+  carbs NUMERIC(10,2) NOT NULL DEFAULT 0,
+  fats NUMERIC(10,2) NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_foods_data_type ON foods (data_type);
+
+-- Matches the expression searched by postgresDialect.FullTextSearch.
+CREATE INDEX IF NOT EXISTS idx_foods_search ON foods
+  USING GIN (to_tsvector('simple', description || ' ' || brand_owner));
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+  id SERIAL PRIMARY KEY,
+  actor_id INTEGER NOT NULL,
+  actor_role VARCHAR(20) NOT NULL,
+  action VARCHAR(50) NOT NULL,
+  target_user_id INTEGER NOT NULL,
+  entity_id INTEGER DEFAULT NULL,
+  ip_address VARCHAR(45) NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_target ON audit_log (target_user_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_actor ON audit_log (actor_id);
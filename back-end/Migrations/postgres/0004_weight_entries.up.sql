@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS weight_entries (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  weight NUMERIC(5,2) NOT NULL,
+  recorded_at TIMESTAMP NOT NULL,
+  created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_weight_user_recorded ON weight_entries (user_id, recorded_at);
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN body_fat NUMERIC(4,1) DEFAULT NULL;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS jobs (
+  id SERIAL PRIMARY KEY,
+  type VARCHAR(50) NOT NULL,
+  status VARCHAR(20) NOT NULL,
+  params TEXT NOT NULL,
+  total INTEGER NOT NULL DEFAULT 0,
+  processed INTEGER NOT NULL DEFAULT 0,
+  changed INTEGER NOT NULL DEFAULT 0,
+  failed INTEGER NOT NULL DEFAULT 0,
+  last_id INTEGER NOT NULL DEFAULT 0,
+  error_message TEXT DEFAULT NULL,
+  created_by INTEGER NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  started_at TIMESTAMP DEFAULT NULL,
+  finished_at TIMESTAMP DEFAULT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs (status);
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS dietitian_applications (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  credentials VARCHAR(500) NOT NULL,
+  license_number VARCHAR(100) NOT NULL,
+  bio TEXT NOT NULL,
+  status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','approved','rejected')),
+  reviewed_by INTEGER DEFAULT NULL,
+  review_reason TEXT DEFAULT NULL,
+  created_at TIMESTAMP NOT NULL,
+  reviewed_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_dietitian_applications_status ON dietitian_applications (status, id);
+CREATE INDEX IF NOT EXISTS idx_dietitian_applications_user ON dietitian_applications (user_id);
+
+CREATE TABLE IF NOT EXISTS dietitian_profiles (
+  user_id INTEGER NOT NULL PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+  display_name VARCHAR(100) NOT NULL,
+  credentials VARCHAR(500) NOT NULL,
+  license_number VARCHAR(100) NOT NULL,
+  bio TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
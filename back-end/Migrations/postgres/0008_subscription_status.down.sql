@@ -0,0 +1,7 @@
+ALTER TABLE dietitian_profiles DROP COLUMN IF EXISTS client_capacity;
+
+DELETE FROM user_dietitian WHERE status <> 'accepted';
+DROP INDEX IF EXISTS idx_user_dietitian_status;
+ALTER TABLE user_dietitian DROP COLUMN IF EXISTS ended_at;
+ALTER TABLE user_dietitian DROP COLUMN IF EXISTS responded_at;
+ALTER TABLE user_dietitian DROP COLUMN IF EXISTS status;
@@ -0,0 +1,10 @@
+ALTER TABLE user_dietitian ADD COLUMN status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','accepted','declined','ended'));
+ALTER TABLE user_dietitian ADD COLUMN responded_at TIMESTAMP DEFAULT NULL;
+ALTER TABLE user_dietitian ADD COLUMN ended_at TIMESTAMP DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_user_dietitian_status ON user_dietitian (dietitian_id, status);
+
+-- Relationships created before requests had to be accepted stay active.
+UPDATE user_dietitian SET status = 'accepted', responded_at = assigned_at;
+
+ALTER TABLE dietitian_profiles ADD COLUMN client_capacity INTEGER DEFAULT NULL;
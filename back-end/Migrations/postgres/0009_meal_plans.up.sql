@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS meal_plans (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  dietitian_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  name VARCHAR(100) NOT NULL,
+  notes TEXT DEFAULT NULL,
+  days INTEGER NOT NULL,
+  start_date DATE NOT NULL,
+  end_date DATE NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_meal_plans_user_dates ON meal_plans (user_id, start_date, end_date);
+
+CREATE TABLE IF NOT EXISTS meal_plan_items (
+  id SERIAL PRIMARY KEY,
+  plan_id INTEGER NOT NULL REFERENCES meal_plans (id) ON DELETE CASCADE,
+  day_number INTEGER NOT NULL,
+  meal VARCHAR(10) NOT NULL CHECK (meal IN ('breakfast','lunch','dinner','snack')),
+  food_id VARCHAR(50) NOT NULL,
+  food_name VARCHAR(255) NOT NULL,
+  quantity NUMERIC(10,2) NOT NULL,
+  calories NUMERIC(10,2) NOT NULL,
+  protein NUMERIC(10,2) NOT NULL,
+  carbs NUMERIC(10,2) NOT NULL,
+  fats NUMERIC(10,2) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_meal_plan_items_plan ON meal_plan_items (plan_id, day_number);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS comments (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  dietitian_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  author_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  parent_id INTEGER DEFAULT NULL REFERENCES comments (id) ON DELETE CASCADE,
+  entry_id INTEGER DEFAULT NULL REFERENCES consumed_foods (id) ON DELETE CASCADE,
+  entry_date DATE DEFAULT NULL,
+  body TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_comments_user_date ON comments (user_id, entry_date);
+CREATE INDEX IF NOT EXISTS idx_comments_entry ON comments (entry_id);
+CREATE INDEX IF NOT EXISTS idx_comments_parent ON comments (parent_id);
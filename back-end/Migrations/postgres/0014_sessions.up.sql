@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS sessions (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  family_id CHAR(32) NOT NULL UNIQUE,
+  user_agent VARCHAR(255) NOT NULL DEFAULT '',
+  ip_address VARCHAR(45) NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL,
+  last_used_at TIMESTAMP NOT NULL,
+  revoked_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user ON sessions (user_id, revoked_at);
+
+INSERT INTO sessions (user_id, family_id, created_at, last_used_at, revoked_at)
+SELECT user_id, family_id, MIN(created_at), MAX(created_at), MAX(revoked_at)
+FROM refresh_tokens
+GROUP BY user_id, family_id;
@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP DEFAULT NULL;
+
+-- Accounts created before emails had to be verified stay usable.
+UPDATE users SET email_verified_at = created_at;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS user_two_factor (
+  user_id INTEGER PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+  secret_encrypted VARCHAR(255) NOT NULL,
+  enabled_at TIMESTAMP DEFAULT NULL,
+  last_used_step BIGINT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  code_hash CHAR(64) NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  used_at TIMESTAMP DEFAULT NULL,
+  UNIQUE (user_id, code_hash)
+);
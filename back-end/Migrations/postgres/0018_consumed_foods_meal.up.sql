@@ -0,0 +1 @@
+ALTER TABLE consumed_foods ADD COLUMN meal VARCHAR(10) NOT NULL DEFAULT 'snack' CHECK (meal IN ('breakfast','lunch','dinner','snack'));
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS mfa_challenges;
+ALTER TABLE user_two_factor DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE user_two_factor DROP COLUMN IF EXISTS failed_attempts;
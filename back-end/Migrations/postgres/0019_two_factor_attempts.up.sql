@@ -0,0 +1,13 @@
+ALTER TABLE user_two_factor ADD COLUMN failed_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_two_factor ADD COLUMN locked_until TIMESTAMP DEFAULT NULL;
+
+CREATE TABLE IF NOT EXISTS mfa_challenges (
+  id CHAR(32) PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL,
+  expires_at TIMESTAMP NOT NULL,
+  used_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_mfa_challenges_user ON mfa_challenges (user_id);
@@ -0,0 +1,64 @@
+CREATE TABLE IF NOT EXISTS users (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  email VARCHAR(100) NOT NULL UNIQUE,
+  username VARCHAR(50) NOT NULL UNIQUE,
+  password_hash VARCHAR(255) NOT NULL,
+  full_name VARCHAR(100) NOT NULL,
+  birthdate DATE NOT NULL,
+  gender TEXT NOT NULL CHECK (gender IN ('male','female','other')),
+  height DECIMAL(5,2) NOT NULL,
+  weight DECIMAL(5,2) NOT NULL,
+  goal_type TEXT NOT NULL CHECK (goal_type IN ('lose','gain','maintain')),
+  activity_level TEXT NOT NULL CHECK (activity_level IN ('sedentary','light','moderate','active','very_active')),
+  daily_calorie_goal INTEGER NOT NULL DEFAULT 2000,
+  role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('admin','dietitian','user')),
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS consumed_foods (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  food_id VARCHAR(50) NOT NULL,
+  food_name VARCHAR(255) NOT NULL,
+  quantity DECIMAL(10,2) NOT NULL,
+  calories DECIMAL(10,2) NOT NULL,
+  protein DECIMAL(10,2) NOT NULL,
+  carbs DECIMAL(10,2) NOT NULL,
+  fats DECIMAL(10,2) NOT NULL,
+  entry_date DATETIME NOT NULL,
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_date ON consumed_foods (user_id, entry_date);
+
+CREATE TABLE IF NOT EXISTS daily_entries (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  entry_date DATE NOT NULL,
+  total_calories INTEGER NOT NULL,
+  total_protein DECIMAL(5,2) DEFAULT NULL,
+  total_carbs DECIMAL(5,2) DEFAULT NULL,
+  total_fats DECIMAL(5,2) DEFAULT NULL,
+  notes TEXT DEFAULT NULL,
+  UNIQUE (user_id, entry_date)
+);
+
+CREATE TABLE IF NOT EXISTS user_dietitian (
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  dietitian_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  assigned_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (user_id, dietitian_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_dietitian_dietitian ON user_dietitian (dietitian_id);
+
+CREATE TABLE IF NOT EXISTS user_goals (
+  user_id INTEGER NOT NULL PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+  target_calories INTEGER NOT NULL,
+  target_protein DECIMAL(5,2) DEFAULT NULL,
+  target_carbs DECIMAL(5,2) DEFAULT NULL,
+  target_fats DECIMAL(5,2) DEFAULT NULL,
+  target_weight DECIMAL(5,2) DEFAULT NULL
+);
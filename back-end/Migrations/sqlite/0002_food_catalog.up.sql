@@ -0,0 +1,15 @@
+-- SQLite has no FULLTEXT index; searches fall back to LIKE on these columns.
+CREATE TABLE IF NOT EXISTS foods (
+  fdc_id INTEGER NOT NULL PRIMARY KEY,
+  description VARCHAR(255) NOT NULL,
+  data_type VARCHAR(30) NOT NULL,
+  brand_owner VARCHAR(255) NOT NULL DEFAULT '',
+  calories DECIMAL(10,2) NOT NULL DEFAULT 0,
+  protein DECIMAL(10,2) NOT NULL DEFAULT 0,
+  carbs DECIMAL(10,2) NOT NULL DEFAULT 0,
+  fats DECIMAL(10,2) NOT NULL DEFAULT 0,
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_foods_data_type ON foods (data_type);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS weight_entries (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  weight DECIMAL(5,2) NOT NULL,
+  recorded_at DATETIME NOT NULL,
+  created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_weight_user_recorded ON weight_entries (user_id, recorded_at);
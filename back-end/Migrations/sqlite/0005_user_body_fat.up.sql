@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN body_fat DECIMAL(4,1) DEFAULT NULL;
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS meal_plans (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  dietitian_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  name VARCHAR(100) NOT NULL,
+  notes TEXT DEFAULT NULL,
+  days INTEGER NOT NULL,
+  start_date DATE NOT NULL,
+  end_date DATE NOT NULL,
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_meal_plans_user_dates ON meal_plans (user_id, start_date, end_date);
+
+CREATE TABLE IF NOT EXISTS meal_plan_items (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  plan_id INTEGER NOT NULL REFERENCES meal_plans (id) ON DELETE CASCADE,
+  day_number INTEGER NOT NULL,
+  meal TEXT NOT NULL CHECK (meal IN ('breakfast','lunch','dinner','snack')),
+  food_id VARCHAR(50) NOT NULL,
+  food_name VARCHAR(255) NOT NULL,
+  quantity DECIMAL(10,2) NOT NULL,
+  calories DECIMAL(10,2) NOT NULL,
+  protein DECIMAL(10,2) NOT NULL,
+  carbs DECIMAL(10,2) NOT NULL,
+  fats DECIMAL(10,2) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_meal_plan_items_plan ON meal_plan_items (plan_id, day_number);
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS conversations (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  dietitian_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  created_at DATETIME NOT NULL,
+  last_message_at DATETIME DEFAULT NULL,
+  UNIQUE (user_id, dietitian_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversations_dietitian ON conversations (dietitian_id);
+
+CREATE TABLE IF NOT EXISTS messages (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  conversation_id INTEGER NOT NULL REFERENCES conversations (id) ON DELETE CASCADE,
+  sender_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  body TEXT NOT NULL,
+  created_at DATETIME NOT NULL,
+  read_at DATETIME DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_messages_conversation ON messages (conversation_id, id);
+CREATE INDEX IF NOT EXISTS idx_messages_unread ON messages (conversation_id, read_at);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS alerts (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  dietitian_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  rule VARCHAR(50) NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open','acknowledged','resolved')),
+  message VARCHAR(255) NOT NULL,
+  detected_at DATETIME NOT NULL,
+  last_seen_at DATETIME NOT NULL,
+  acknowledged_at DATETIME DEFAULT NULL,
+  snoozed_until DATETIME DEFAULT NULL,
+  resolved_at DATETIME DEFAULT NULL,
+  UNIQUE (dietitian_id, user_id, rule)
+);
+
+CREATE INDEX IF NOT EXISTS idx_alerts_user ON alerts (user_id);
+CREATE INDEX IF NOT EXISTS idx_alerts_status ON alerts (dietitian_id, status);
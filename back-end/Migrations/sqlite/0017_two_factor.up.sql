@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS user_two_factor (
+  user_id INTEGER PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+  secret_encrypted VARCHAR(255) NOT NULL,
+  enabled_at DATETIME DEFAULT NULL,
+  last_used_step INTEGER NOT NULL DEFAULT 0,
+  created_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  code_hash CHAR(64) NOT NULL,
+  created_at DATETIME NOT NULL,
+  used_at DATETIME DEFAULT NULL,
+  UNIQUE (user_id, code_hash)
+);
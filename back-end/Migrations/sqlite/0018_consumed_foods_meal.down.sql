@@ -0,0 +1 @@
+ALTER TABLE consumed_foods DROP COLUMN meal;
@@ -0,0 +1 @@
+ALTER TABLE consumed_foods ADD COLUMN meal TEXT NOT NULL DEFAULT 'snack' CHECK (meal IN ('breakfast','lunch','dinner','snack'));
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS mfa_challenges;
+ALTER TABLE user_two_factor DROP COLUMN locked_until;
+ALTER TABLE user_two_factor DROP COLUMN failed_attempts;
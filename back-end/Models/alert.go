@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+)
+
+// Alert flags a client of a dietitian whose log breaks one of the alert
+// rules. There is one alert per dietitian, client and rule: it is resolved
+// when the rule no longer applies and opened again when it does.
+type Alert struct {
+	ID          int    `db:"id" json:"id"`
+	DietitianID int    `db:"dietitian_id" json:"dietitianId"`
+	UserID      int    `db:"user_id" json:"userId"`
+	Rule        string `db:"rule" json:"rule"`
+	Status      string `db:"status" json:"status"`
+	Message     string `db:"message" json:"message"`
+	// DetectedAt is when the rule started to apply, LastSeenAt the latest
+	// evaluation that found it still applying.
+	DetectedAt     time.Time  `db:"detected_at" json:"detectedAt"`
+	LastSeenAt     time.Time  `db:"last_seen_at" json:"lastSeenAt"`
+	AcknowledgedAt *time.Time `db:"acknowledged_at" json:"acknowledgedAt,omitempty"`
+	SnoozedUntil   *time.Time `db:"snoozed_until" json:"snoozedUntil,omitempty"`
+	ResolvedAt     *time.Time `db:"resolved_at" json:"resolvedAt,omitempty"`
+
+	// The client's name, filled when listing alerts.
+	FullName string `db:"full_name" json:"fullName,omitempty"`
+}
+
+const (
+	AlertOpen         = "open"
+	AlertAcknowledged = "acknowledged"
+	AlertResolved     = "resolved"
+
+	// AlertSnoozed selects open alerts that are snoozed when listing; it is
+	// not stored.
+	AlertSnoozed = "snoozed"
+)
+
+// Alert rules
+const (
+	AlertCaloriesOver  = "calories_over"
+	AlertCaloriesUnder = "calories_under"
+	AlertNotLogging    = "not_logging"
+	AlertLowProtein    = "low_protein"
+)
+
+// Snoozed reports whether the alert is hidden from the open alerts at now.
+func (a *Alert) Snoozed(now time.Time) bool {
+	return a.Status == AlertOpen && a.SnoozedUntil != nil && a.SnoozedUntil.After(now)
+}
+
+// AlertFinding is a rule that applies to a client at evaluation time.
+type AlertFinding struct {
+	Rule    string
+	Message string
+}
+
+// DailyTotals is a daily_entries row: what a user logged on one date.
+type DailyTotals struct {
+	UserID   int     `db:"user_id" json:"userId"`
+	Date     string  `db:"day" json:"date"`
+	Calories float64 `db:"total_calories" json:"calories"`
+	Protein  float64 `db:"total_protein" json:"protein"`
+	Carbs    float64 `db:"total_carbs" json:"carbs"`
+	Fats     float64 `db:"total_fats" json:"fats"`
+}
+
+// SnoozeRequest hides an alert for the given number of hours.
+type SnoozeRequest struct {
+	Hours int `json:"hours" binding:"required,min=1,max=720"`
+}
+
+// EvaluateAlertsParams are the options of an alert evaluation job. AsOf is
+// the last day, YYYY-MM-DD, the rules look at; it defaults to yesterday, the
+// last complete day.
+type EvaluateAlertsParams struct {
+	AsOf string `json:"asOf,omitempty" binding:"omitempty,datetime=2006-01-02"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// AuditEntry records a privileged action taken on another user's data, such
+// as an admin or dietitian editing a client's food log.
+type AuditEntry struct {
+	ID           int       `db:"id" json:"id"`
+	ActorID      int       `db:"actor_id" json:"actorId"`
+	ActorRole    string    `db:"actor_role" json:"actorRole"`
+	Action       string    `db:"action" json:"action"`
+	TargetUserID int       `db:"target_user_id" json:"targetUserId"`
+	EntityID     *int      `db:"entity_id" json:"entityId,omitempty"`
+	IPAddress    string    `db:"ip_address" json:"ipAddress"`
+	CreatedAt    time.Time `db:"created_at" json:"createdAt"`
+}
+
+const (
+	AuditFoodEntryRead   = "food_entry.read"
+	AuditFoodEntryUpdate = "food_entry.update"
+	AuditFoodEntryDelete = "food_entry.delete"
+
+	AuditApplicationApprove = "dietitian_application.approve"
+	AuditApplicationReject  = "dietitian_application.reject"
+
+	AuditSessionRead      = "session.read"
+	AuditSessionRevoke    = "session.revoke"
+	AuditSessionRevokeAll = "session.revoke_all"
+)
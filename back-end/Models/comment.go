@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+)
+
+// Comment is a dietitian's note on a client's food entry or on a whole day,
+// or a reply in its thread. Threads are one level deep: replies point at the
+// comment that started the thread and share its entry and day.
+type Comment struct {
+	ID          int  `db:"id" json:"id"`
+	UserID      int  `db:"user_id" json:"userId"`
+	DietitianID int  `db:"dietitian_id" json:"dietitianId"`
+	AuthorID    int  `db:"author_id" json:"authorId"`
+	ParentID    *int `db:"parent_id" json:"parentId,omitempty"`
+	EntryID     *int `db:"entry_id" json:"entryId,omitempty"`
+	// Date is the YYYY-MM-DD day the thread is about. For an entry it is the
+	// entry's current date.
+	Date      string    `db:"day" json:"date"`
+	Body      string    `db:"body" json:"body"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+
+	AuthorName string `db:"author_name" json:"authorName"`
+	AuthorRole string `db:"author_role" json:"authorRole"`
+
+	Replies []Comment `db:"-" json:"replies,omitempty"`
+}
+
+// CommentThreads groups comments, oldest first, into threads with their
+// replies. Replies whose thread is missing are dropped.
+func CommentThreads(comments []Comment) []Comment {
+	threads := []Comment{}
+	index := make(map[int]int)
+	for _, comment := range comments {
+		if comment.ParentID == nil {
+			index[comment.ID] = len(threads)
+			threads = append(threads, comment)
+		}
+	}
+	for _, comment := range comments {
+		if comment.ParentID == nil {
+			continue
+		}
+		if i, ok := index[*comment.ParentID]; ok {
+			threads[i].Replies = append(threads[i].Replies, comment)
+		}
+	}
+	return threads
+}
+
+// GroupComments splits threads into those about whole days, keyed by date, and
+// those about entries, keyed by entry ID.
+func GroupComments(threads []Comment) (map[string][]Comment, map[int][]Comment) {
+	byDay := make(map[string][]Comment)
+	byEntry := make(map[int][]Comment)
+	for _, thread := range threads {
+		if thread.EntryID != nil {
+			byEntry[*thread.EntryID] = append(byEntry[*thread.EntryID], thread)
+		} else {
+			byDay[thread.Date] = append(byDay[thread.Date], thread)
+		}
+	}
+	return byDay, byEntry
+}
+
+// CommentRequest starts a thread on an entry or, when EntryID is omitted, on
+// the day Date, given as YYYY-MM-DD.
+type CommentRequest struct {
+	EntryID *int   `json:"entryId" binding:"omitempty,min=1"`
+	Date    string `json:"date" binding:"omitempty,datetime=2006-01-02"`
+	Body    string `json:"body" binding:"required,max=2000"`
+}
+
+type ReplyRequest struct {
+	Body string `json:"body" binding:"required,max=2000"`
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// DashboardRow summarises one client of a dietitian's caseload.
+type DashboardRow struct {
+	UserID   int    `json:"userId"`
+	FullName string `json:"fullName"`
+	Email    string `json:"email"`
+	// LastLogDate is the latest day with entries, YYYY-MM-DD, or nil if the
+	// client never logged.
+	LastLogDate *string `json:"lastLogDate"`
+	// Adherence7 and Adherence30 cover the 7 and 30 complete days before
+	// today.
+	Adherence7  NutritionAdherence `json:"adherence7d"`
+	Adherence30 NutritionAdherence `json:"adherence30d"`
+	Weight      float64            `json:"weight"`
+	// WeightChange is the difference between the first and the last weigh-in
+	// of the last 30 days, nil with fewer than two.
+	WeightChange *float64 `json:"weightChange"`
+	OpenAlerts   int      `json:"openAlerts"`
+	AlertRules   []string `json:"alertRules"`
+}
+
+// NutritionAdherence scores the logged days of a period against the goals,
+// from 0 to 1 per nutrient: a day on target scores 1, a day 30% over or
+// under scores 0.7. The scores are nil without goals or logged days.
+type NutritionAdherence struct {
+	LoggedDays int      `json:"loggedDays"`
+	Days       int      `json:"days"`
+	Calories   *float64 `json:"calories"`
+	Protein    *float64 `json:"protein"`
+	Carbs      *float64 `json:"carbs"`
+	Fats       *float64 `json:"fats"`
+}
+
+// Adherence scores the totals keyed by YYYY-MM-DD of the days from from to
+// to against goals, which may be nil.
+func Adherence(goals *UserGoals, days map[string]DailyTotals, from, to time.Time) NutritionAdherence {
+	var result NutritionAdherence
+	var calories, protein, carbs, fats float64
+	for date := DateOnly(from); !date.After(DateOnly(to)); date = date.AddDate(0, 0, 1) {
+		result.Days++
+		totals, ok := days[date.Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		result.LoggedDays++
+		if goals != nil {
+			calories += dayScore(totals.Calories, float64(goals.TargetCalories))
+			protein += dayScore(totals.Protein, goals.TargetProtein)
+			carbs += dayScore(totals.Carbs, goals.TargetCarbs)
+			fats += dayScore(totals.Fats, goals.TargetFats)
+		}
+	}
+	if goals == nil || result.LoggedDays == 0 {
+		return result
+	}
+
+	result.Calories = averageScore(calories, result.LoggedDays, goals.TargetCalories > 0)
+	result.Protein = averageScore(protein, result.LoggedDays, goals.TargetProtein > 0)
+	result.Carbs = averageScore(carbs, result.LoggedDays, goals.TargetCarbs > 0)
+	result.Fats = averageScore(fats, result.LoggedDays, goals.TargetFats > 0)
+	return result
+}
+
+func dayScore(actual, target float64) float64 {
+	if target <= 0 {
+		return 0
+	}
+	return math.Max(0, 1-math.Abs(actual-target)/target)
+}
+
+func averageScore(total float64, days int, hasTarget bool) *float64 {
+	if !hasTarget {
+		return nil
+	}
+	average := math.Round(total/float64(days)*1000) / 1000
+	return &average
+}
@@ -4,13 +4,28 @@ import (
 	"time"
 )
 
+// DietitianSubscription is a row of user_dietitian. A client requests a
+// dietitian, who accepts or declines; either side of an accepted relationship
+// can later end it. Only accepted subscriptions give access to the client.
 type DietitianSubscription struct {
-	ID          int       `db:"id" json:"id"`
-	UserID      int       `db:"user_id" json:"userId"`
-	DietitianID int       `db:"dietitian_id" json:"dietitianId"`
-	CreatedAt   time.Time `db:"created_at" json:"createdAt"`
+	UserID      int        `db:"user_id" json:"userId"`
+	DietitianID int        `db:"dietitian_id" json:"dietitianId"`
+	Status      string     `db:"status" json:"status"`
+	RequestedAt time.Time  `db:"assigned_at" json:"requestedAt"`
+	RespondedAt *time.Time `db:"responded_at" json:"respondedAt,omitempty"`
+	EndedAt     *time.Time `db:"ended_at" json:"endedAt,omitempty"`
+
+	// The other party's name, filled when listing subscriptions.
+	FullName string `db:"full_name" json:"fullName,omitempty"`
 }
 
+const (
+	SubscriptionPending  = "pending"
+	SubscriptionAccepted = "accepted"
+	SubscriptionDeclined = "declined"
+	SubscriptionEnded    = "ended"
+)
+
 type UserProgress struct {
 	NutritionHistory struct {
 		Dates    []string  `json:"dates"`
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+)
+
+// DietitianApplication is a user's request to become a dietitian. Approving
+// it switches the user's role and publishes a DietitianProfile.
+type DietitianApplication struct {
+	ID            int        `db:"id" json:"id"`
+	UserID        int        `db:"user_id" json:"userId"`
+	Credentials   string     `db:"credentials" json:"credentials"`
+	LicenseNumber string     `db:"license_number" json:"licenseNumber"`
+	Bio           string     `db:"bio" json:"bio"`
+	Status        string     `db:"status" json:"status"`
+	ReviewedBy    *int       `db:"reviewed_by" json:"reviewedBy,omitempty"`
+	ReviewReason  *string    `db:"review_reason" json:"reviewReason,omitempty"`
+	CreatedAt     time.Time  `db:"created_at" json:"createdAt"`
+	ReviewedAt    *time.Time `db:"reviewed_at" json:"reviewedAt,omitempty"`
+
+	// Filled from the applicant's account when listing applications.
+	ApplicantName  string `db:"applicant_name" json:"applicantName,omitempty"`
+	ApplicantEmail string `db:"applicant_email" json:"applicantEmail,omitempty"`
+}
+
+const (
+	ApplicationPending  = "pending"
+	ApplicationApproved = "approved"
+	ApplicationRejected = "rejected"
+)
+
+type DietitianApplicationRequest struct {
+	Credentials   string `json:"credentials" binding:"required,max=500"`
+	LicenseNumber string `json:"licenseNumber" binding:"required,max=100"`
+	Bio           string `json:"bio" binding:"required,max=5000"`
+}
+
+// ApplicationReviewRequest is the body of an approval or rejection. A reason
+// is required to reject.
+type ApplicationReviewRequest struct {
+	Reason string `json:"reason" binding:"max=1000"`
+}
+
+// DietitianProfile is the public page of a dietitian.
+type DietitianProfile struct {
+	UserID        int    `db:"user_id" json:"userId"`
+	DisplayName   string `db:"display_name" json:"displayName"`
+	Credentials   string `db:"credentials" json:"credentials"`
+	LicenseNumber string `db:"license_number" json:"licenseNumber"`
+	Bio           string `db:"bio" json:"bio"`
+	// ClientCapacity caps the accepted clients; nil means no limit.
+	ClientCapacity *int      `db:"client_capacity" json:"clientCapacity"`
+	CreatedAt      time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// DietitianProfileRequest replaces the editable fields of a profile, so
+// leaving out clientCapacity removes the limit.
+type DietitianProfileRequest struct {
+	DisplayName    string `json:"displayName" binding:"required,max=100"`
+	Credentials    string `json:"credentials" binding:"required,max=500"`
+	Bio            string `json:"bio" binding:"required,max=5000"`
+	ClientCapacity *int   `json:"clientCapacity" binding:"omitempty,min=1,max=1000"`
+}
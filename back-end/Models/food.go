@@ -0,0 +1,82 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Food is a catalog item imported from USDA FoodData Central. Macro values
+// are stored per 100 g, which is how FDC reports them.
+type Food struct {
+	FdcID       int       `db:"fdc_id" json:"fdcId"`
+	Description string    `db:"description" json:"description"`
+	DataType    string    `db:"data_type" json:"dataType"`
+	BrandOwner  string    `db:"brand_owner" json:"brandOwner,omitempty"`
+	Calories    float64   `db:"calories" json:"calories"`
+	Protein     float64   `db:"protein" json:"protein"`
+	Carbs       float64   `db:"carbs" json:"carbs"`
+	Fat         float64   `db:"fats" json:"fat"`
+	CreatedAt   time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+const (
+	FoodTypeFoundation = "Foundation"
+	FoodTypeSRLegacy   = "SR Legacy"
+	FoodTypeSurvey     = "Survey (FNDDS)"
+	FoodTypeBranded    = "Branded"
+)
+
+// FoodSearchPage is one page of ranked catalog search results. Only the best
+// full-text candidates are ranked, so when Capped is set TotalHits counts the
+// matches among them rather than in the whole catalog.
+type FoodSearchPage struct {
+	Foods     []*Food
+	TotalHits int
+	Capped    bool
+}
+
+// NutritionFor scales the per-100 g values of the food to the given amount
+// in grams.
+func (f *Food) NutritionFor(grams float64) (calories, protein, carbs, fat float64) {
+	factor := grams / 100
+	return round2(f.Calories * factor), round2(f.Protein * factor), round2(f.Carbs * factor), round2(f.Fat * factor)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// FDCFoodNutrient mirrors a nutrient entry of the FDC search API so existing
+// front-end code can read catalog results unchanged.
+type FDCFoodNutrient struct {
+	NutrientID     int     `json:"nutrientId"`
+	NutrientName   string  `json:"nutrientName"`
+	NutrientNumber string  `json:"nutrientNumber"`
+	UnitName       string  `json:"unitName"`
+	Value          float64 `json:"value"`
+}
+
+// FDCSearchFood mirrors a food of the FDC search API.
+type FDCSearchFood struct {
+	FdcID         int               `json:"fdcId"`
+	Description   string            `json:"description"`
+	DataType      string            `json:"dataType"`
+	BrandOwner    string            `json:"brandOwner,omitempty"`
+	FoodNutrients []FDCFoodNutrient `json:"foodNutrients"`
+}
+
+func (f *Food) ToSearchFood() *FDCSearchFood {
+	return &FDCSearchFood{
+		FdcID:       f.FdcID,
+		Description: f.Description,
+		DataType:    f.DataType,
+		BrandOwner:  f.BrandOwner,
+		FoodNutrients: []FDCFoodNutrient{
+			{NutrientID: 1008, NutrientName: "Energy", NutrientNumber: "208", UnitName: "KCAL", Value: f.Calories},
+			{NutrientID: 1003, NutrientName: "Protein", NutrientNumber: "203", UnitName: "G", Value: f.Protein},
+			{NutrientID: 1004, NutrientName: "Total lipid (fat)", NutrientNumber: "204", UnitName: "G", Value: f.Fat},
+			{NutrientID: 1005, NutrientName: "Carbohydrate, by difference", NutrientNumber: "205", UnitName: "G", Value: f.Carbs},
+		},
+	}
+}
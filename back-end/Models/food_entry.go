@@ -14,11 +14,26 @@ type FoodEntry struct {
 	Protein   float64   `db:"protein" json:"protein"`
 	Carbs     float64   `db:"carbs" json:"carbs"`
 	Fat       float64   `db:"fats" json:"fat"`
+	Meal      string    `db:"meal" json:"meal"`
 	Date      time.Time `db:"entry_date" json:"date"`
 	CreatedAt time.Time `db:"created_at" json:"createdAt"`
 	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
 }
 
+// Rescale changes the amount of the entry and scales its stored macros by the
+// same factor. It is the fallback for entries whose food is not in the
+// catalog; the scaled values carry the rounding of the stored ones.
+func (e *FoodEntry) Rescale(amount float64) {
+	if e.Amount > 0 {
+		factor := amount / e.Amount
+		e.Calories = round2(e.Calories * factor)
+		e.Protein = round2(e.Protein * factor)
+		e.Carbs = round2(e.Carbs * factor)
+		e.Fat = round2(e.Fat * factor)
+	}
+	e.Amount = amount
+}
+
 const (
 	MealBreakfast = "breakfast"
 	MealLunch     = "lunch"
@@ -26,21 +41,52 @@ const (
 	MealSnack     = "snack"
 )
 
+// MealTypes lists the meal slots in the order they are shown during a day.
+var MealTypes = []string{MealBreakfast, MealLunch, MealDinner, MealSnack}
+
+func IsValidMeal(meal string) bool {
+	for _, m := range MealTypes {
+		if m == meal {
+			return true
+		}
+	}
+	return false
+}
+
 type DailyNutrition struct {
-	Date          time.Time `json:"date"`
-	TotalCalories float64   `json:"total_calories"`
-	TotalProtein  float64   `json:"total_protein"`
-	TotalCarbs    float64   `json:"total_carbs"`
-	TotalFats     float64   `json:"total_fats"`
+	Date          time.Time        `json:"date"`
+	TotalCalories float64          `json:"total_calories"`
+	TotalProtein  float64          `json:"total_protein"`
+	TotalCarbs    float64          `json:"total_carbs"`
+	TotalFats     float64          `json:"total_fats"`
+	Meals         []*MealNutrition `json:"meals,omitempty"`
 }
 
+// MealNutrition is the subtotal of one meal slot within a day.
+type MealNutrition struct {
+	Meal          string  `json:"meal"`
+	TotalCalories float64 `json:"total_calories"`
+	TotalProtein  float64 `json:"total_protein"`
+	TotalCarbs    float64 `json:"total_carbs"`
+	TotalFats     float64 `json:"total_fats"`
+}
+
+// FoodEntryRequest identifies a catalog food and the amount eaten in grams.
+// Name and macros are looked up and calculated on the server. Meal defaults
+// to MealSnack when omitted.
 type FoodEntryRequest struct {
-	FoodID   string    `json:"foodId" binding:"required"`
-	Name     string    `json:"name" binding:"required"`
-	Amount   float64   `json:"amount" binding:"required,gt=0"`
-	Date     time.Time `json:"date" binding:"required"`
-	Calories float64   `json:"calories" binding:"required,gte=0"`
-	Protein  float64   `json:"protein" binding:"required,gte=0"`
-	Carbs    float64   `json:"carbs" binding:"required,gte=0"`
-	Fat      float64   `json:"fat" binding:"required,gte=0"`
+	FoodID string    `json:"foodId" binding:"required"`
+	Amount float64   `json:"amount" binding:"required,gt=0"`
+	Meal   string    `json:"meal"`
+	Date   time.Time `json:"date" binding:"required"`
+}
+
+// FoodEntryUpdateRequest holds the fields of an entry that can be edited.
+// Omitted fields keep their current value. Changing the amount rescales the
+// macros; changing the food recalculates them from the catalog.
+type FoodEntryUpdateRequest struct {
+	FoodID *string    `json:"foodId"`
+	Amount *float64   `json:"amount" binding:"omitempty,gt=0"`
+	Meal   *string    `json:"meal"`
+	Date   *time.Time `json:"date"`
 }
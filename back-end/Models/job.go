@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+// Job is a long-running task executed in the background. Progress is written
+// after every batch, so a job interrupted by a restart continues after LastID.
+type Job struct {
+	ID           int            `db:"id" json:"id"`
+	Type         string         `db:"type" json:"type"`
+	Status       string         `db:"status" json:"status"`
+	Params       types.JSONText `db:"params" json:"params"`
+	Total        int            `db:"total" json:"total"`
+	Processed    int            `db:"processed" json:"processed"`
+	Changed      int            `db:"changed" json:"changed"`
+	Failed       int            `db:"failed" json:"failed"`
+	LastID       int            `db:"last_id" json:"lastId"`
+	ErrorMessage *string        `db:"error_message" json:"error,omitempty"`
+	CreatedBy    int            `db:"created_by" json:"createdBy"`
+	CreatedAt    time.Time      `db:"created_at" json:"createdAt"`
+	StartedAt    *time.Time     `db:"started_at" json:"startedAt"`
+	FinishedAt   *time.Time     `db:"finished_at" json:"finishedAt"`
+	UpdatedAt    time.Time      `db:"updated_at" json:"updatedAt"`
+}
+
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+const (
+	JobRecalculateGoals = "recalculate_goals"
+	JobEvaluateAlerts   = "evaluate_alerts"
+)
+
+// Finished reports whether the job has stopped for good.
+func (j *Job) Finished() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed
+}
+
+// RecalculateGoalsParams are the options of a goal recalculation job. A dry
+// run only counts the users whose targets would change.
+type RecalculateGoalsParams struct {
+	DryRun bool   `json:"dryRun"`
+	Role   string `json:"role,omitempty" binding:"omitempty,oneof=user admin dietitian"`
+}
@@ -0,0 +1,221 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// MealPlan is a dietitian's plan for a client. Its items are numbered by day
+// of the plan, 1 to Days, and the days repeat from StartDate until EndDate,
+// so a one-week plan can be assigned for a month.
+type MealPlan struct {
+	ID          int       `db:"id" json:"id"`
+	UserID      int       `db:"user_id" json:"userId"`
+	DietitianID int       `db:"dietitian_id" json:"dietitianId"`
+	Name        string    `db:"name" json:"name"`
+	Notes       *string   `db:"notes" json:"notes,omitempty"`
+	Days        int       `db:"days" json:"days"`
+	StartDate   time.Time `db:"start_date" json:"startDate"`
+	EndDate     time.Time `db:"end_date" json:"endDate"`
+	CreatedAt   time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updatedAt"`
+
+	Items []MealPlanItem `db:"-" json:"items,omitempty"`
+}
+
+// MealPlanItem is a food planned for one meal slot of one day. Name and
+// macros are copied from the catalog when the plan is saved.
+type MealPlanItem struct {
+	ID       int     `db:"id" json:"id"`
+	PlanID   int     `db:"plan_id" json:"planId"`
+	Day      int     `db:"day_number" json:"day"`
+	Meal     string  `db:"meal" json:"meal"`
+	FoodID   string  `db:"food_id" json:"foodId"`
+	Name     string  `db:"food_name" json:"name"`
+	Amount   float64 `db:"quantity" json:"amount"`
+	Calories float64 `db:"calories" json:"calories"`
+	Protein  float64 `db:"protein" json:"protein"`
+	Carbs    float64 `db:"carbs" json:"carbs"`
+	Fat      float64 `db:"fats" json:"fat"`
+}
+
+// DayOf returns the plan day that applies on date, and false when date is
+// outside the plan.
+func (p *MealPlan) DayOf(date time.Time) (int, bool) {
+	date, start, end := DateOnly(date), DateOnly(p.StartDate), DateOnly(p.EndDate)
+	if date.Before(start) || date.After(end) || p.Days < 1 {
+		return 0, false
+	}
+	elapsed := int(date.Sub(start).Hours() / 24)
+	return elapsed%p.Days + 1, true
+}
+
+// ItemsFor returns the items planned for date, in plan order.
+func (p *MealPlan) ItemsFor(date time.Time) []MealPlanItem {
+	day, ok := p.DayOf(date)
+	if !ok {
+		return nil
+	}
+
+	var items []MealPlanItem
+	for _, item := range p.Items {
+		if item.Day == day {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Overlaps reports whether the two plans share at least one date.
+func (p *MealPlan) Overlaps(other *MealPlan) bool {
+	return !DateOnly(p.StartDate).After(DateOnly(other.EndDate)) &&
+		!DateOnly(other.StartDate).After(DateOnly(p.EndDate))
+}
+
+// DateOnly drops the time of day and location of t, keeping its calendar
+// date.
+func DateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// MatchPlannedItems pairs planned items with the entries logged on the same
+// day. An item counts as logged when an entry of the same food was logged
+// for the same meal; every entry matches at most one item. The result holds
+// the matched entry for each item, or nil.
+func MatchPlannedItems(items []MealPlanItem, entries []*FoodEntry) []*FoodEntry {
+	matched := make([]*FoodEntry, len(items))
+	used := make([]bool, len(entries))
+	for i, item := range items {
+		for j, entry := range entries {
+			if !used[j] && entry.FoodID == item.FoodID && entry.Meal == item.Meal {
+				matched[i] = entry
+				used[j] = true
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// PlannedItem is a MealPlanItem of a given day, with the entry it was logged
+// as, if any.
+type PlannedItem struct {
+	MealPlanItem
+	Logged      bool `json:"logged"`
+	FoodEntryID *int `json:"foodEntryId,omitempty"`
+}
+
+// PlannedMeal groups the planned items of one meal slot.
+type PlannedMeal struct {
+	Meal  string        `json:"meal"`
+	Items []PlannedItem `json:"items"`
+}
+
+// DayPlan is what a client should eat on one date.
+type DayPlan struct {
+	Date  string        `json:"date"`
+	Day   int           `json:"day"`
+	Plan  *MealPlan     `json:"plan"`
+	Meals []PlannedMeal `json:"meals"`
+}
+
+// DayAdherence compares one day of a plan with what was logged. Consumed
+// calories count every entry of the day, planned or not.
+type DayAdherence struct {
+	Date             string  `json:"date"`
+	PlannedItems     int     `json:"plannedItems"`
+	LoggedItems      int     `json:"loggedItems"`
+	PlannedCalories  float64 `json:"plannedCalories"`
+	ConsumedCalories float64 `json:"consumedCalories"`
+	// Adherence is the share of planned items logged, from 0 to 1.
+	Adherence float64 `json:"adherence"`
+}
+
+// MealPlanAdherence summarises DayAdherence over a date range.
+type MealPlanAdherence struct {
+	PlanID           int            `json:"planId"`
+	From             string         `json:"from"`
+	To               string         `json:"to"`
+	PlannedItems     int            `json:"plannedItems"`
+	LoggedItems      int            `json:"loggedItems"`
+	PlannedCalories  float64        `json:"plannedCalories"`
+	ConsumedCalories float64        `json:"consumedCalories"`
+	Adherence        float64        `json:"adherence"`
+	Days             []DayAdherence `json:"days"`
+}
+
+// Adherence compares the plan with entries, keyed by YYYY-MM-DD, for every
+// date from from to to that falls within the plan.
+func (p *MealPlan) Adherence(entries map[string][]*FoodEntry, from, to time.Time) *MealPlanAdherence {
+	result := &MealPlanAdherence{
+		PlanID: p.ID,
+		From:   from.Format("2006-01-02"),
+		To:     to.Format("2006-01-02"),
+		Days:   []DayAdherence{},
+	}
+
+	for date := DateOnly(from); !date.After(DateOnly(to)); date = date.AddDate(0, 0, 1) {
+		if _, ok := p.DayOf(date); !ok {
+			continue
+		}
+		items := p.ItemsFor(date)
+
+		key := date.Format("2006-01-02")
+		day := DayAdherence{Date: key, PlannedItems: len(items)}
+		for i, entry := range MatchPlannedItems(items, entries[key]) {
+			day.PlannedCalories += items[i].Calories
+			if entry != nil {
+				day.LoggedItems++
+			}
+		}
+		for _, entry := range entries[key] {
+			day.ConsumedCalories += entry.Calories
+		}
+		day.PlannedCalories = round2(day.PlannedCalories)
+		day.ConsumedCalories = round2(day.ConsumedCalories)
+		day.Adherence = share(day.LoggedItems, day.PlannedItems)
+
+		result.PlannedItems += day.PlannedItems
+		result.LoggedItems += day.LoggedItems
+		result.PlannedCalories += day.PlannedCalories
+		result.ConsumedCalories += day.ConsumedCalories
+		result.Days = append(result.Days, day)
+	}
+
+	result.PlannedCalories = round2(result.PlannedCalories)
+	result.ConsumedCalories = round2(result.ConsumedCalories)
+	result.Adherence = share(result.LoggedItems, result.PlannedItems)
+	return result
+}
+
+func share(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*1000) / 1000
+}
+
+// MealPlanRequest creates or replaces a plan. Dates are YYYY-MM-DD and both
+// are included.
+type MealPlanRequest struct {
+	Name      string                `json:"name" binding:"required,max=100"`
+	Notes     *string               `json:"notes" binding:"omitempty,max=2000"`
+	Days      int                   `json:"days" binding:"required,min=1,max=28"`
+	StartDate string                `json:"startDate" binding:"required,datetime=2006-01-02"`
+	EndDate   string                `json:"endDate" binding:"required,datetime=2006-01-02"`
+	Items     []MealPlanItemRequest `json:"items" binding:"required,min=1,max=500,dive"`
+}
+
+type MealPlanItemRequest struct {
+	Day    int     `json:"day" binding:"required,min=1"`
+	Meal   string  `json:"meal" binding:"required"`
+	FoodID string  `json:"foodId" binding:"required"`
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// LogPlanItemRequest logs a planned item. Date is YYYY-MM-DD and defaults to
+// today, amount defaults to the planned amount.
+type LogPlanItemRequest struct {
+	Date   string   `json:"date" binding:"omitempty,datetime=2006-01-02"`
+	Amount *float64 `json:"amount" binding:"omitempty,gt=0"`
+}
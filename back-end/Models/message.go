@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+)
+
+// Conversation is the message thread between a client and one of their
+// dietitians. There is at most one per pair.
+type Conversation struct {
+	ID            int        `db:"id" json:"id"`
+	UserID        int        `db:"user_id" json:"userId"`
+	DietitianID   int        `db:"dietitian_id" json:"dietitianId"`
+	CreatedAt     time.Time  `db:"created_at" json:"createdAt"`
+	LastMessageAt *time.Time `db:"last_message_at" json:"lastMessageAt,omitempty"`
+}
+
+// HasParticipant reports whether userID is the client or the dietitian of
+// the conversation.
+func (c *Conversation) HasParticipant(userID int) bool {
+	return c.UserID == userID || c.DietitianID == userID
+}
+
+// OtherParticipant returns the ID of the participant who is not userID.
+func (c *Conversation) OtherParticipant(userID int) int {
+	if c.UserID == userID {
+		return c.DietitianID
+	}
+	return c.UserID
+}
+
+// ConversationSummary is a conversation as listed in the inbox of one
+// participant. UnreadCount counts the messages the other participant sent
+// that this one has not read.
+type ConversationSummary struct {
+	Conversation
+	UserName      string  `db:"user_name" json:"userName"`
+	DietitianName string  `db:"dietitian_name" json:"dietitianName"`
+	LastMessage   *string `db:"last_message" json:"lastMessage,omitempty"`
+	UnreadCount   int     `db:"unread_count" json:"unreadCount"`
+}
+
+// Message is one message of a conversation. ReadAt is set once the recipient
+// has read it.
+type Message struct {
+	ID             int        `db:"id" json:"id"`
+	ConversationID int        `db:"conversation_id" json:"conversationId"`
+	SenderID       int        `db:"sender_id" json:"senderId"`
+	Body           string     `db:"body" json:"body"`
+	CreatedAt      time.Time  `db:"created_at" json:"createdAt"`
+	ReadAt         *time.Time `db:"read_at" json:"readAt,omitempty"`
+}
+
+// MessagePage is a page of a conversation's history, newest first.
+// NextCursor is passed as ?before= to fetch the older messages, and is nil on
+// the last page.
+type MessagePage struct {
+	Messages   []Message `json:"messages"`
+	NextCursor *int      `json:"nextCursor"`
+}
+
+// ReadReceipt reports that ReaderID read the messages of a conversation up to
+// and including UpTo.
+type ReadReceipt struct {
+	ConversationID int       `json:"conversationId"`
+	ReaderID       int       `json:"readerId"`
+	UpTo           int       `json:"upTo"`
+	ReadAt         time.Time `json:"readAt"`
+	Count          int       `json:"count"`
+}
+
+// ConversationRequest opens the conversation with ParticipantID: a dietitian
+// for a client, a client for a dietitian.
+type ConversationRequest struct {
+	ParticipantID int `json:"participantId" binding:"required"`
+}
+
+type MessageRequest struct {
+	Body string `json:"body" binding:"required,max=5000"`
+}
+
+// MarkReadRequest marks the received messages up to UpTo as read, or all of
+// them when UpTo is omitted.
+type MarkReadRequest struct {
+	UpTo *int `json:"upTo" binding:"omitempty,min=1"`
+}
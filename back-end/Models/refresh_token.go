@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+)
+
+// RefreshTokenLifetime is how long a refresh token can be used. Every use
+// replaces it with a new one, so an active session does not expire.
+const RefreshTokenLifetime = 7 * 24 * time.Hour
+
+// RefreshToken is a stored refresh token. The tokens issued by one login form
+// a family: using a token rotates it, and using a rotated token again, which
+// only happens when it was stolen, revokes the whole family.
+type RefreshToken struct {
+	ID        int        `db:"id" json:"-"`
+	UserID    int        `db:"user_id" json:"-"`
+	FamilyID  string     `db:"family_id" json:"-"`
+	TokenHash string     `db:"token_hash" json:"-"`
+	CreatedAt time.Time  `db:"created_at" json:"-"`
+	ExpiresAt time.Time  `db:"expires_at" json:"-"`
+	RotatedAt *time.Time `db:"rotated_at" json:"-"`
+	RevokedAt *time.Time `db:"revoked_at" json:"-"`
+}
+
+// NewRefreshToken returns a token for userID and its stored form. An empty
+// familyID starts a new family.
+func NewRefreshToken(userID int, familyID string, now time.Time) (string, *RefreshToken, error) {
+	if familyID == "" {
+		var err error
+		if familyID, err = generateID(); err != nil {
+			return "", nil, err
+		}
+	}
+	token, err := GenerateToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, &RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: HashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(RefreshTokenLifetime),
+	}, nil
+}
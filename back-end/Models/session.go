@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// Session is a signed-in device: the family of refresh tokens issued by one
+// login. Revoking it revokes its refresh tokens and the access tokens issued
+// with them.
+type Session struct {
+	ID        int       `db:"id" json:"id"`
+	UserID    int       `db:"user_id" json:"userId"`
+	FamilyID  string    `db:"family_id" json:"-"`
+	UserAgent string    `db:"user_agent" json:"userAgent"`
+	IPAddress string    `db:"ip_address" json:"ipAddress"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+	// LastUsedAt is the latest login or refresh of the session.
+	LastUsedAt time.Time  `db:"last_used_at" json:"lastUsedAt"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revokedAt,omitempty"`
+
+	// Current marks the session of the request when listing.
+	Current bool `db:"-" json:"current"`
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// EnergyPerKg is the approximate energy content of one kilogram of body
+	// weight change, in kcal.
+	EnergyPerKg = 7700.0
+
+	// MinAcceptConfidence is the confidence an estimate needs before its
+	// suggested goal can be accepted.
+	MinAcceptConfidence = 0.3
+
+	// minWindowDays is the minimum number of logged days and of days between
+	// the first and last weigh-in for a window to produce an estimate.
+	minWindowDays = 7
+)
+
+// TDEEWindows are the lengths in days of the rolling windows the estimate is
+// built from. Longer windows smooth out logging gaps, shorter ones react to
+// recent changes in activity.
+var TDEEWindows = []int{14, 28}
+
+// TDEEWindow is the expenditure backed out of one rolling window: the average
+// logged intake minus the energy stored or released as weight change.
+type TDEEWindow struct {
+	Days          int     `json:"days"`
+	LoggedDays    int     `json:"loggedDays"`
+	WeighIns      int     `json:"weighIns"`
+	AverageIntake float64 `json:"averageIntake"`
+	WeightChange  float64 `json:"weightChange"`
+	TDEE          float64 `json:"tdee"`
+	Confidence    float64 `json:"confidence"`
+}
+
+// TDEEEstimate combines the windows into one expenditure estimate. TDEE is 0
+// when no window had enough data.
+type TDEEEstimate struct {
+	TDEE          int          `json:"tdee"`
+	Confidence    float64      `json:"confidence"`
+	Windows       []TDEEWindow `json:"windows"`
+	CurrentGoal   int          `json:"currentGoal"`
+	SuggestedGoal int          `json:"suggestedGoal"`
+}
+
+// CanAccept reports whether the suggested goal is reliable enough to apply.
+func (e *TDEEEstimate) CanAccept() bool {
+	return e.SuggestedGoal > 0 && e.Confidence >= MinAcceptConfidence
+}
+
+// EstimateTDEE estimates daily energy expenditure from the daily intake and
+// the weight trend over each of TDEEWindows, ending on the day of end. Days
+// without any logged calories are treated as not logged rather than fasted.
+// The windows are combined weighted by their confidence.
+func EstimateTDEE(intake []*DailyNutrition, points []WeightTrendPoint, end time.Time) TDEEEstimate {
+	estimate := TDEEEstimate{}
+
+	var weighted, totalConfidence float64
+	for _, days := range TDEEWindows {
+		window := estimateWindow(intake, points, end, days)
+		estimate.Windows = append(estimate.Windows, window)
+
+		weighted += window.TDEE * window.Confidence
+		totalConfidence += window.Confidence
+		estimate.Confidence = math.Max(estimate.Confidence, window.Confidence)
+	}
+
+	if totalConfidence > 0 {
+		estimate.TDEE = int(math.Round(weighted / totalConfidence))
+	}
+	return estimate
+}
+
+func estimateWindow(intake []*DailyNutrition, points []WeightTrendPoint, end time.Time, days int) TDEEWindow {
+	window := TDEEWindow{Days: days}
+
+	lastDay := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	firstDay := lastDay.AddDate(0, 0, -(days - 1))
+	inWindow := func(t time.Time) bool {
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return !day.Before(firstDay) && !day.After(lastDay)
+	}
+
+	var totalIntake float64
+	for _, day := range intake {
+		if inWindow(day.Date) && day.TotalCalories > 0 {
+			window.LoggedDays++
+			totalIntake += day.TotalCalories
+		}
+	}
+	if window.LoggedDays > 0 {
+		window.AverageIntake = round2(totalIntake / float64(window.LoggedDays))
+	}
+
+	// The change is measured on the trend, starting from the last weigh-in
+	// before the window when there is one.
+	var first, last *WeightTrendPoint
+	for i := range points {
+		point := &points[i]
+		switch {
+		case inWindow(point.RecordedAt):
+			window.WeighIns++
+			if first == nil {
+				first = point
+			}
+			last = point
+		case point.RecordedAt.Before(firstDay):
+			first = point
+		}
+	}
+	if first == nil || last == nil || window.LoggedDays < minWindowDays {
+		return window
+	}
+
+	span := last.RecordedAt.Sub(first.RecordedAt).Hours() / 24
+	if span < minWindowDays {
+		return window
+	}
+
+	window.WeightChange = round2(last.Trend - first.Trend)
+	tdee := window.AverageIntake - window.WeightChange*EnergyPerKg/span
+
+	// Implausible results almost always mean meals were not logged.
+	if tdee < 1000 || tdee > 6000 {
+		return window
+	}
+
+	coverage := float64(window.LoggedDays) / float64(days)
+	weighInCoverage := math.Min(1, float64(window.WeighIns)/(float64(days)/2))
+	spanCoverage := math.Min(1, span/float64(days))
+
+	window.TDEE = math.Round(tdee)
+	window.Confidence = round2(coverage * weighInCoverage * spanCoverage)
+	return window
+}
@@ -0,0 +1,167 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+var tdeeEnd = time.Date(2026, 3, 28, 0, 0, 0, 0, time.UTC)
+
+// tdeeDay returns the date of day i of the 28 days ending on tdeeEnd.
+func tdeeDay(i int) time.Time {
+	return tdeeEnd.AddDate(0, 0, i-27)
+}
+
+// loggedIntake returns a row for each of the 28 days, as the nutrition
+// history does, with zero calories on the days that weren't logged.
+func loggedIntake(calories func(i int) float64) []*DailyNutrition {
+	intake := make([]*DailyNutrition, 0, 28)
+	for i := 0; i < 28; i++ {
+		intake = append(intake, &DailyNutrition{Date: tdeeDay(i), TotalCalories: calories(i)})
+	}
+	return intake
+}
+
+// dailyWeighIns returns a morning weigh-in on each day from first to last.
+func dailyWeighIns(first, last int, trend func(i int) float64) []WeightTrendPoint {
+	var points []WeightTrendPoint
+	for i := first; i <= last; i++ {
+		at := tdeeDay(i).Add(7 * time.Hour)
+		points = append(points, WeightTrendPoint{WeightEntry: WeightEntry{Weight: trend(i), RecordedAt: at}, Trend: trend(i)})
+	}
+	return points
+}
+
+func constant(value float64) func(int) float64 {
+	return func(int) float64 { return value }
+}
+
+func TestEstimateTDEE(t *testing.T) {
+	tests := []struct {
+		name           string
+		intake         []*DailyNutrition
+		points         []WeightTrendPoint
+		wantWindows    [2]float64
+		wantTDEE       int
+		wantConfidence float64
+	}{
+		{
+			name: "no data",
+		},
+		{
+			name:           "stable weight",
+			intake:         loggedIntake(constant(2500)),
+			points:         dailyWeighIns(0, 27, constant(80)),
+			wantWindows:    [2]float64{2500, 2500},
+			wantTDEE:       2500,
+			wantConfidence: 1,
+		},
+		{
+			// 1.4 kg lost over the last 14 days and 2.7 kg over 27 days
+			// both take 770 kcal a day.
+			name:           "losing",
+			intake:         loggedIntake(constant(2000)),
+			points:         dailyWeighIns(0, 27, func(i int) float64 { return 80 - 0.1*float64(i) }),
+			wantWindows:    [2]float64{2770, 2770},
+			wantTDEE:       2770,
+			wantConfidence: 1,
+		},
+		{
+			// Unlogged days are left out of the average intake instead of
+			// counting as fasted, and lower the confidence.
+			name: "every other day logged",
+			intake: loggedIntake(func(i int) float64 {
+				if i%2 == 0 {
+					return 2500
+				}
+				return 0
+			}),
+			points:         dailyWeighIns(0, 27, constant(80)),
+			wantWindows:    [2]float64{2500, 2500},
+			wantTDEE:       2500,
+			wantConfidence: 0.5,
+		},
+		{
+			name: "too few logged days",
+			intake: loggedIntake(func(i int) float64 {
+				if i >= 22 {
+					return 2500
+				}
+				return 0
+			}),
+			points: dailyWeighIns(0, 27, constant(80)),
+		},
+		{
+			name:   "weigh-ins span under a week",
+			intake: loggedIntake(constant(2500)),
+			points: dailyWeighIns(22, 27, constant(80)),
+		},
+		{
+			name:   "no weigh-in in the windows",
+			intake: loggedIntake(constant(2500)),
+			points: dailyWeighIns(-10, -1, constant(80)),
+		},
+		{
+			name:   "implausibly low",
+			intake: loggedIntake(constant(800)),
+			points: dailyWeighIns(0, 27, constant(80)),
+		},
+		{
+			name:   "implausibly high",
+			intake: loggedIntake(constant(6500)),
+			points: dailyWeighIns(0, 27, constant(80)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			estimate := EstimateTDEE(tt.intake, tt.points, tdeeEnd)
+			if len(estimate.Windows) != len(TDEEWindows) {
+				t.Fatalf("got %d windows, want %d", len(estimate.Windows), len(TDEEWindows))
+			}
+			for i, want := range tt.wantWindows {
+				if window := estimate.Windows[i]; window.TDEE != want {
+					t.Errorf("%d-day window TDEE = %v, want %v (%+v)", window.Days, window.TDEE, want, window)
+				}
+			}
+			if estimate.TDEE != tt.wantTDEE || estimate.Confidence != tt.wantConfidence {
+				t.Errorf("estimate = %d kcal at %v confidence, want %d at %v",
+					estimate.TDEE, estimate.Confidence, tt.wantTDEE, tt.wantConfidence)
+			}
+		})
+	}
+}
+
+func TestEstimateTDEEConfidence(t *testing.T) {
+	// Only the last 14 days have weigh-ins, so both windows measure the
+	// change over 13 days, less than half of the 28-day window.
+	estimate := EstimateTDEE(loggedIntake(constant(2500)), dailyWeighIns(14, 27, constant(80)), tdeeEnd)
+
+	want := []float64{0.93, 0.46}
+	for i, window := range estimate.Windows {
+		if window.Confidence != want[i] {
+			t.Errorf("%d-day window confidence = %v, want %v", window.Days, window.Confidence, want[i])
+		}
+	}
+	if estimate.Confidence != 0.93 {
+		t.Errorf("estimate confidence = %v, want the best window's 0.93", estimate.Confidence)
+	}
+}
+
+func TestCanAccept(t *testing.T) {
+	tests := []struct {
+		confidence    float64
+		suggestedGoal int
+		want          bool
+	}{
+		{1, 2000, true},
+		{MinAcceptConfidence, 2000, true},
+		{MinAcceptConfidence - 0.01, 2000, false},
+		{1, 0, false},
+	}
+	for _, tt := range tests {
+		estimate := TDEEEstimate{Confidence: tt.confidence, SuggestedGoal: tt.suggestedGoal}
+		if got := estimate.CanAccept(); got != tt.want {
+			t.Errorf("CanAccept at %v confidence with goal %d = %v, want %v", tt.confidence, tt.suggestedGoal, got, tt.want)
+		}
+	}
+}
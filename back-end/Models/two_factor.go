@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+)
+
+// TwoFactor is the TOTP enrollment of a user. It is pending until the user
+// confirms it with a first code, and only then required at login.
+type TwoFactor struct {
+	UserID int `db:"user_id" json:"-"`
+	// SecretEncrypted is the base32 secret, encrypted with the server's
+	// two-factor key.
+	SecretEncrypted string     `db:"secret_encrypted" json:"-"`
+	EnabledAt       *time.Time `db:"enabled_at" json:"-"`
+	// LastUsedStep is the time step of the last accepted code; codes of that
+	// step or earlier are rejected so they can't be replayed.
+	LastUsedStep int64 `db:"last_used_step" json:"-"`
+	// FailedAttempts counts the wrong codes since the last accepted one.
+	// When it reaches the limit, codes are refused until LockedUntil.
+	FailedAttempts int        `db:"failed_attempts" json:"-"`
+	LockedUntil    *time.Time `db:"locked_until" json:"-"`
+	CreatedAt      time.Time  `db:"created_at" json:"-"`
+}
+
+// MFAChallenge is a login waiting for its second factor. Its ID is the jti
+// of the mfaToken handed out after the password check; the challenge takes a
+// limited number of attempts and is used up by the one that succeeds.
+type MFAChallenge struct {
+	ID        string     `db:"id" json:"-"`
+	UserID    int        `db:"user_id" json:"-"`
+	Attempts  int        `db:"attempts" json:"-"`
+	CreatedAt time.Time  `db:"created_at" json:"-"`
+	ExpiresAt time.Time  `db:"expires_at" json:"-"`
+	UsedAt    *time.Time `db:"used_at" json:"-"`
+}
+
+// NewMFAChallenge returns a challenge for userID with a random ID, valid for
+// lifetime from now.
+func NewMFAChallenge(userID int, lifetime time.Duration, now time.Time) (*MFAChallenge, error) {
+	id, err := generateID()
+	if err != nil {
+		return nil, err
+	}
+	return &MFAChallenge{
+		ID:        id,
+		UserID:    userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(lifetime),
+	}, nil
+}
+
+// RecoveryCode is a stored one-time code that replaces a TOTP code when the
+// authenticator is lost. Only the hash of the code is stored.
+type RecoveryCode struct {
+	ID        int        `db:"id" json:"-"`
+	UserID    int        `db:"user_id" json:"-"`
+	CodeHash  string     `db:"code_hash" json:"-"`
+	CreatedAt time.Time  `db:"created_at" json:"-"`
+	UsedAt    *time.Time `db:"used_at" json:"-"`
+}
+
+// TwoFactorStatus describes the two-factor setup of a user.
+type TwoFactorStatus struct {
+	Enabled bool `json:"enabled"`
+	// Pending is true between enrollment and its confirmation.
+	Pending bool `json:"pending"`
+	// Required is true when the user's role must use two-factor login.
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recoveryCodesRemaining"`
+}
@@ -16,23 +16,36 @@ type User struct {
 	Gender           string    `db:"gender" json:"gender"`
 	Height           float64   `db:"height" json:"height"`
 	Weight           float64   `db:"weight" json:"weight"`
+	BodyFat          *float64  `db:"body_fat" json:"bodyFat"`
 	GoalType         string    `db:"goal_type" json:"goalType"`
 	ActivityLevel    string    `db:"activity_level" json:"activityLevel"`
 	DailyCalorieGoal int       `db:"daily_calorie_goal" json:"dailyCalorieGoal"`
 	Role             string    `db:"role" json:"role"`
-	CreatedAt        time.Time `db:"created_at" json:"createdAt"`
-	UpdatedAt        time.Time `db:"updated_at" json:"updatedAt"`
+	// EmailVerifiedAt is nil until the user follows the link mailed on
+	// registration.
+	EmailVerifiedAt *time.Time `db:"email_verified_at" json:"emailVerifiedAt"`
+	CreatedAt       time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt       time.Time  `db:"updated_at" json:"updatedAt"`
 }
 
 func (u *User) SetPassword(password string) error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := HashPassword(password)
 	if err != nil {
 		return err
 	}
-	u.PasswordHash = string(hashedPassword)
+	u.PasswordHash = hashedPassword
 	return nil
 }
 
+// HashPassword returns the bcrypt hash stored for a password.
+func HashPassword(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashedPassword), nil
+}
+
 func (u *User) CheckPassword(password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password))
 	return err == nil
@@ -77,8 +90,10 @@ type AuthUser struct {
 	Gender           string    `json:"gender"`
 	Height           float64   `json:"height"`
 	Weight           float64   `json:"weight"`
+	BodyFat          *float64  `json:"bodyFat"`
 	Birthdate        time.Time `json:"birthdate"`
 	DailyCalorieGoal int       `json:"dailyCalorieGoal"`
+	EmailVerified    bool      `json:"emailVerified"`
 }
 
 func (u *User) ToAuthUser() *AuthUser {
@@ -93,8 +108,10 @@ func (u *User) ToAuthUser() *AuthUser {
 		Gender:           u.Gender,
 		Height:           u.Height,
 		Weight:           u.Weight,
+		BodyFat:          u.BodyFat,
 		Birthdate:        u.Birthdate,
 		DailyCalorieGoal: u.DailyCalorieGoal,
+		EmailVerified:    u.EmailVerifiedAt != nil,
 	}
 }
 
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+)
+
+// Purposes of user tokens
+const (
+	TokenPasswordReset     = "password_reset"
+	TokenEmailVerification = "email_verification"
+)
+
+const (
+	// PasswordResetLifetime is how long a password reset link can be used.
+	PasswordResetLifetime = time.Hour
+	// PasswordResetResendInterval is how long a user waits before another
+	// password reset link is mailed.
+	PasswordResetResendInterval = 2 * time.Minute
+	// EmailVerificationLifetime is how long an email verification link can
+	// be used.
+	EmailVerificationLifetime = 48 * time.Hour
+	// EmailVerificationResendInterval is how long a user waits before another
+	// verification link is mailed.
+	EmailVerificationResendInterval = 2 * time.Minute
+)
+
+// UserToken is a stored single-use token mailed to a user, such as a password
+// reset token. Only the hash of the token is stored.
+type UserToken struct {
+	ID        int        `db:"id" json:"-"`
+	UserID    int        `db:"user_id" json:"-"`
+	Purpose   string     `db:"purpose" json:"-"`
+	TokenHash string     `db:"token_hash" json:"-"`
+	CreatedAt time.Time  `db:"created_at" json:"-"`
+	ExpiresAt time.Time  `db:"expires_at" json:"-"`
+	UsedAt    *time.Time `db:"used_at" json:"-"`
+}
+
+// NewUserToken returns a token for userID and its stored form, valid for
+// lifetime.
+func NewUserToken(userID int, purpose string, lifetime time.Duration, now time.Time) (string, *UserToken, error) {
+	token, err := GenerateToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, &UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: HashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(lifetime),
+	}, nil
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// WeightEntry is one weigh-in of a user, in kilograms.
+type WeightEntry struct {
+	ID         int       `db:"id" json:"id"`
+	UserID     int       `db:"user_id" json:"userId"`
+	Weight     float64   `db:"weight" json:"weight"`
+	RecordedAt time.Time `db:"recorded_at" json:"recordedAt"`
+	CreatedAt  time.Time `db:"created_at" json:"createdAt"`
+}
+
+// WeightEntryRequest logs a weigh-in. RecordedAt defaults to now.
+type WeightEntryRequest struct {
+	Weight     float64    `json:"weight" binding:"required,gt=0,lte=500"`
+	RecordedAt *time.Time `json:"recordedAt"`
+}
+
+// WeightTrendPoint is a weigh-in together with the smoothed trend weight at
+// that moment.
+type WeightTrendPoint struct {
+	WeightEntry
+	Trend float64 `json:"trend"`
+}
+
+const (
+	// WeightTrendSmoothing is the share of a new daily weigh-in that moves the
+	// trend, so day-to-day water swings barely register.
+	WeightTrendSmoothing = 0.1
+
+	// weightProjectionWindow is how far back the trend slope is measured when
+	// projecting the target date.
+	weightProjectionWindow = 28 * 24 * time.Hour
+
+	// maxProjectionDays caps projections that are too far out to be useful.
+	maxProjectionDays = 730
+)
+
+// WeightTrend computes an exponentially weighted moving average over entries
+// sorted by RecordedAt. The smoothing factor is applied per day, so gaps
+// between weigh-ins give the next entry proportionally more weight.
+func WeightTrend(entries []WeightEntry) []WeightTrendPoint {
+	points := make([]WeightTrendPoint, 0, len(entries))
+	for i, entry := range entries {
+		trend := entry.Weight
+		if i > 0 {
+			previous := points[i-1]
+			days := entry.RecordedAt.Sub(previous.RecordedAt).Hours() / 24
+			alpha := 1 - math.Pow(1-WeightTrendSmoothing, math.Max(days, 0))
+			trend = previous.Trend + alpha*(entry.Weight-previous.Trend)
+		}
+		points = append(points, WeightTrendPoint{WeightEntry: entry, Trend: round2(trend)})
+	}
+	return points
+}
+
+// WeeklyWeightChange returns the change of the trend per week over the last
+// four weeks of points, or 0 when the points span less than a week.
+func WeeklyWeightChange(points []WeightTrendPoint) float64 {
+	if len(points) < 2 {
+		return 0
+	}
+
+	last := points[len(points)-1]
+	first := points[0]
+	for _, point := range points {
+		if last.RecordedAt.Sub(point.RecordedAt) <= weightProjectionWindow {
+			first = point
+			break
+		}
+	}
+
+	days := last.RecordedAt.Sub(first.RecordedAt).Hours() / 24
+	if days < 7 {
+		return 0
+	}
+	return round2((last.Trend - first.Trend) / days * 7)
+}
+
+// ProjectTargetDate extrapolates the current weekly change from the latest
+// trend weight and returns when the target weight will be reached. It returns
+// nil when the trend is flat, moving away from the target, or would take
+// more than two years.
+func ProjectTargetDate(points []WeightTrendPoint, targetWeight float64) *time.Time {
+	if len(points) == 0 || targetWeight <= 0 {
+		return nil
+	}
+
+	last := points[len(points)-1]
+	remaining := targetWeight - last.Trend
+	if math.Abs(remaining) < 0.1 {
+		reached := last.RecordedAt
+		return &reached
+	}
+
+	weekly := WeeklyWeightChange(points)
+	if weekly == 0 || math.Signbit(weekly) != math.Signbit(remaining) {
+		return nil
+	}
+
+	days := remaining / weekly * 7
+	if days > maxProjectionDays {
+		return nil
+	}
+
+	projected := last.RecordedAt.AddDate(0, 0, int(math.Ceil(days)))
+	return &projected
+}
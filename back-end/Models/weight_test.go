@@ -0,0 +1,111 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+var weightStart = time.Date(2026, 3, 1, 7, 0, 0, 0, time.UTC)
+
+func weighIn(day float64, weight float64) WeightEntry {
+	return WeightEntry{Weight: weight, RecordedAt: weightStart.Add(time.Duration(day * 24 * float64(time.Hour)))}
+}
+
+func trendPoint(day int, trend float64) WeightTrendPoint {
+	return WeightTrendPoint{WeightEntry: weighIn(float64(day), trend), Trend: trend}
+}
+
+func TestWeightTrend(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []WeightEntry
+		want    []float64
+	}{
+		{"no entries", nil, []float64{}},
+		{"single entry", []WeightEntry{weighIn(0, 80)}, []float64{80}},
+		{"daily", []WeightEntry{weighIn(0, 80), weighIn(1, 81), weighIn(2, 81)}, []float64{80, 80.1, 80.19}},
+		// 1 - 0.9^3 of the change after a three day gap.
+		{"gap", []WeightEntry{weighIn(0, 80), weighIn(3, 81)}, []float64{80, 80.27}},
+		{"week gap", []WeightEntry{weighIn(0, 80), weighIn(7, 70)}, []float64{80, 74.78}},
+		{"half day", []WeightEntry{weighIn(0, 80), weighIn(0.5, 82)}, []float64{80, 80.1}},
+		{"same moment", []WeightEntry{weighIn(0, 80), weighIn(0, 90)}, []float64{80, 80}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points := WeightTrend(tt.entries)
+			if len(points) != len(tt.want) {
+				t.Fatalf("got %d points, want %d", len(points), len(tt.want))
+			}
+			for i, want := range tt.want {
+				if points[i].Trend != want {
+					t.Errorf("trend[%d] = %v, want %v", i, points[i].Trend, want)
+				}
+				if points[i].Weight != tt.entries[i].Weight {
+					t.Errorf("weight[%d] = %v, want %v", i, points[i].Weight, tt.entries[i].Weight)
+				}
+			}
+		})
+	}
+}
+
+func TestWeeklyWeightChange(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []WeightTrendPoint
+		want   float64
+	}{
+		{"no points", nil, 0},
+		{"single point", []WeightTrendPoint{trendPoint(0, 80)}, 0},
+		{"under a week", []WeightTrendPoint{trendPoint(0, 80), trendPoint(6, 79)}, 0},
+		{"losing", []WeightTrendPoint{trendPoint(0, 80), trendPoint(7, 79.5), trendPoint(14, 79)}, -0.5},
+		{"gaining", []WeightTrendPoint{trendPoint(0, 60), trendPoint(21, 61.5)}, 0.5},
+		// Only the last four weeks count.
+		{"window", []WeightTrendPoint{trendPoint(0, 90), trendPoint(9, 85), trendPoint(10, 80), trendPoint(38, 78)}, -0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WeeklyWeightChange(tt.points); got != tt.want {
+				t.Errorf("WeeklyWeightChange = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectTargetDate(t *testing.T) {
+	losing := []WeightTrendPoint{trendPoint(0, 80), trendPoint(14, 79)}
+
+	tests := []struct {
+		name   string
+		points []WeightTrendPoint
+		target float64
+		want   *time.Time
+	}{
+		{"no points", nil, 75, nil},
+		{"no target", losing, 0, nil},
+		{"reached", losing, 79.05, &losing[1].RecordedAt},
+		// 4 kg at 0.5 kg a week.
+		{"losing", losing, 75, timePtr(losing[1].RecordedAt.AddDate(0, 0, 56))},
+		{"wrong way", losing, 85, nil},
+		{"flat", []WeightTrendPoint{trendPoint(0, 80), trendPoint(14, 80)}, 75, nil},
+		{"too short to tell", []WeightTrendPoint{trendPoint(0, 80), trendPoint(3, 79)}, 75, nil},
+		// 20 kg at 0.1 kg a week takes 1400 days.
+		{"too far out", []WeightTrendPoint{trendPoint(0, 80), trendPoint(28, 79.6)}, 59.6, nil},
+		{"gaining", []WeightTrendPoint{trendPoint(0, 60), trendPoint(21, 61.5)}, 62.5, timePtr(weighIn(35, 0).RecordedAt)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ProjectTargetDate(tt.points, tt.target)
+			switch {
+			case got == nil && tt.want == nil:
+			case got == nil || tt.want == nil:
+				t.Errorf("ProjectTargetDate = %v, want %v", got, tt.want)
+			case !got.Equal(*tt.want):
+				t.Errorf("ProjectTargetDate = %v, want %v", *got, *tt.want)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,212 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	models "HabitBite/backend/Models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrAlertNotFound = errors.New("alert not found")
+	ErrAlertNotOpen  = errors.New("alert is not open")
+)
+
+// AlertRepository stores the alerts the nightly rules raise for dietitians.
+type AlertRepository interface {
+	// SyncAlerts records the findings of one evaluation of a client for each
+	// of their dietitians: new findings open alerts, resolved ones are opened
+	// again, and the client's alerts whose rule no longer applies, or whose
+	// dietitian is no longer listed, are resolved. It returns how many alerts
+	// were opened.
+	SyncAlerts(ctx context.Context, userID int, dietitianIDs []int, findings []models.AlertFinding, now time.Time) (int, error)
+	// GetAlerts returns a dietitian's alerts about their current clients,
+	// newest first. Status is one of the stored statuses or AlertSnoozed;
+	// AlertOpen leaves out snoozed alerts, and "" returns all of them. A
+	// clientID of 0 returns the alerts of every client.
+	GetAlerts(ctx context.Context, dietitianID int, status string, clientID int, now time.Time) ([]models.Alert, error)
+	// AcknowledgeAlert marks an open alert as seen; it stays acknowledged
+	// until its rule stops applying.
+	AcknowledgeAlert(ctx context.Context, dietitianID, alertID int, now time.Time) (*models.Alert, error)
+	// SnoozeAlert hides an open alert until the given time.
+	SnoozeAlert(ctx context.Context, dietitianID, alertID int, until time.Time) (*models.Alert, error)
+}
+
+type alertRepository struct {
+	db      *sqlx.DB
+	dialect dialect
+}
+
+func NewAlertRepository(db *sqlx.DB) AlertRepository {
+	return &alertRepository{db: db, dialect: dialectFor(db)}
+}
+
+type alertKey struct {
+	dietitianID int
+	rule        string
+}
+
+func (r *alertRepository) SyncAlerts(ctx context.Context, userID int, dietitianIDs []int, findings []models.AlertFinding, now time.Time) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	var existing []models.Alert
+	query := `SELECT * FROM alerts WHERE user_id = ?` + r.dialect.ForUpdate()
+	if err := tx.SelectContext(ctx, &existing, tx.Rebind(query), userID); err != nil {
+		return 0, wrapDatabaseError(err)
+	}
+	stored := make(map[alertKey]models.Alert, len(existing))
+	for _, alert := range existing {
+		stored[alertKey{alert.DietitianID, alert.Rule}] = alert
+	}
+
+	opened := 0
+	found := make(map[alertKey]bool)
+	for _, dietitianID := range dietitianIDs {
+		for _, finding := range findings {
+			key := alertKey{dietitianID, finding.Rule}
+			found[key] = true
+
+			alert, ok := stored[key]
+			switch {
+			case !ok:
+				insertQuery := `
+					INSERT INTO alerts (dietitian_id, user_id, rule, status, message, detected_at, last_seen_at)
+					VALUES (?, ?, ?, ?, ?, ?, ?)
+				`
+				_, err = r.dialect.InsertReturningID(ctx, tx, insertQuery,
+					dietitianID, userID, finding.Rule, models.AlertOpen, finding.Message, now, now)
+				opened++
+			case alert.Status == models.AlertResolved:
+				reopenQuery := `
+					UPDATE alerts
+					SET status = ?, message = ?, detected_at = ?, last_seen_at = ?,
+						acknowledged_at = NULL, snoozed_until = NULL, resolved_at = NULL
+					WHERE id = ?
+				`
+				_, err = tx.ExecContext(ctx, tx.Rebind(reopenQuery), models.AlertOpen, finding.Message, now, now, alert.ID)
+				opened++
+			default:
+				_, err = tx.ExecContext(ctx, tx.Rebind(`UPDATE alerts SET message = ?, last_seen_at = ? WHERE id = ?`),
+					finding.Message, now, alert.ID)
+			}
+			if err != nil {
+				return 0, wrapDatabaseError(err)
+			}
+		}
+	}
+
+	for key, alert := range stored {
+		if found[key] || alert.Status == models.AlertResolved {
+			continue
+		}
+		_, err := tx.ExecContext(ctx, tx.Rebind(`UPDATE alerts SET status = ?, resolved_at = ? WHERE id = ?`),
+			models.AlertResolved, now, alert.ID)
+		if err != nil {
+			return 0, wrapDatabaseError(err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, wrapDatabaseError(err)
+	}
+
+	return opened, nil
+}
+
+func (r *alertRepository) GetAlerts(ctx context.Context, dietitianID int, status string, clientID int, now time.Time) ([]models.Alert, error) {
+	query := `
+		SELECT a.*, u.full_name
+		FROM alerts a
+		JOIN users u ON u.id = a.user_id
+		JOIN user_dietitian ud ON ud.user_id = a.user_id AND ud.dietitian_id = a.dietitian_id
+		WHERE a.dietitian_id = ? AND ud.status = ?
+	`
+	args := []interface{}{dietitianID, models.SubscriptionAccepted}
+
+	switch status {
+	case "":
+	case models.AlertOpen:
+		query += ` AND a.status = ? AND (a.snoozed_until IS NULL OR a.snoozed_until <= ?)`
+		args = append(args, models.AlertOpen, now)
+	case models.AlertSnoozed:
+		query += ` AND a.status = ? AND a.snoozed_until > ?`
+		args = append(args, models.AlertOpen, now)
+	default:
+		query += ` AND a.status = ?`
+		args = append(args, status)
+	}
+	if clientID != 0 {
+		query += ` AND a.user_id = ?`
+		args = append(args, clientID)
+	}
+	query += ` ORDER BY a.detected_at DESC, a.id DESC`
+
+	alerts := []models.Alert{}
+	if err := r.db.SelectContext(ctx, &alerts, r.db.Rebind(query), args...); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	return alerts, nil
+}
+
+func (r *alertRepository) AcknowledgeAlert(ctx context.Context, dietitianID, alertID int, now time.Time) (*models.Alert, error) {
+	alert, err := r.getAlert(ctx, dietitianID, alertID)
+	if err != nil {
+		return nil, err
+	}
+	switch alert.Status {
+	case models.AlertAcknowledged:
+		return alert, nil
+	case models.AlertResolved:
+		return nil, ErrAlertNotOpen
+	}
+
+	query := `UPDATE alerts SET status = ?, acknowledged_at = ?, snoozed_until = NULL WHERE id = ?`
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), models.AlertAcknowledged, now, alertID); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	alert.Status = models.AlertAcknowledged
+	alert.AcknowledgedAt = &now
+	alert.SnoozedUntil = nil
+	return alert, nil
+}
+
+func (r *alertRepository) SnoozeAlert(ctx context.Context, dietitianID, alertID int, until time.Time) (*models.Alert, error) {
+	alert, err := r.getAlert(ctx, dietitianID, alertID)
+	if err != nil {
+		return nil, err
+	}
+	if alert.Status != models.AlertOpen {
+		return nil, ErrAlertNotOpen
+	}
+
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(`UPDATE alerts SET snoozed_until = ? WHERE id = ?`), until, alertID); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	alert.SnoozedUntil = &until
+	return alert, nil
+}
+
+func (r *alertRepository) getAlert(ctx context.Context, dietitianID, alertID int) (*models.Alert, error) {
+	query := `
+		SELECT a.*, u.full_name
+		FROM alerts a
+		JOIN users u ON u.id = a.user_id
+		WHERE a.id = ? AND a.dietitian_id = ?
+	`
+	var alert models.Alert
+	if err := r.db.GetContext(ctx, &alert, r.db.Rebind(query), alertID, dietitianID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAlertNotFound
+		}
+		return nil, wrapDatabaseError(err)
+	}
+	return &alert, nil
+}
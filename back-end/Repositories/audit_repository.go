@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	models "HabitBite/backend/Models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type AuditRepository interface {
+	RecordAudit(ctx context.Context, entry *models.AuditEntry) error
+	GetAuditEntries(ctx context.Context, targetUserID int, limit int) ([]models.AuditEntry, error)
+}
+
+type auditRepository struct {
+	db      *sqlx.DB
+	dialect dialect
+}
+
+func NewAuditRepository(db *sqlx.DB) AuditRepository {
+	return &auditRepository{db: db, dialect: dialectFor(db)}
+}
+
+func (r *auditRepository) RecordAudit(ctx context.Context, entry *models.AuditEntry) error {
+	entry.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO audit_log (
+			actor_id, actor_role, action, target_user_id, entity_id, ip_address, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	id, err := r.dialect.InsertReturningID(ctx, r.db, query,
+		entry.ActorID, entry.ActorRole, entry.Action, entry.TargetUserID,
+		entry.EntityID, entry.IPAddress, entry.CreatedAt)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	entry.ID = int(id)
+
+	return nil
+}
+
+// GetAuditEntries returns the most recent audit entries, newest first. A
+// targetUserID of 0 returns entries for all users.
+func (r *auditRepository) GetAuditEntries(ctx context.Context, targetUserID int, limit int) ([]models.AuditEntry, error) {
+	query := `SELECT * FROM audit_log ORDER BY id DESC LIMIT ?`
+	args := []interface{}{limit}
+	if targetUserID != 0 {
+		query = `SELECT * FROM audit_log WHERE target_user_id = ? ORDER BY id DESC LIMIT ?`
+		args = []interface{}{targetUserID, limit}
+	}
+
+	entries := []models.AuditEntry{}
+	if err := r.db.SelectContext(ctx, &entries, r.db.Rebind(query), args...); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	models "HabitBite/backend/Models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrCommentNotFound = errors.New("comment not found")
+
+// CommentRepository stores the comment threads on a client's food log. Every
+// method is scoped to the client the comments are about. Comments on an
+// entry are deleted with it.
+type CommentRepository interface {
+	// CreateComment saves a comment and fills in its date and author. A reply
+	// must carry the entry and date of its thread.
+	CreateComment(ctx context.Context, comment *models.Comment) error
+	GetComment(ctx context.Context, userID, commentID int) (*models.Comment, error)
+	// GetComments returns the comments on days from startDate to endDate,
+	// both included, oldest first. A dietitianID of 0 returns the threads of
+	// every dietitian, otherwise only that dietitian's.
+	GetComments(ctx context.Context, userID, dietitianID int, startDate, endDate time.Time) ([]models.Comment, error)
+	// DeleteComment deletes a comment and, for a thread, its replies.
+	DeleteComment(ctx context.Context, userID, commentID int) error
+}
+
+type commentRepository struct {
+	db      *sqlx.DB
+	dialect dialect
+}
+
+func NewCommentRepository(db *sqlx.DB) CommentRepository {
+	return &commentRepository{db: db, dialect: dialectFor(db)}
+}
+
+// selectComments returns the query for comments with their date, resolved
+// through the entry for entry comments, and their author.
+func (r *commentRepository) selectComments() string {
+	return fmt.Sprintf(`
+		SELECT c.id, c.user_id, c.dietitian_id, c.author_id, c.parent_id, c.entry_id,
+			c.body, c.created_at, %s AS day, a.full_name AS author_name, a.role AS author_role
+		FROM comments c
+		LEFT JOIN consumed_foods f ON f.id = c.entry_id
+		JOIN users a ON a.id = c.author_id
+	`, r.dialect.DateString("COALESCE(c.entry_date, f.entry_date)"))
+}
+
+func (r *commentRepository) CreateComment(ctx context.Context, comment *models.Comment) error {
+	// Entry comments take their date from the entry, so it follows the entry
+	// when it is moved to another day.
+	var entryDate interface{}
+	if comment.EntryID == nil {
+		entryDate = comment.Date
+	}
+
+	query := `
+		INSERT INTO comments (
+			user_id, dietitian_id, author_id, parent_id, entry_id, entry_date, body, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	id, err := r.dialect.InsertReturningID(ctx, r.db, query,
+		comment.UserID, comment.DietitianID, comment.AuthorID, comment.ParentID, comment.EntryID,
+		entryDate, comment.Body, time.Now())
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	saved, err := r.GetComment(ctx, comment.UserID, int(id))
+	if err != nil {
+		return err
+	}
+	*comment = *saved
+	return nil
+}
+
+func (r *commentRepository) GetComment(ctx context.Context, userID, commentID int) (*models.Comment, error) {
+	var comment models.Comment
+	query := r.selectComments() + ` WHERE c.id = ? AND c.user_id = ?`
+	if err := r.db.GetContext(ctx, &comment, r.db.Rebind(query), commentID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, wrapDatabaseError(err)
+	}
+	return &comment, nil
+}
+
+func (r *commentRepository) GetComments(ctx context.Context, userID, dietitianID int, startDate, endDate time.Time) ([]models.Comment, error) {
+	query := r.selectComments() + fmt.Sprintf(`
+		WHERE c.user_id = ? AND %s BETWEEN ? AND ?
+	`, r.dialect.Date("COALESCE(c.entry_date, f.entry_date)"))
+	args := []interface{}{userID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")}
+	if dietitianID != 0 {
+		query += ` AND c.dietitian_id = ?`
+		args = append(args, dietitianID)
+	}
+	query += ` ORDER BY c.created_at ASC, c.id ASC`
+
+	comments := []models.Comment{}
+	if err := r.db.SelectContext(ctx, &comments, r.db.Rebind(query), args...); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	return comments, nil
+}
+
+func (r *commentRepository) DeleteComment(ctx context.Context, userID, commentID int) error {
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(`DELETE FROM comments WHERE id = ? AND user_id = ?`), commentID, userID)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	if rows == 0 {
+		return ErrCommentNotFound
+	}
+	return nil
+}
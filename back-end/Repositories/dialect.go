@@ -0,0 +1,220 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// dialect hides the SQL differences between the supported database drivers.
+// Queries that are valid on every backend are written inline; only the
+// fragments below vary.
+type dialect interface {
+	// Date returns an expression for the calendar date of a datetime value.
+	Date(expr string) string
+	// DateString returns the date of a datetime value formatted as YYYY-MM-DD.
+	DateString(expr string) string
+	// DateSeries returns a query yielding one YYYY-MM-DD row named date for
+	// every day between two date parameters, inclusive.
+	DateSeries() string
+	// ForUpdate returns the row locking clause for SELECT statements.
+	ForUpdate() string
+	// Upsert returns the clause that updates the given columns when an insert
+	// conflicts on the key columns.
+	Upsert(keys []string, columns []string) string
+	// FullTextSearch returns a query selecting the foods with a description or
+	// brand word starting with one of the prefixes, best match first, ending in
+	// a LIMIT ? placeholder, and its arguments apart from the limit. ok is false
+	// when the database has no full-text index on foods.
+	FullTextSearch(prefixes []string) (query string, args []interface{}, ok bool)
+	// InsertReturningID runs an INSERT written with ? placeholders and returns
+	// the id of the new row.
+	InsertReturningID(ctx context.Context, q sqlx.ExtContext, query string, args ...interface{}) (int64, error)
+}
+
+// dialectFor returns the dialect of the driver the connection was opened with.
+func dialectFor(db *sqlx.DB) dialect {
+	switch db.DriverName() {
+	case "sqlite3":
+		return sqliteDialect{}
+	case "pgx":
+		return postgresDialect{}
+	default:
+		return mysqlDialect{}
+	}
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Date(expr string) string {
+	return fmt.Sprintf("DATE(%s)", expr)
+}
+
+func (mysqlDialect) DateString(expr string) string {
+	return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d')", expr)
+}
+
+func (mysqlDialect) DateSeries() string {
+	return `
+		WITH RECURSIVE dates(date) AS (
+			SELECT DATE(?)
+			UNION ALL
+			SELECT DATE_ADD(date, INTERVAL 1 DAY)
+			FROM dates
+			WHERE date < DATE(?)
+		)
+		SELECT DATE_FORMAT(date, '%Y-%m-%d') as date
+		FROM dates
+		ORDER BY date ASC
+	`
+}
+
+func (mysqlDialect) ForUpdate() string {
+	return " FOR UPDATE"
+}
+
+func (mysqlDialect) Upsert(keys []string, columns []string) string {
+	updates := make([]string, len(columns))
+	for i, column := range columns {
+		updates[i] = fmt.Sprintf("%s = VALUES(%s)", column, column)
+	}
+	return "ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+}
+
+func (mysqlDialect) FullTextSearch(prefixes []string) (string, []interface{}, bool) {
+	terms := make([]string, len(prefixes))
+	for i, prefix := range prefixes {
+		terms[i] = prefix + "*"
+	}
+	against := strings.Join(terms, " ")
+
+	query := `
+		SELECT ` + foodColumns + `
+		FROM foods
+		WHERE MATCH(description, brand_owner) AGAINST (? IN BOOLEAN MODE)
+		ORDER BY MATCH(description, brand_owner) AGAINST (? IN BOOLEAN MODE) DESC
+		LIMIT ?
+	`
+	return query, []interface{}{against, against}, true
+}
+
+func (mysqlDialect) InsertReturningID(ctx context.Context, q sqlx.ExtContext, query string, args ...interface{}) (int64, error) {
+	return insertLastID(ctx, q, query, args...)
+}
+
+// sqliteDialect targets mattn/go-sqlite3, which stores times as text such as
+// "2025-05-16 15:00:00+03:00". Taking the leading date keeps the wall-clock
+// day, as MySQL does, instead of converting to UTC like SQLite's date().
+type sqliteDialect struct{}
+
+func (sqliteDialect) Date(expr string) string {
+	return fmt.Sprintf("substr(%s, 1, 10)", expr)
+}
+
+func (sqliteDialect) DateString(expr string) string {
+	return fmt.Sprintf("substr(%s, 1, 10)", expr)
+}
+
+func (sqliteDialect) DateSeries() string {
+	return `
+		WITH RECURSIVE dates(date) AS (
+			SELECT substr(?, 1, 10)
+			UNION ALL
+			SELECT date(date, '+1 day')
+			FROM dates
+			WHERE date < substr(?, 1, 10)
+		)
+		SELECT date
+		FROM dates
+		ORDER BY date ASC
+	`
+}
+
+// SQLite serialises writers on the whole database, so no row locks are needed.
+func (sqliteDialect) ForUpdate() string {
+	return ""
+}
+
+func (sqliteDialect) Upsert(keys []string, columns []string) string {
+	return onConflictUpdate(keys, columns)
+}
+
+func (sqliteDialect) FullTextSearch(prefixes []string) (string, []interface{}, bool) {
+	return "", nil, false
+}
+
+func (sqliteDialect) InsertReturningID(ctx context.Context, q sqlx.ExtContext, query string, args ...interface{}) (int64, error) {
+	return insertLastID(ctx, q, query, args...)
+}
+
+// postgresDialect targets PostgreSQL through the pgx stdlib driver. Queries
+// are written with ? placeholders and rebound to $n before they are sent.
+type postgresDialect struct{}
+
+func (postgresDialect) Date(expr string) string {
+	return fmt.Sprintf("CAST(%s AS DATE)", expr)
+}
+
+func (postgresDialect) DateString(expr string) string {
+	return fmt.Sprintf("to_char(%s, 'YYYY-MM-DD')", expr)
+}
+
+func (postgresDialect) DateSeries() string {
+	return `
+		SELECT to_char(day, 'YYYY-MM-DD') as date
+		FROM generate_series(CAST(? AS DATE), CAST(? AS DATE), INTERVAL '1 day') AS day
+		ORDER BY day ASC
+	`
+}
+
+func (postgresDialect) ForUpdate() string {
+	return " FOR UPDATE"
+}
+
+func (postgresDialect) Upsert(keys []string, columns []string) string {
+	return onConflictUpdate(keys, columns)
+}
+
+// FullTextSearch matches prefixes against the idx_foods_search GIN index. The
+// simple configuration skips stemming, which would not apply to prefixes.
+func (postgresDialect) FullTextSearch(prefixes []string) (string, []interface{}, bool) {
+	lexemes := make([]string, len(prefixes))
+	for i, prefix := range prefixes {
+		lexemes[i] = "'" + strings.ReplaceAll(prefix, "'", "''") + "':*"
+	}
+	tsquery := strings.Join(lexemes, " | ")
+
+	query := `
+		SELECT ` + foodColumns + `
+		FROM foods
+		WHERE to_tsvector('simple', description || ' ' || brand_owner) @@ to_tsquery('simple', ?)
+		ORDER BY ts_rank(to_tsvector('simple', description || ' ' || brand_owner), to_tsquery('simple', ?)) DESC
+		LIMIT ?
+	`
+	return query, []interface{}{tsquery, tsquery}, true
+}
+
+func (postgresDialect) InsertReturningID(ctx context.Context, q sqlx.ExtContext, query string, args ...interface{}) (int64, error) {
+	var id int64
+	err := q.QueryRowxContext(ctx, q.Rebind(query+" RETURNING id"), args...).Scan(&id)
+	return id, err
+}
+
+// onConflictUpdate builds the upsert clause shared by SQLite and PostgreSQL.
+func onConflictUpdate(keys []string, columns []string) string {
+	updates := make([]string, len(columns))
+	for i, column := range columns {
+		updates[i] = fmt.Sprintf("%s = excluded.%s", column, column)
+	}
+	return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(keys, ", "), strings.Join(updates, ", "))
+}
+
+func insertLastID(ctx context.Context, q sqlx.ExtContext, query string, args ...interface{}) (int64, error) {
+	result, err := q.ExecContext(ctx, q.Rebind(query), args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
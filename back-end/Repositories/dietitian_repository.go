@@ -0,0 +1,305 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	models "HabitBite/backend/Models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrApplicationNotFound     = errors.New("dietitian application not found")
+	ErrApplicationPending      = errors.New("a dietitian application is already pending")
+	ErrApplicationNotPending   = errors.New("dietitian application has already been reviewed")
+	ErrDietitianProfileMissing = errors.New("dietitian profile not found")
+)
+
+// DietitianRepository stores dietitian applications and the public profiles
+// created when they are approved.
+type DietitianRepository interface {
+	// CreateApplication fails with ErrApplicationPending if the user already
+	// has an application waiting for review.
+	CreateApplication(ctx context.Context, application *models.DietitianApplication) error
+	GetApplication(ctx context.Context, id int) (*models.DietitianApplication, error)
+	// GetApplications returns the applications with the given status, or all
+	// of them if status is empty, newest first.
+	GetApplications(ctx context.Context, status string) ([]models.DietitianApplication, error)
+	GetUserApplications(ctx context.Context, userID int) ([]models.DietitianApplication, error)
+	// ApproveApplication marks a pending application approved, makes the
+	// applicant a dietitian and creates or replaces their profile.
+	ApproveApplication(ctx context.Context, id int, reviewerID int, reason string) (*models.DietitianApplication, error)
+	RejectApplication(ctx context.Context, id int, reviewerID int, reason string) (*models.DietitianApplication, error)
+
+	GetProfile(ctx context.Context, dietitianID int) (*models.DietitianProfile, error)
+	// GetProfiles returns the profiles of the given dietitians keyed by user
+	// ID. Dietitians without a profile are left out.
+	GetProfiles(ctx context.Context, dietitianIDs []int) (map[int]models.DietitianProfile, error)
+	SaveProfile(ctx context.Context, profile *models.DietitianProfile) error
+}
+
+type dietitianRepository struct {
+	db      *sqlx.DB
+	dialect dialect
+}
+
+func NewDietitianRepository(db *sqlx.DB) DietitianRepository {
+	return &dietitianRepository{db: db, dialect: dialectFor(db)}
+}
+
+const applicationColumns = `
+	a.*, u.full_name AS applicant_name, u.email AS applicant_email
+	FROM dietitian_applications a
+	JOIN users u ON u.id = a.user_id
+`
+
+func (r *dietitianRepository) CreateApplication(ctx context.Context, application *models.DietitianApplication) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	var pending int
+	err = tx.GetContext(ctx, &pending,
+		tx.Rebind(`SELECT COUNT(*) FROM dietitian_applications WHERE user_id = ? AND status = ?`),
+		application.UserID, models.ApplicationPending)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	if pending > 0 {
+		return ErrApplicationPending
+	}
+
+	application.Status = models.ApplicationPending
+	application.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO dietitian_applications (
+			user_id, credentials, license_number, bio, status, created_at
+		) VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	id, err := r.dialect.InsertReturningID(ctx, tx, query,
+		application.UserID, application.Credentials, application.LicenseNumber,
+		application.Bio, application.Status, application.CreatedAt)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	application.ID = int(id)
+
+	if err = tx.Commit(); err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	return nil
+}
+
+func (r *dietitianRepository) GetApplication(ctx context.Context, id int) (*models.DietitianApplication, error) {
+	var application models.DietitianApplication
+	err := r.db.GetContext(ctx, &application, r.db.Rebind(`SELECT `+applicationColumns+` WHERE a.id = ?`), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrApplicationNotFound
+		}
+		return nil, wrapDatabaseError(err)
+	}
+
+	return &application, nil
+}
+
+func (r *dietitianRepository) GetApplications(ctx context.Context, status string) ([]models.DietitianApplication, error) {
+	query := `SELECT ` + applicationColumns + ` ORDER BY a.id DESC`
+	args := []interface{}{}
+	if status != "" {
+		query = `SELECT ` + applicationColumns + ` WHERE a.status = ? ORDER BY a.id DESC`
+		args = append(args, status)
+	}
+
+	applications := []models.DietitianApplication{}
+	if err := r.db.SelectContext(ctx, &applications, r.db.Rebind(query), args...); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	return applications, nil
+}
+
+func (r *dietitianRepository) GetUserApplications(ctx context.Context, userID int) ([]models.DietitianApplication, error) {
+	query := `SELECT * FROM dietitian_applications WHERE user_id = ? ORDER BY id DESC`
+
+	applications := []models.DietitianApplication{}
+	if err := r.db.SelectContext(ctx, &applications, r.db.Rebind(query), userID); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	return applications, nil
+}
+
+func (r *dietitianRepository) ApproveApplication(ctx context.Context, id int, reviewerID int, reason string) (*models.DietitianApplication, error) {
+	return r.review(ctx, id, reviewerID, reason, models.ApplicationApproved)
+}
+
+func (r *dietitianRepository) RejectApplication(ctx context.Context, id int, reviewerID int, reason string) (*models.DietitianApplication, error) {
+	return r.review(ctx, id, reviewerID, reason, models.ApplicationRejected)
+}
+
+// review moves a pending application to status in one transaction, so two
+// admins reviewing at once cannot both succeed.
+func (r *dietitianRepository) review(ctx context.Context, id int, reviewerID int, reason string, status string) (*models.DietitianApplication, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	var application models.DietitianApplication
+	err = tx.GetContext(ctx, &application,
+		tx.Rebind(`SELECT * FROM dietitian_applications WHERE id = ?`+r.dialect.ForUpdate()), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrApplicationNotFound
+		}
+		return nil, wrapDatabaseError(err)
+	}
+	if application.Status != models.ApplicationPending {
+		return nil, ErrApplicationNotPending
+	}
+
+	now := time.Now()
+	application.Status = status
+	application.ReviewedBy = &reviewerID
+	application.ReviewedAt = &now
+	application.ReviewReason = nil
+	if reason != "" {
+		application.ReviewReason = &reason
+	}
+
+	updateQuery := `
+		UPDATE dietitian_applications
+		SET status = ?, reviewed_by = ?, review_reason = ?, reviewed_at = ?
+		WHERE id = ?
+	`
+	_, err = tx.ExecContext(ctx, tx.Rebind(updateQuery),
+		application.Status, application.ReviewedBy, application.ReviewReason, application.ReviewedAt, id)
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	if status == models.ApplicationApproved {
+		var fullName string
+		err = tx.GetContext(ctx, &fullName, tx.Rebind(`SELECT full_name FROM users WHERE id = ?`), application.UserID)
+		if err != nil {
+			return nil, wrapDatabaseError(err)
+		}
+
+		_, err = tx.ExecContext(ctx, tx.Rebind(`UPDATE users SET role = ?, updated_at = ? WHERE id = ?`),
+			models.RoleDietitian, now, application.UserID)
+		if err != nil {
+			return nil, wrapDatabaseError(err)
+		}
+
+		profile := &models.DietitianProfile{
+			UserID:        application.UserID,
+			DisplayName:   fullName,
+			Credentials:   application.Credentials,
+			LicenseNumber: application.LicenseNumber,
+			Bio:           application.Bio,
+		}
+		if err := r.saveProfile(ctx, tx, profile); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	return &application, nil
+}
+
+func (r *dietitianRepository) GetProfile(ctx context.Context, dietitianID int) (*models.DietitianProfile, error) {
+	var profile models.DietitianProfile
+	err := r.db.GetContext(ctx, &profile, r.db.Rebind(`SELECT * FROM dietitian_profiles WHERE user_id = ?`), dietitianID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDietitianProfileMissing
+		}
+		return nil, wrapDatabaseError(err)
+	}
+
+	return &profile, nil
+}
+
+func (r *dietitianRepository) GetProfiles(ctx context.Context, dietitianIDs []int) (map[int]models.DietitianProfile, error) {
+	byUser := make(map[int]models.DietitianProfile, len(dietitianIDs))
+	if len(dietitianIDs) == 0 {
+		return byUser, nil
+	}
+
+	query, args, err := sqlx.In(`SELECT * FROM dietitian_profiles WHERE user_id IN (?)`, dietitianIDs)
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	var profiles []models.DietitianProfile
+	if err := r.db.SelectContext(ctx, &profiles, r.db.Rebind(query), args...); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	for _, profile := range profiles {
+		byUser[profile.UserID] = profile
+	}
+	return byUser, nil
+}
+
+func (r *dietitianRepository) SaveProfile(ctx context.Context, profile *models.DietitianProfile) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	if err := r.saveProfile(ctx, tx, profile); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	return nil
+}
+
+// saveProfile inserts or replaces a profile, keeping the original creation
+// time.
+func (r *dietitianRepository) saveProfile(ctx context.Context, tx *sqlx.Tx, profile *models.DietitianProfile) error {
+	now := time.Now()
+	profile.UpdatedAt = now
+
+	query := `
+		INSERT INTO dietitian_profiles (
+			user_id, display_name, credentials, license_number, bio, client_capacity,
+			created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	` + r.dialect.Upsert(
+		[]string{"user_id"},
+		[]string{"display_name", "credentials", "license_number", "bio", "client_capacity", "updated_at"},
+	)
+
+	_, err := tx.ExecContext(ctx, tx.Rebind(query),
+		profile.UserID, profile.DisplayName, profile.Credentials, profile.LicenseNumber,
+		profile.Bio, profile.ClientCapacity, now, now)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	err = tx.GetContext(ctx, &profile.CreatedAt,
+		tx.Rebind(`SELECT created_at FROM dietitian_profiles WHERE user_id = ?`), profile.UserID)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,20 +12,41 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+var (
+	ErrFoodEntryNotFound  = errors.New("food entry not found")
+	ErrFoodEntryForbidden = errors.New("food entry belongs to another user")
+)
+
+// Every method is scoped to the owning user. Looking up an entry that exists
+// but belongs to someone else returns ErrFoodEntryForbidden, so callers can
+// tell it apart from an entry that does not exist at all.
+
 type FoodEntryRepository interface {
 	CreateFoodEntry(ctx context.Context, entry *models.FoodEntry) error
+	GetFoodEntry(ctx context.Context, userID, entryID int) (*models.FoodEntry, error)
 	GetDailyEntries(ctx context.Context, userID int, date time.Time) ([]*models.FoodEntry, error)
-	DeleteFoodEntry(ctx context.Context, entryID int) error
+	UpdateFoodEntry(ctx context.Context, entry *models.FoodEntry) error
+	DeleteFoodEntry(ctx context.Context, userID, entryID int) error
 	GetDailyNutrition(ctx context.Context, userID int, date time.Time) (*models.DailyNutrition, error)
 	GetNutritionHistory(ctx context.Context, userID int, startDate, endDate time.Time) ([]*models.DailyNutrition, error)
+	// GetEntriesByDay returns the entries from startDate to endDate, both
+	// included, keyed by their YYYY-MM-DD date.
+	GetEntriesByDay(ctx context.Context, userID int, startDate, endDate time.Time) (map[string][]*models.FoodEntry, error)
+	// GetDailyTotalsForUsers returns the daily_entries rows of the users from
+	// startDate to endDate, both included, ordered by user and date.
+	GetDailyTotalsForUsers(ctx context.Context, userIDs []int, startDate, endDate time.Time) ([]models.DailyTotals, error)
+	// GetLastLogDates returns the latest day with entries, up to endDate, of
+	// each user that has any.
+	GetLastLogDates(ctx context.Context, userIDs []int, endDate time.Time) (map[int]time.Time, error)
 }
 
 type foodEntryRepository struct {
-	db *sqlx.DB
+	db      *sqlx.DB
+	dialect dialect
 }
 
 func NewFoodEntryRepository(db *sqlx.DB) FoodEntryRepository {
-	return &foodEntryRepository{db: db}
+	return &foodEntryRepository{db: db, dialect: dialectFor(db)}
 }
 
 func (r *foodEntryRepository) CreateFoodEntry(ctx context.Context, entry *models.FoodEntry) error {
@@ -38,12 +60,12 @@ func (r *foodEntryRepository) CreateFoodEntry(ctx context.Context, entry *models
 	query := `
 		INSERT INTO consumed_foods (
 			user_id, food_id, food_name, quantity, calories, protein, carbs, fats,
-			entry_date, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			meal, entry_date, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	now := time.Now()
-	result, err := tx.ExecContext(ctx, query,
+	id, err := r.dialect.InsertReturningID(ctx, tx, query,
 		entry.UserID,
 		entry.FoodID,
 		entry.Name,
@@ -52,6 +74,7 @@ func (r *foodEntryRepository) CreateFoodEntry(ctx context.Context, entry *models
 		entry.Protein,
 		entry.Carbs,
 		entry.Fat,
+		entry.Meal,
 		entry.Date,
 		now,
 		now,
@@ -59,84 +82,12 @@ func (r *foodEntryRepository) CreateFoodEntry(ctx context.Context, entry *models
 	if err != nil {
 		return fmt.Errorf("failed to insert food entry: %v", err)
 	}
-
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("failed to get last insert ID: %v", err)
-	}
 	entry.ID = int(id)
+	entry.CreatedAt = now
+	entry.UpdatedAt = now
 
-	entryDate := time.Date(entry.Date.Year(), entry.Date.Month(), entry.Date.Day(), 0, 0, 0, 0, time.UTC)
-
-	nutritionQuery := `
-		SELECT 
-			IFNULL(SUM(calories), 0) as total_calories,
-			IFNULL(SUM(protein), 0) as total_protein,
-			IFNULL(SUM(carbs), 0) as total_carbs,
-			IFNULL(SUM(fats), 0) as total_fats
-		FROM consumed_foods
-		WHERE user_id = ? AND DATE(entry_date) = DATE(?)
-	`
-
-	var totalCalories, totalProtein, totalCarbs, totalFats float64
-	err = tx.QueryRowContext(ctx, nutritionQuery, entry.UserID, entryDate).Scan(
-		&totalCalories,
-		&totalProtein,
-		&totalCarbs,
-		&totalFats,
-	)
-	if err != nil && err != sql.ErrNoRows {
-		return fmt.Errorf("failed to calculate daily totals: %v", err)
-	}
-
-	var entryID int
-	checkQuery := `
-		SELECT id FROM daily_entries
-		WHERE user_id = ? AND entry_date = DATE(?)
-	`
-
-	err = tx.QueryRowContext(ctx, checkQuery, entry.UserID, entryDate).Scan(&entryID)
-
-	if err == sql.ErrNoRows {
-		insertQuery := `
-			INSERT INTO daily_entries (
-				user_id, entry_date, total_calories, total_protein, total_carbs, total_fats
-			) VALUES (?, ?, ?, ?, ?, ?)
-		`
-
-		_, err = tx.ExecContext(ctx, insertQuery,
-			entry.UserID,
-			entryDate,
-			totalCalories,
-			totalProtein,
-			totalCarbs,
-			totalFats,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to insert daily entry: %v", err)
-		}
-	} else if err == nil {
-		updateQuery := `
-			UPDATE daily_entries SET
-				total_calories = ?,
-				total_protein = ?,
-				total_carbs = ?,
-				total_fats = ?
-			WHERE id = ?
-		`
-
-		_, err = tx.ExecContext(ctx, updateQuery,
-			totalCalories,
-			totalProtein,
-			totalCarbs,
-			totalFats,
-			entryID,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to update daily entry: %v", err)
-		}
-	} else {
-		return fmt.Errorf("failed to check for existing daily entry: %v", err)
+	if err := r.recalculateDailyTotals(ctx, tx, entry.UserID, entry.Date); err != nil {
+		return err
 	}
 
 	if err = tx.Commit(); err != nil {
@@ -148,15 +99,15 @@ func (r *foodEntryRepository) CreateFoodEntry(ctx context.Context, entry *models
 
 func (r *foodEntryRepository) GetDailyEntries(ctx context.Context, userID int, date time.Time) ([]*models.FoodEntry, error) {
 
-	query := `
+	query := fmt.Sprintf(`
 		SELECT id, user_id, food_id, food_name, quantity, calories, protein, carbs, fats,
-			   entry_date, created_at, updated_at
+			   meal, entry_date, created_at, updated_at
 		FROM consumed_foods
-		WHERE user_id = ? AND DATE(entry_date) = DATE(?)
+		WHERE user_id = ? AND %s = %s
 		ORDER BY entry_date DESC
-	`
+	`, r.dialect.Date("entry_date"), r.dialect.Date("?"))
 
-	rows, err := r.db.QueryContext(ctx, query, userID, date)
+	rows, err := r.db.QueryContext(ctx, r.db.Rebind(query), userID, date)
 	if err != nil {
 		return nil, fmt.Errorf("database query error: %v", err)
 	}
@@ -173,13 +124,13 @@ func (r *foodEntryRepository) GetDailyEntries(ctx context.Context, userID int, d
 
 		query = `
 			SELECT id, user_id, food_id, food_name, quantity, calories, protein, carbs, fats,
-				   entry_date, created_at, updated_at
+				   meal, entry_date, created_at, updated_at
 			FROM consumed_foods
 			WHERE user_id = ? AND entry_date BETWEEN ? AND ?
 			ORDER BY entry_date DESC
 		`
 
-		rows, err = r.db.QueryContext(ctx, query, userID, startOfDay, endOfDay)
+		rows, err = r.db.QueryContext(ctx, r.db.Rebind(query), userID, startOfDay, endOfDay)
 		if err != nil {
 			fmt.Printf("[ERROR GetDailyEntries] Alternative query error: %v\n", err)
 			return nil, fmt.Errorf("database alternative query error: %v", err)
@@ -193,6 +144,32 @@ func (r *foodEntryRepository) GetDailyEntries(ctx context.Context, userID int, d
 	return entries, nil
 }
 
+func (r *foodEntryRepository) GetEntriesByDay(ctx context.Context, userID int, startDate, endDate time.Time) (map[string][]*models.FoodEntry, error) {
+	query := fmt.Sprintf(`
+		SELECT id, user_id, food_id, food_name, quantity, calories, protein, carbs, fats,
+			   meal, entry_date, created_at, updated_at, %s AS entry_day
+		FROM consumed_foods
+		WHERE user_id = ? AND %s BETWEEN ? AND ?
+		ORDER BY entry_date ASC
+	`, r.dialect.DateString("entry_date"), r.dialect.Date("entry_date"))
+
+	var rows []struct {
+		models.FoodEntry
+		Day string `db:"entry_day"`
+	}
+	err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query),
+		userID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("database query error: %v", err)
+	}
+
+	byDay := make(map[string][]*models.FoodEntry)
+	for i := range rows {
+		byDay[rows[i].Day] = append(byDay[rows[i].Day], &rows[i].FoodEntry)
+	}
+	return byDay, nil
+}
+
 func scanEntries(rows *sql.Rows) ([]*models.FoodEntry, error) {
 	defer rows.Close()
 
@@ -209,6 +186,7 @@ func scanEntries(rows *sql.Rows) ([]*models.FoodEntry, error) {
 			&entry.Protein,
 			&entry.Carbs,
 			&entry.Fat,
+			&entry.Meal,
 			&entry.Date,
 			&entry.CreatedAt,
 			&entry.UpdatedAt,
@@ -226,7 +204,37 @@ func scanEntries(rows *sql.Rows) ([]*models.FoodEntry, error) {
 	return entries, nil
 }
 
-func (r *foodEntryRepository) DeleteFoodEntry(ctx context.Context, entryID int) error {
+func (r *foodEntryRepository) GetFoodEntry(ctx context.Context, userID, entryID int) (*models.FoodEntry, error) {
+	query := `
+		SELECT id, user_id, food_id, food_name, quantity, calories, protein, carbs, fats,
+			   meal, entry_date, created_at, updated_at
+		FROM consumed_foods
+		WHERE id = ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, r.db.Rebind(query), entryID)
+	if err != nil {
+		return nil, fmt.Errorf("database query error: %v", err)
+	}
+
+	entries, err := scanEntries(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrFoodEntryNotFound
+	}
+	if entries[0].UserID != userID {
+		return nil, ErrFoodEntryForbidden
+	}
+
+	return entries[0], nil
+}
+
+// UpdateFoodEntry stores the new values of an existing entry. The daily
+// rollups of both the previous and the new day are rebuilt in the same
+// transaction, so moving an entry between days keeps both totals correct.
+func (r *foodEntryRepository) UpdateFoodEntry(ctx context.Context, entry *models.FoodEntry) error {
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %v", err)
@@ -234,38 +242,132 @@ func (r *foodEntryRepository) DeleteFoodEntry(ctx context.Context, entryID int)
 
 	defer tx.Rollback()
 
-	query := `SELECT user_id, entry_date FROM consumed_foods WHERE id = ?`
-	var userID int
-	var entryDate time.Time
+	previousDate, err := r.lockOwnedEntry(ctx, tx, entry.UserID, entry.ID)
+	if err != nil {
+		return err
+	}
+
+	entry.UpdatedAt = time.Now()
+
+	updateQuery := `
+		UPDATE consumed_foods SET
+			food_id = ?,
+			food_name = ?,
+			quantity = ?,
+			calories = ?,
+			protein = ?,
+			carbs = ?,
+			fats = ?,
+			meal = ?,
+			entry_date = ?,
+			updated_at = ?
+		WHERE id = ? AND user_id = ?
+	`
 
-	err = tx.QueryRowContext(ctx, query, entryID).Scan(&userID, &entryDate)
+	_, err = tx.ExecContext(ctx, tx.Rebind(updateQuery),
+		entry.FoodID,
+		entry.Name,
+		entry.Amount,
+		entry.Calories,
+		entry.Protein,
+		entry.Carbs,
+		entry.Fat,
+		entry.Meal,
+		entry.Date,
+		entry.UpdatedAt,
+		entry.ID,
+		entry.UserID,
+	)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("food entry not found")
+		return fmt.Errorf("failed to update food entry: %v", err)
+	}
+
+	if err := r.recalculateDailyTotals(ctx, tx, entry.UserID, previousDate); err != nil {
+		return err
+	}
+	if !sameDay(previousDate, entry.Date) {
+		if err := r.recalculateDailyTotals(ctx, tx, entry.UserID, entry.Date); err != nil {
+			return err
 		}
-		return fmt.Errorf("failed to fetch food entry: %v", err)
 	}
 
-	deleteQuery := `DELETE FROM consumed_foods WHERE id = ?`
-	_, err = tx.ExecContext(ctx, deleteQuery, entryID)
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return nil
+}
+
+func (r *foodEntryRepository) DeleteFoodEntry(ctx context.Context, userID, entryID int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+
+	defer tx.Rollback()
+
+	entryDate, err := r.lockOwnedEntry(ctx, tx, userID, entryID)
+	if err != nil {
+		return err
+	}
+
+	deleteQuery := `DELETE FROM consumed_foods WHERE id = ? AND user_id = ?`
+	_, err = tx.ExecContext(ctx, tx.Rebind(deleteQuery), entryID, userID)
 	if err != nil {
 		return fmt.Errorf("failed to delete food entry: %v", err)
 	}
 
-	dateOnly := time.Date(entryDate.Year(), entryDate.Month(), entryDate.Day(), 0, 0, 0, 0, time.UTC)
+	if err := r.recalculateDailyTotals(ctx, tx, userID, entryDate); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return nil
+}
+
+// lockOwnedEntry locks the entry for the rest of the transaction and returns
+// its current date, after checking that it belongs to userID.
+func (r *foodEntryRepository) lockOwnedEntry(ctx context.Context, tx *sqlx.Tx, userID, entryID int) (time.Time, error) {
+	var ownerID int
+	var entryDate time.Time
+
+	err := tx.QueryRowContext(ctx,
+		tx.Rebind(`SELECT user_id, entry_date FROM consumed_foods WHERE id = ?`+r.dialect.ForUpdate()),
+		entryID,
+	).Scan(&ownerID, &entryDate)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, ErrFoodEntryNotFound
+		}
+		return time.Time{}, fmt.Errorf("failed to fetch food entry: %v", err)
+	}
+	if ownerID != userID {
+		return time.Time{}, ErrFoodEntryForbidden
+	}
+
+	return entryDate, nil
+}
+
+// recalculateDailyTotals rebuilds the daily_entries rollup of one user and
+// day from consumed_foods, removing the row once the day has no entries left.
+func (r *foodEntryRepository) recalculateDailyTotals(ctx context.Context, tx *sqlx.Tx, userID int, date time.Time) error {
+	dateOnly := date.Format("2006-01-02")
 
-	nutritionQuery := `
+	nutritionQuery := fmt.Sprintf(`
 		SELECT 
-			IFNULL(SUM(calories), 0) as total_calories,
-			IFNULL(SUM(protein), 0) as total_protein,
-			IFNULL(SUM(carbs), 0) as total_carbs,
-			IFNULL(SUM(fats), 0) as total_fats
+			COALESCE(SUM(calories), 0) as total_calories,
+			COALESCE(SUM(protein), 0) as total_protein,
+			COALESCE(SUM(carbs), 0) as total_carbs,
+			COALESCE(SUM(fats), 0) as total_fats
 		FROM consumed_foods
-		WHERE user_id = ? AND DATE(entry_date) = DATE(?)
-	`
+		WHERE user_id = ? AND %s = ?
+	`, r.dialect.Date("entry_date"))
 
 	var totalCalories, totalProtein, totalCarbs, totalFats float64
-	err = tx.QueryRowContext(ctx, nutritionQuery, userID, dateOnly).Scan(
+	err := tx.QueryRowContext(ctx, tx.Rebind(nutritionQuery), userID, dateOnly).Scan(
 		&totalCalories,
 		&totalProtein,
 		&totalCarbs,
@@ -274,114 +376,136 @@ func (r *foodEntryRepository) DeleteFoodEntry(ctx context.Context, entryID int)
 	if err != nil && err != sql.ErrNoRows {
 		return fmt.Errorf("failed to calculate daily totals: %v", err)
 	}
+	hasTotals := totalCalories > 0 || totalProtein > 0 || totalCarbs > 0 || totalFats > 0
 
 	var dailyEntryID int
 	checkQuery := `
 		SELECT id FROM daily_entries
-		WHERE user_id = ? AND entry_date = DATE(?)
+		WHERE user_id = ? AND entry_date = ?
 	`
 
-	err = tx.QueryRowContext(ctx, checkQuery, userID, dateOnly).Scan(&dailyEntryID)
-
-	if err == sql.ErrNoRows {
-		if totalCalories > 0 || totalProtein > 0 || totalCarbs > 0 || totalFats > 0 {
-			insertQuery := `
-				INSERT INTO daily_entries (
-					user_id, entry_date, total_calories, total_protein, total_carbs, total_fats
-				) VALUES (?, ?, ?, ?, ?, ?)
-			`
-
-			_, err = tx.ExecContext(ctx, insertQuery,
-				userID,
-				dateOnly,
-				totalCalories,
-				totalProtein,
-				totalCarbs,
-				totalFats,
-			)
-			if err != nil {
-				return fmt.Errorf("failed to insert daily entry: %v", err)
-			}
+	err = tx.QueryRowContext(ctx, tx.Rebind(checkQuery), userID, dateOnly).Scan(&dailyEntryID)
+
+	switch {
+	case err == sql.ErrNoRows:
+		if !hasTotals {
+			return nil
 		}
-	} else if err == nil {
-		if totalCalories > 0 || totalProtein > 0 || totalCarbs > 0 || totalFats > 0 {
-			updateQuery := `
-				UPDATE daily_entries SET
-					total_calories = ?,
-					total_protein = ?,
-					total_carbs = ?,
-					total_fats = ?
-				WHERE id = ?
-			`
-
-			_, err = tx.ExecContext(ctx, updateQuery,
-				totalCalories,
-				totalProtein,
-				totalCarbs,
-				totalFats,
-				dailyEntryID,
-			)
-			if err != nil {
-				return fmt.Errorf("failed to update daily entry: %v", err)
-			}
-		} else {
-			deleteEntryQuery := `DELETE FROM daily_entries WHERE id = ?`
-			_, err = tx.ExecContext(ctx, deleteEntryQuery, dailyEntryID)
+
+		insertQuery := `
+			INSERT INTO daily_entries (
+				user_id, entry_date, total_calories, total_protein, total_carbs, total_fats
+			) VALUES (?, ?, ?, ?, ?, ?)
+		`
+
+		_, err = tx.ExecContext(ctx, tx.Rebind(insertQuery),
+			userID,
+			dateOnly,
+			totalCalories,
+			totalProtein,
+			totalCarbs,
+			totalFats,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert daily entry: %v", err)
+		}
+	case err == nil:
+		if !hasTotals {
+			_, err = tx.ExecContext(ctx, tx.Rebind(`DELETE FROM daily_entries WHERE id = ?`), dailyEntryID)
 			if err != nil {
 				return fmt.Errorf("failed to delete daily entry: %v", err)
 			}
+			return nil
 		}
-	} else {
-		return fmt.Errorf("failed to check for existing daily entry: %v", err)
-	}
 
-	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %v", err)
+		updateQuery := `
+			UPDATE daily_entries SET
+				total_calories = ?,
+				total_protein = ?,
+				total_carbs = ?,
+				total_fats = ?
+			WHERE id = ?
+		`
+
+		_, err = tx.ExecContext(ctx, tx.Rebind(updateQuery),
+			totalCalories,
+			totalProtein,
+			totalCarbs,
+			totalFats,
+			dailyEntryID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update daily entry: %v", err)
+		}
+	default:
+		return fmt.Errorf("failed to check for existing daily entry: %v", err)
 	}
 
 	return nil
 }
 
+func sameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.Month() == b.Month() && a.Day() == b.Day()
+}
+
+// GetDailyNutrition returns the day total together with a subtotal for every
+// meal slot, in models.MealTypes order.
 func (r *foodEntryRepository) GetDailyNutrition(ctx context.Context, userID int, date time.Time) (*models.DailyNutrition, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT 
-			IFNULL(SUM(calories), 0) as total_calories,
-			IFNULL(SUM(protein), 0) as total_protein,
-			IFNULL(SUM(carbs), 0) as total_carbs,
-			IFNULL(SUM(fats), 0) as total_fats
+			meal,
+			COALESCE(SUM(calories), 0) as total_calories,
+			COALESCE(SUM(protein), 0) as total_protein,
+			COALESCE(SUM(carbs), 0) as total_carbs,
+			COALESCE(SUM(fats), 0) as total_fats
 		FROM consumed_foods
-		WHERE user_id = ? AND DATE(entry_date) = DATE(?)
-	`
+		WHERE user_id = ? AND %s = %s
+		GROUP BY meal
+	`, r.dialect.Date("entry_date"), r.dialect.Date("?"))
 
-	var nutrition models.DailyNutrition
-	err := r.db.QueryRowContext(ctx, query, userID, date).Scan(
-		&nutrition.TotalCalories,
-		&nutrition.TotalProtein,
-		&nutrition.TotalCarbs,
-		&nutrition.TotalFats,
-	)
-	if err != nil && err != sql.ErrNoRows {
+	rows, err := r.db.QueryContext(ctx, r.db.Rebind(query), userID, date)
+	if err != nil {
 		return nil, fmt.Errorf("failed to get daily nutrition: %v", err)
 	}
+	defer rows.Close()
+
+	byMeal := make(map[string]*models.MealNutrition)
+	for rows.Next() {
+		meal := &models.MealNutrition{}
+		if err := rows.Scan(
+			&meal.Meal,
+			&meal.TotalCalories,
+			&meal.TotalProtein,
+			&meal.TotalCarbs,
+			&meal.TotalFats,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan meal nutrition: %v", err)
+		}
+		byMeal[meal.Meal] = meal
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %v", err)
+	}
+
+	nutrition := &models.DailyNutrition{Date: date}
+	for _, mealType := range models.MealTypes {
+		meal, ok := byMeal[mealType]
+		if !ok {
+			meal = &models.MealNutrition{Meal: mealType}
+		}
+		nutrition.Meals = append(nutrition.Meals, meal)
+		nutrition.TotalCalories += meal.TotalCalories
+		nutrition.TotalProtein += meal.TotalProtein
+		nutrition.TotalCarbs += meal.TotalCarbs
+		nutrition.TotalFats += meal.TotalFats
+	}
 
-	nutrition.Date = date
-	return &nutrition, nil
+	return nutrition, nil
 }
 
 func (r *foodEntryRepository) GetNutritionHistory(ctx context.Context, userID int, startDate, endDate time.Time) ([]*models.DailyNutrition, error) {
-	datesQuery := `
-		WITH RECURSIVE dates(date) AS (
-			SELECT DATE(?)
-			UNION ALL
-			SELECT DATE_ADD(date, INTERVAL 1 DAY)
-			FROM dates
-			WHERE date < DATE(?)
-		)
-		SELECT DATE_FORMAT(date, '%Y-%m-%d') as date
-		FROM dates
-		ORDER BY date ASC
-	`
-	dateRows, err := r.db.QueryContext(ctx, datesQuery, startDate, endDate)
+	datesQuery := r.dialect.DateSeries()
+	dateRows, err := r.db.QueryContext(ctx, r.db.Rebind(datesQuery), startDate, endDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query date range: %v", err)
 	}
@@ -413,9 +537,9 @@ func (r *foodEntryRepository) GetNutritionHistory(ctx context.Context, userID in
 		fmt.Printf("[WARN GetNutritionHistory] Empty nutritionByDate map after date population. Date range: %s to %s\n",
 			startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
 	}
-	entriesQuery := `
+	entriesQuery := fmt.Sprintf(`
 		SELECT 
-			DATE_FORMAT(entry_date, '%Y-%m-%d') as date,
+			%s as date,
 			total_calories,
 			total_protein,
 			total_carbs,
@@ -423,9 +547,10 @@ func (r *foodEntryRepository) GetNutritionHistory(ctx context.Context, userID in
 		FROM daily_entries
 		WHERE user_id = ? AND entry_date BETWEEN ? AND ?
 		ORDER BY entry_date ASC
-	`
+	`, r.dialect.DateString("entry_date"))
 
-	entryRows, err := r.db.QueryContext(ctx, entriesQuery, userID, startDate, endDate)
+	entryRows, err := r.db.QueryContext(ctx, r.db.Rebind(entriesQuery), userID,
+		startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
 	if err != nil {
 		fmt.Printf("[ERROR GetNutritionHistory] Daily entries query error: %v\n", err)
 		return nil, fmt.Errorf("failed to query daily entries: %v", err)
@@ -455,19 +580,20 @@ func (r *foodEntryRepository) GetNutritionHistory(ctx context.Context, userID in
 		nutritionByDate[dateStr] = &nutrition
 	}
 
-	consumedFoodsRangeQuery := `
+	consumedFoodsRangeQuery := fmt.Sprintf(`
 		SELECT 
-			DATE_FORMAT(DATE(entry_date), '%Y-%m-%d') as date,
-			IFNULL(SUM(calories), 0) as total_calories,
-			IFNULL(SUM(protein), 0) as total_protein,
-			IFNULL(SUM(carbs), 0) as total_carbs,
-			IFNULL(SUM(fats), 0) as total_fats
+			%[1]s as date,
+			COALESCE(SUM(calories), 0) as total_calories,
+			COALESCE(SUM(protein), 0) as total_protein,
+			COALESCE(SUM(carbs), 0) as total_carbs,
+			COALESCE(SUM(fats), 0) as total_fats
 		FROM consumed_foods
-		WHERE user_id = ? AND DATE(entry_date) BETWEEN ? AND ?
-		GROUP BY DATE(entry_date)
-	`
+		WHERE user_id = ? AND %[2]s BETWEEN ? AND ?
+		GROUP BY %[1]s
+	`, r.dialect.DateString("entry_date"), r.dialect.Date("entry_date"))
 
-	consumedRows, err := r.db.QueryContext(ctx, consumedFoodsRangeQuery, userID, startDate, endDate)
+	consumedRows, err := r.db.QueryContext(ctx, r.db.Rebind(consumedFoodsRangeQuery), userID,
+		startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
 	if err != nil {
 		fmt.Printf("[ERROR GetNutritionHistory] Consumed foods range query error: %v\n", err)
 	} else {
@@ -500,20 +626,20 @@ func (r *foodEntryRepository) GetNutritionHistory(ctx context.Context, userID in
 	}
 
 	todayStr := time.Now().Format("2006-01-02")
-	consumedFoodsQuery := `
+	consumedFoodsQuery := fmt.Sprintf(`
 		SELECT 
-			DATE_FORMAT(DATE(entry_date), '%Y-%m-%d') as date,
-			IFNULL(SUM(calories), 0) as total_calories,
-			IFNULL(SUM(protein), 0) as total_protein,
-			IFNULL(SUM(carbs), 0) as total_carbs,
-			IFNULL(SUM(fats), 0) as total_fats
+			%[1]s as date,
+			COALESCE(SUM(calories), 0) as total_calories,
+			COALESCE(SUM(protein), 0) as total_protein,
+			COALESCE(SUM(carbs), 0) as total_carbs,
+			COALESCE(SUM(fats), 0) as total_fats
 		FROM consumed_foods
-		WHERE user_id = ? AND DATE(entry_date) = DATE(?)
-		GROUP BY DATE(entry_date)
-	`
+		WHERE user_id = ? AND %[2]s = ?
+		GROUP BY %[1]s
+	`, r.dialect.DateString("entry_date"), r.dialect.Date("entry_date"))
 
 	var todayNutrition models.DailyNutrition
-	err = r.db.QueryRowContext(ctx, consumedFoodsQuery, userID, todayStr).Scan(
+	err = r.db.QueryRowContext(ctx, r.db.Rebind(consumedFoodsQuery), userID, todayStr).Scan(
 		&todayStr,
 		&todayNutrition.TotalCalories,
 		&todayNutrition.TotalProtein,
@@ -544,3 +670,64 @@ func (r *foodEntryRepository) GetNutritionHistory(ctx context.Context, userID in
 
 	return history, nil
 }
+
+func (r *foodEntryRepository) GetDailyTotalsForUsers(ctx context.Context, userIDs []int, startDate, endDate time.Time) ([]models.DailyTotals, error) {
+	totals := []models.DailyTotals{}
+	if len(userIDs) == 0 {
+		return totals, nil
+	}
+
+	query, args, err := sqlx.In(fmt.Sprintf(`
+		SELECT
+			user_id,
+			%s AS day,
+			total_calories,
+			COALESCE(total_protein, 0) AS total_protein,
+			COALESCE(total_carbs, 0) AS total_carbs,
+			COALESCE(total_fats, 0) AS total_fats
+		FROM daily_entries
+		WHERE user_id IN (?) AND entry_date BETWEEN ? AND ?
+		ORDER BY user_id, entry_date
+	`, r.dialect.DateString("entry_date")), userIDs, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	if err := r.db.SelectContext(ctx, &totals, r.db.Rebind(query), args...); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	return totals, nil
+}
+
+func (r *foodEntryRepository) GetLastLogDates(ctx context.Context, userIDs []int, endDate time.Time) (map[int]time.Time, error) {
+	byUser := make(map[int]time.Time, len(userIDs))
+	if len(userIDs) == 0 {
+		return byUser, nil
+	}
+
+	query, args, err := sqlx.In(fmt.Sprintf(`
+		SELECT user_id, %s AS day
+		FROM daily_entries
+		WHERE user_id IN (?) AND entry_date <= ?
+		GROUP BY user_id
+	`, r.dialect.DateString("MAX(entry_date)")), userIDs, endDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	var rows []struct {
+		UserID int    `db:"user_id"`
+		Day    string `db:"day"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	for _, row := range rows {
+		day, err := time.Parse("2006-01-02", row.Day)
+		if err != nil {
+			return nil, wrapDatabaseError(err)
+		}
+		byUser[row.UserID] = day
+	}
+	return byUser, nil
+}
@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	models "HabitBite/backend/Models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrFoodNotFound = errors.New("food not found")
+
+type FoodRepository interface {
+	FindByFdcID(ctx context.Context, fdcID int) (*models.Food, error)
+	SearchFoods(ctx context.Context, query string, page, pageSize int) (*models.FoodSearchPage, error)
+	UpsertFoods(ctx context.Context, foods []*models.Food) error
+	CountFoods(ctx context.Context) (int, error)
+}
+
+type foodRepository struct {
+	db      *sqlx.DB
+	dialect dialect
+}
+
+func NewFoodRepository(db *sqlx.DB) FoodRepository {
+	return &foodRepository{db: db, dialect: dialectFor(db)}
+}
+
+func (r *foodRepository) FindByFdcID(ctx context.Context, fdcID int) (*models.Food, error) {
+	query := `SELECT * FROM foods WHERE fdc_id = ? LIMIT 1`
+	var food models.Food
+	err := r.db.GetContext(ctx, &food, r.db.Rebind(query), fdcID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrFoodNotFound
+		}
+		return nil, wrapDatabaseError(err)
+	}
+
+	return &food, nil
+}
+
+// SearchFoods returns one page of catalog foods matching the query together
+// with the total number of matches. Candidates come from the full-text index
+// using prefix terms; the final ranking and typo tolerance happen in rankFoods.
+func (r *foodRepository) SearchFoods(ctx context.Context, query string, page, pageSize int) (*models.FoodSearchPage, error) {
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return &models.FoodSearchPage{Foods: []*models.Food{}}, nil
+	}
+
+	prefixes := searchPrefixes(terms)
+	if len(prefixes) == 0 {
+		return &models.FoodSearchPage{Foods: []*models.Food{}}, nil
+	}
+
+	candidates, err := r.searchCandidates(ctx, prefixes)
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	return rankedPage(terms, candidates, page, pageSize), nil
+}
+
+// foodColumns lists the columns of foods in the order of models.Food.
+const foodColumns = `fdc_id, description, data_type, brand_owner, calories, protein, carbs, fats, created_at, updated_at`
+
+// likeEscaper escapes the LIKE wildcards of a search prefix for ESCAPE '!'.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// searchCandidates loads the foods matching any of the prefixes, best
+// full-text matches first. Without a full-text index the prefixes are matched
+// with LIKE, which is only meant for the small catalogs of development
+// databases.
+func (r *foodRepository) searchCandidates(ctx context.Context, prefixes []string) ([]*models.Food, error) {
+	var candidates []*models.Food
+
+	if query, args, ok := r.dialect.FullTextSearch(prefixes); ok {
+		args = append(args, SearchCandidateLimit)
+		err := r.db.SelectContext(ctx, &candidates, r.db.Rebind(query), args...)
+		return candidates, err
+	}
+
+	conditions := make([]string, 0, len(prefixes))
+	args := make([]interface{}, 0, len(prefixes)*2+1)
+	for _, prefix := range prefixes {
+		pattern := "%" + likeEscaper.Replace(prefix) + "%"
+		conditions = append(conditions, "LOWER(description) LIKE ? ESCAPE '!' OR LOWER(brand_owner) LIKE ? ESCAPE '!'")
+		args = append(args, pattern, pattern)
+	}
+	args = append(args, SearchCandidateLimit)
+
+	query := fmt.Sprintf(`
+		SELECT `+foodColumns+`
+		FROM foods
+		WHERE %s
+		ORDER BY fdc_id
+		LIMIT ?
+	`, strings.Join(conditions, " OR "))
+	err := r.db.SelectContext(ctx, &candidates, r.db.Rebind(query), args...)
+	return candidates, err
+}
+
+// UpsertFoods inserts the given foods in a single statement, replacing the
+// stored values of any food that was already imported.
+func (r *foodRepository) UpsertFoods(ctx context.Context, foods []*models.Food) error {
+	if len(foods) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	placeholders := make([]string, 0, len(foods))
+	args := make([]interface{}, 0, len(foods)*10)
+	for _, food := range foods {
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args,
+			food.FdcID, food.Description, food.DataType, food.BrandOwner,
+			food.Calories, food.Protein, food.Carbs, food.Fat, now, now)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO foods (
+			fdc_id, description, data_type, brand_owner, calories, protein, carbs, fats,
+			created_at, updated_at
+		) VALUES %s
+		%s
+	`, strings.Join(placeholders, ", "), r.dialect.Upsert(
+		[]string{"fdc_id"},
+		[]string{"description", "data_type", "brand_owner", "calories", "protein", "carbs", "fats", "updated_at"},
+	))
+
+	if _, err = tx.ExecContext(ctx, tx.Rebind(query), args...); err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	return nil
+}
+
+func (r *foodRepository) CountFoods(ctx context.Context) (int, error) {
+	var count int
+	if err := r.db.GetContext(ctx, &count, r.db.Rebind(`SELECT COUNT(*) FROM foods`)); err != nil {
+		return 0, wrapDatabaseError(err)
+	}
+	return count, nil
+}
@@ -0,0 +1,212 @@
+package repositories
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	models "HabitBite/backend/Models"
+)
+
+// SearchCandidateLimit caps how many full-text matches are re-ranked in Go
+// for a single query. Searches that reach it report a capped total, and no
+// page past it can hold results.
+const SearchCandidateLimit = 1000
+
+// foodTypeBoosts weights the ranking score by FDC data type so branded
+// products are preferred over the generic foundation and survey entries when
+// both match equally well.
+var foodTypeBoosts = map[string]float64{
+	models.FoodTypeBranded:    1.15,
+	models.FoodTypeFoundation: 1.0,
+	models.FoodTypeSRLegacy:   1.0,
+	models.FoodTypeSurvey:     1.0,
+}
+
+type rankedFood struct {
+	food  *models.Food
+	score float64
+}
+
+// tokenize lower-cases the text and splits it into alphanumeric words.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// searchPrefixes returns the distinct prefixes used to collect search
+// candidates. Matching on the first three letters as well keeps misspelled
+// terms in the candidate set; InnoDB does not index shorter tokens.
+func searchPrefixes(terms []string) []string {
+	seen := make(map[string]bool)
+	var prefixes []string
+	for _, term := range terms {
+		candidates := []string{term}
+		if runes := []rune(term); len(runes) > 3 {
+			candidates = append(candidates, string(runes[:3]))
+		}
+		for _, prefix := range candidates {
+			if len([]rune(prefix)) < 3 || seen[prefix] {
+				continue
+			}
+			seen[prefix] = true
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+// rankedPage ranks the candidates and returns the requested page together
+// with the total number of matches.
+func rankedPage(terms []string, candidates []*models.Food, page, pageSize int) *models.FoodSearchPage {
+	ranked := rankFoods(terms, candidates)
+	result := &models.FoodSearchPage{
+		Foods:     []*models.Food{},
+		TotalHits: len(ranked),
+		Capped:    len(candidates) >= SearchCandidateLimit,
+	}
+
+	// Compare page numbers rather than offsets, which overflow for huge pages.
+	if page-1 < (result.TotalHits+pageSize-1)/pageSize {
+		start := (page - 1) * pageSize
+		result.Foods = ranked[start:min(start+pageSize, result.TotalHits)]
+	}
+	return result
+}
+
+// rankFoods scores the candidates against the query terms and returns the
+// ones that match every term, best first. A term matches a word of the
+// description or brand exactly, as a prefix, or within a small edit distance.
+func rankFoods(terms []string, candidates []*models.Food) []*models.Food {
+	ranked := make([]rankedFood, 0, len(candidates))
+	for _, food := range candidates {
+		if score := scoreFood(terms, food); score > 0 {
+			ranked = append(ranked, rankedFood{food: food, score: score})
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		if len(ranked[i].food.Description) != len(ranked[j].food.Description) {
+			return len(ranked[i].food.Description) < len(ranked[j].food.Description)
+		}
+		return ranked[i].food.FdcID < ranked[j].food.FdcID
+	})
+
+	foods := make([]*models.Food, len(ranked))
+	for i, r := range ranked {
+		foods[i] = r.food
+	}
+	return foods
+}
+
+func scoreFood(terms []string, food *models.Food) float64 {
+	words := tokenize(food.Description + " " + food.BrandOwner)
+	if len(words) == 0 || len(terms) == 0 {
+		return 0
+	}
+
+	total := 0.0
+	for _, term := range terms {
+		best := 0.0
+		for _, word := range words {
+			if s := termScore(term, word); s > best {
+				best = s
+			}
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+
+	score := total / float64(len(terms))
+
+	// Prefer short descriptions that start with the first term, e.g. "Apple,
+	// raw" over "Cereal bar, apple cinnamon".
+	if termScore(terms[0], words[0]) > 0 {
+		score += 0.1
+	}
+	score += 0.1 * float64(len(terms)) / float64(len(words))
+
+	boost, ok := foodTypeBoosts[food.DataType]
+	if !ok {
+		boost = 1.0
+	}
+	return score * boost
+}
+
+func termScore(term, word string) float64 {
+	switch {
+	case word == term:
+		return 1.0
+	case strings.HasPrefix(word, term):
+		return 0.8
+	}
+
+	maxEdits := allowedEdits(term)
+	if maxEdits == 0 {
+		return 0
+	}
+
+	distance := editDistance(term, word)
+	// Also compare against the start of longer words so a misspelled prefix
+	// such as "chikc" still finds "chicken".
+	if termRunes, wordRunes := []rune(term), []rune(word); len(wordRunes) > len(termRunes) {
+		if d := editDistance(term, string(wordRunes[:len(termRunes)])); d < distance {
+			distance = d
+		}
+	}
+
+	if distance > maxEdits {
+		return 0
+	}
+	return 0.6 - 0.15*float64(distance-1)
+}
+
+// allowedEdits returns how many typos are tolerated for a term of this length
+// in letters.
+func allowedEdits(term string) int {
+	switch n := utf8.RuneCountInString(term); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance returns the optimal string alignment distance between a and
+// b: insertions, deletions, substitutions and swaps of adjacent letters each
+// count as one edit.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	models "HabitBite/backend/Models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrJobNotFound = errors.New("job not found")
+
+type JobRepository interface {
+	CreateJob(ctx context.Context, job *models.Job) error
+	GetJob(ctx context.Context, id int) (*models.Job, error)
+	// UpdateJob saves the status and progress of a job.
+	UpdateJob(ctx context.Context, job *models.Job) error
+	// GetUnfinishedJobs returns the queued and running jobs, oldest first.
+	GetUnfinishedJobs(ctx context.Context) ([]models.Job, error)
+}
+
+type jobRepository struct {
+	db      *sqlx.DB
+	dialect dialect
+}
+
+func NewJobRepository(db *sqlx.DB) JobRepository {
+	return &jobRepository{db: db, dialect: dialectFor(db)}
+}
+
+func (r *jobRepository) CreateJob(ctx context.Context, job *models.Job) error {
+	now := time.Now()
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	query := `
+		INSERT INTO jobs (type, status, params, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	id, err := r.dialect.InsertReturningID(ctx, r.db, query,
+		job.Type, job.Status, job.Params.String(), job.CreatedBy, job.CreatedAt, job.UpdatedAt)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	job.ID = int(id)
+
+	return nil
+}
+
+func (r *jobRepository) GetJob(ctx context.Context, id int) (*models.Job, error) {
+	var job models.Job
+	err := r.db.GetContext(ctx, &job, r.db.Rebind(`SELECT * FROM jobs WHERE id = ?`), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrJobNotFound
+		}
+		return nil, wrapDatabaseError(err)
+	}
+
+	return &job, nil
+}
+
+func (r *jobRepository) UpdateJob(ctx context.Context, job *models.Job) error {
+	job.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE jobs SET
+			status = ?, total = ?, processed = ?, changed = ?, failed = ?, last_id = ?,
+			error_message = ?, started_at = ?, finished_at = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(query),
+		job.Status, job.Total, job.Processed, job.Changed, job.Failed, job.LastID,
+		job.ErrorMessage, job.StartedAt, job.FinishedAt, job.UpdatedAt, job.ID)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	return nil
+}
+
+func (r *jobRepository) GetUnfinishedJobs(ctx context.Context) ([]models.Job, error) {
+	query := `SELECT * FROM jobs WHERE status IN (?, ?) ORDER BY id`
+
+	jobs := []models.Job{}
+	err := r.db.SelectContext(ctx, &jobs, r.db.Rebind(query), models.JobQueued, models.JobRunning)
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	return jobs, nil
+}
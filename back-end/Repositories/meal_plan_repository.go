@@ -0,0 +1,262 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	models "HabitBite/backend/Models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrMealPlanNotFound     = errors.New("meal plan not found")
+	ErrMealPlanItemNotFound = errors.New("meal plan item not found")
+	ErrMealPlanOverlap      = errors.New("meal plan overlaps another plan of the client")
+)
+
+// MealPlanRepository stores dietitian-authored meal plans. Every method is
+// scoped to the client the plan is for, and a client's plans never overlap,
+// so at most one plan applies on any date.
+type MealPlanRepository interface {
+	// CreateMealPlan saves a plan with its items. It fails with
+	// ErrMealPlanOverlap if the dates overlap another plan of the client.
+	CreateMealPlan(ctx context.Context, plan *models.MealPlan) error
+	// ReplaceMealPlan updates the plan and replaces all of its items.
+	ReplaceMealPlan(ctx context.Context, plan *models.MealPlan) error
+	DeleteMealPlan(ctx context.Context, userID, planID int) error
+	// GetMealPlan returns a plan with its items.
+	GetMealPlan(ctx context.Context, userID, planID int) (*models.MealPlan, error)
+	// GetMealPlans returns a client's plans without items, latest first.
+	GetMealPlans(ctx context.Context, userID int) ([]models.MealPlan, error)
+	// GetActiveMealPlan returns the plan, with its items, that applies on
+	// date, or ErrMealPlanNotFound.
+	GetActiveMealPlan(ctx context.Context, userID int, date time.Time) (*models.MealPlan, error)
+	GetMealPlanItem(ctx context.Context, userID, itemID int) (*models.MealPlanItem, error)
+}
+
+type mealPlanRepository struct {
+	db      *sqlx.DB
+	dialect dialect
+}
+
+func NewMealPlanRepository(db *sqlx.DB) MealPlanRepository {
+	return &mealPlanRepository{db: db, dialect: dialectFor(db)}
+}
+
+func (r *mealPlanRepository) CreateMealPlan(ctx context.Context, plan *models.MealPlan) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	if err := r.checkOverlap(ctx, tx, plan); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	plan.CreatedAt = now
+	plan.UpdatedAt = now
+
+	query := `
+		INSERT INTO meal_plans (
+			user_id, dietitian_id, name, notes, days, start_date, end_date, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	id, err := r.dialect.InsertReturningID(ctx, tx, query,
+		plan.UserID, plan.DietitianID, plan.Name, plan.Notes, plan.Days,
+		plan.StartDate.Format("2006-01-02"), plan.EndDate.Format("2006-01-02"), now, now)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	plan.ID = int(id)
+
+	if err := r.insertItems(ctx, tx, plan); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	return nil
+}
+
+func (r *mealPlanRepository) ReplaceMealPlan(ctx context.Context, plan *models.MealPlan) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	if err := r.checkOverlap(ctx, tx, plan); err != nil {
+		return err
+	}
+
+	plan.UpdatedAt = time.Now()
+	updateQuery := `
+		UPDATE meal_plans
+		SET name = ?, notes = ?, days = ?, start_date = ?, end_date = ?, updated_at = ?
+		WHERE id = ? AND user_id = ?
+	`
+	result, err := tx.ExecContext(ctx, tx.Rebind(updateQuery),
+		plan.Name, plan.Notes, plan.Days, plan.StartDate.Format("2006-01-02"),
+		plan.EndDate.Format("2006-01-02"), plan.UpdatedAt, plan.ID, plan.UserID)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return wrapDatabaseError(err)
+	} else if rows == 0 {
+		return ErrMealPlanNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(`DELETE FROM meal_plan_items WHERE plan_id = ?`), plan.ID); err != nil {
+		return wrapDatabaseError(err)
+	}
+	if err := r.insertItems(ctx, tx, plan); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	return nil
+}
+
+// checkOverlap locks the client's row, so concurrent saves of their plans
+// run one at a time, and fails if plan overlaps another of their plans.
+func (r *mealPlanRepository) checkOverlap(ctx context.Context, tx *sqlx.Tx, plan *models.MealPlan) error {
+	var userID int
+	err := tx.GetContext(ctx, &userID, tx.Rebind(`SELECT id FROM users WHERE id = ?`+r.dialect.ForUpdate()), plan.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return wrapDatabaseError(err)
+	}
+
+	query := `
+		SELECT COUNT(*) FROM meal_plans
+		WHERE user_id = ? AND id <> ? AND start_date <= ? AND end_date >= ?
+	`
+	var overlapping int
+	err = tx.GetContext(ctx, &overlapping, tx.Rebind(query),
+		plan.UserID, plan.ID, plan.EndDate.Format("2006-01-02"), plan.StartDate.Format("2006-01-02"))
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	if overlapping > 0 {
+		return ErrMealPlanOverlap
+	}
+	return nil
+}
+
+func (r *mealPlanRepository) insertItems(ctx context.Context, tx *sqlx.Tx, plan *models.MealPlan) error {
+	query := `
+		INSERT INTO meal_plan_items (
+			plan_id, day_number, meal, food_id, food_name, quantity, calories, protein, carbs, fats
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	for i := range plan.Items {
+		item := &plan.Items[i]
+		item.PlanID = plan.ID
+		id, err := r.dialect.InsertReturningID(ctx, tx, query,
+			item.PlanID, item.Day, item.Meal, item.FoodID, item.Name, item.Amount,
+			item.Calories, item.Protein, item.Carbs, item.Fat)
+		if err != nil {
+			return wrapDatabaseError(err)
+		}
+		item.ID = int(id)
+	}
+	return nil
+}
+
+func (r *mealPlanRepository) DeleteMealPlan(ctx context.Context, userID, planID int) error {
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(`DELETE FROM meal_plans WHERE id = ? AND user_id = ?`), planID, userID)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	if rows == 0 {
+		return ErrMealPlanNotFound
+	}
+	return nil
+}
+
+func (r *mealPlanRepository) GetMealPlan(ctx context.Context, userID, planID int) (*models.MealPlan, error) {
+	var plan models.MealPlan
+	err := r.db.GetContext(ctx, &plan, r.db.Rebind(`SELECT * FROM meal_plans WHERE id = ? AND user_id = ?`), planID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMealPlanNotFound
+		}
+		return nil, wrapDatabaseError(err)
+	}
+
+	if err := r.loadItems(ctx, &plan); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (r *mealPlanRepository) GetMealPlans(ctx context.Context, userID int) ([]models.MealPlan, error) {
+	plans := []models.MealPlan{}
+	query := `SELECT * FROM meal_plans WHERE user_id = ? ORDER BY start_date DESC`
+	if err := r.db.SelectContext(ctx, &plans, r.db.Rebind(query), userID); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	return plans, nil
+}
+
+func (r *mealPlanRepository) GetActiveMealPlan(ctx context.Context, userID int, date time.Time) (*models.MealPlan, error) {
+	day := date.Format("2006-01-02")
+	query := `SELECT * FROM meal_plans WHERE user_id = ? AND start_date <= ? AND end_date >= ?`
+
+	var plan models.MealPlan
+	if err := r.db.GetContext(ctx, &plan, r.db.Rebind(query), userID, day, day); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMealPlanNotFound
+		}
+		return nil, wrapDatabaseError(err)
+	}
+
+	if err := r.loadItems(ctx, &plan); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (r *mealPlanRepository) GetMealPlanItem(ctx context.Context, userID, itemID int) (*models.MealPlanItem, error) {
+	query := `
+		SELECT i.*
+		FROM meal_plan_items i
+		JOIN meal_plans p ON p.id = i.plan_id
+		WHERE i.id = ? AND p.user_id = ?
+	`
+	var item models.MealPlanItem
+	if err := r.db.GetContext(ctx, &item, r.db.Rebind(query), itemID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMealPlanItemNotFound
+		}
+		return nil, wrapDatabaseError(err)
+	}
+	return &item, nil
+}
+
+func (r *mealPlanRepository) loadItems(ctx context.Context, plan *models.MealPlan) error {
+	plan.Items = []models.MealPlanItem{}
+	query := `SELECT * FROM meal_plan_items WHERE plan_id = ? ORDER BY day_number, id`
+	if err := r.db.SelectContext(ctx, &plan.Items, r.db.Rebind(query), plan.ID); err != nil {
+		return wrapDatabaseError(err)
+	}
+	return nil
+}
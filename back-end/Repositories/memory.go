@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"sync"
+	"time"
+
+	goals "HabitBite/backend/Goals"
+	models "HabitBite/backend/Models"
+)
+
+// memoryDB holds the tables of the in-memory store. All repositories built on
+// the same memoryDB share its lock, so operations spanning several tables,
+// such as the daily rollups, are as atomic as their SQL transactions.
+type memoryDB struct {
+	mu sync.RWMutex
+
+	users         map[int]models.User
+	goals         map[int]models.UserGoals
+	subscriptions map[subscription]models.DietitianSubscription
+	foodEntries   map[int]models.FoodEntry
+	dailyTotals   map[dailyKey]dailyTotals
+	weightEntries map[int]models.WeightEntry
+	foods         map[int]models.Food
+	audit         []models.AuditEntry
+	jobs          map[int]models.Job
+	applications  map[int]models.DietitianApplication
+	profiles      map[int]models.DietitianProfile
+	mealPlans     map[int]models.MealPlan
+	conversations map[int]models.Conversation
+	messages      map[int][]models.Message // by conversation, ascending ID
+	comments      map[int]models.Comment
+	alerts        map[int]models.Alert
+	refreshTokens map[int]models.RefreshToken
+	sessions      map[int]models.Session
+	userTokens    map[int]models.UserToken
+	twoFactor     map[int]models.TwoFactor // by user
+	recoveryCodes map[int]models.RecoveryCode
+	mfaChallenges map[string]models.MFAChallenge
+
+	lastUserID      int
+	lastFoodEntryID int
+	lastWeightID    int
+	lastAuditID     int
+	lastJobID       int
+
+	lastApplicationID  int
+	lastMealPlanID     int
+	lastMealPlanItemID int
+	lastConversationID int
+	lastMessageID      int
+	lastCommentID      int
+	lastAlertID        int
+	lastRefreshTokenID int
+	lastSessionID      int
+	lastUserTokenID    int
+	lastRecoveryCodeID int
+}
+
+// subscription is the key of a user_dietitian row.
+type subscription struct {
+	userID      int
+	dietitianID int
+}
+
+// dailyKey is the key of a daily_entries row; date is formatted YYYY-MM-DD.
+type dailyKey struct {
+	userID int
+	date   string
+}
+
+type dailyTotals struct {
+	calories float64
+	protein  float64
+	carbs    float64
+	fats     float64
+}
+
+// NewMemoryStore returns thread-safe in-memory implementations of every
+// repository, sharing one empty data set. They follow the semantics of the
+// SQL repositories, including the daily_entries rollups and the cascading
+// deletes of the schema, and are meant for tests and the demo mode.
+func NewMemoryStore(planner *goals.Planner) *Store {
+	db := &memoryDB{
+		users:         make(map[int]models.User),
+		goals:         make(map[int]models.UserGoals),
+		subscriptions: make(map[subscription]models.DietitianSubscription),
+		foodEntries:   make(map[int]models.FoodEntry),
+		dailyTotals:   make(map[dailyKey]dailyTotals),
+		weightEntries: make(map[int]models.WeightEntry),
+		foods:         make(map[int]models.Food),
+		jobs:          make(map[int]models.Job),
+		applications:  make(map[int]models.DietitianApplication),
+		profiles:      make(map[int]models.DietitianProfile),
+		mealPlans:     make(map[int]models.MealPlan),
+		conversations: make(map[int]models.Conversation),
+		messages:      make(map[int][]models.Message),
+		comments:      make(map[int]models.Comment),
+		alerts:        make(map[int]models.Alert),
+		refreshTokens: make(map[int]models.RefreshToken),
+		sessions:      make(map[int]models.Session),
+		userTokens:    make(map[int]models.UserToken),
+		twoFactor:     make(map[int]models.TwoFactor),
+		recoveryCodes: make(map[int]models.RecoveryCode),
+		mfaChallenges: make(map[string]models.MFAChallenge),
+	}
+
+	return &Store{
+		Users:         &memoryUserRepository{db: db, planner: planner},
+		FoodEntries:   &memoryFoodEntryRepository{db: db},
+		Foods:         &memoryFoodRepository{db: db},
+		Audit:         &memoryAuditRepository{db: db},
+		Weights:       &memoryWeightRepository{db: db},
+		Jobs:          &memoryJobRepository{db: db},
+		Dietitians:    &memoryDietitianRepository{db: db},
+		MealPlans:     &memoryMealPlanRepository{db: db},
+		Messages:      &memoryMessageRepository{db: db},
+		Comments:      &memoryCommentRepository{db: db},
+		Alerts:        &memoryAlertRepository{db: db},
+		RefreshTokens: &memoryRefreshTokenRepository{db: db},
+		UserTokens:    &memoryUserTokenRepository{db: db},
+		TwoFactor:     &memoryTwoFactorRepository{db: db},
+		Goals:         planner,
+	}
+}
+
+// dateKey formats t the way the SQL repositories store daily_entries dates.
+func dateKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
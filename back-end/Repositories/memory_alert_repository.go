@@ -0,0 +1,171 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	models "HabitBite/backend/Models"
+)
+
+type memoryAlertRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryAlertRepository) SyncAlerts(ctx context.Context, userID int, dietitianIDs []int, findings []models.AlertFinding, now time.Time) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.users[userID]; !ok {
+		return 0, ErrUserNotFound
+	}
+	for _, dietitianID := range dietitianIDs {
+		if _, ok := r.db.users[dietitianID]; !ok {
+			return 0, ErrUserNotFound
+		}
+	}
+
+	stored := make(map[alertKey]int)
+	for id, alert := range r.db.alerts {
+		if alert.UserID == userID {
+			stored[alertKey{alert.DietitianID, alert.Rule}] = id
+		}
+	}
+
+	opened := 0
+	found := make(map[alertKey]bool)
+	for _, dietitianID := range dietitianIDs {
+		for _, finding := range findings {
+			key := alertKey{dietitianID, finding.Rule}
+			found[key] = true
+
+			id, ok := stored[key]
+			if !ok {
+				r.db.lastAlertID++
+				r.db.alerts[r.db.lastAlertID] = models.Alert{
+					ID:          r.db.lastAlertID,
+					DietitianID: dietitianID,
+					UserID:      userID,
+					Rule:        finding.Rule,
+					Status:      models.AlertOpen,
+					Message:     finding.Message,
+					DetectedAt:  now,
+					LastSeenAt:  now,
+				}
+				opened++
+				continue
+			}
+
+			alert := r.db.alerts[id]
+			if alert.Status == models.AlertResolved {
+				alert.Status = models.AlertOpen
+				alert.DetectedAt = now
+				alert.AcknowledgedAt = nil
+				alert.SnoozedUntil = nil
+				alert.ResolvedAt = nil
+				opened++
+			}
+			alert.Message = finding.Message
+			alert.LastSeenAt = now
+			r.db.alerts[id] = alert
+		}
+	}
+
+	for key, id := range stored {
+		alert := r.db.alerts[id]
+		if found[key] || alert.Status == models.AlertResolved {
+			continue
+		}
+		resolvedAt := now
+		alert.Status = models.AlertResolved
+		alert.ResolvedAt = &resolvedAt
+		r.db.alerts[id] = alert
+	}
+
+	return opened, nil
+}
+
+func (r *memoryAlertRepository) GetAlerts(ctx context.Context, dietitianID int, status string, clientID int, now time.Time) ([]models.Alert, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	alerts := []models.Alert{}
+	for _, alert := range r.db.alerts {
+		if alert.DietitianID != dietitianID || (clientID != 0 && alert.UserID != clientID) {
+			continue
+		}
+		sub, ok := r.db.subscriptions[subscription{userID: alert.UserID, dietitianID: dietitianID}]
+		if !ok || sub.Status != models.SubscriptionAccepted {
+			continue
+		}
+
+		switch status {
+		case "":
+		case models.AlertOpen:
+			if alert.Status != models.AlertOpen || alert.Snoozed(now) {
+				continue
+			}
+		case models.AlertSnoozed:
+			if !alert.Snoozed(now) {
+				continue
+			}
+		default:
+			if alert.Status != status {
+				continue
+			}
+		}
+
+		alert.FullName = r.db.users[alert.UserID].FullName
+		alerts = append(alerts, alert)
+	}
+
+	sort.Slice(alerts, func(i, j int) bool {
+		if !alerts[i].DetectedAt.Equal(alerts[j].DetectedAt) {
+			return alerts[i].DetectedAt.After(alerts[j].DetectedAt)
+		}
+		return alerts[i].ID > alerts[j].ID
+	})
+	return alerts, nil
+}
+
+func (r *memoryAlertRepository) AcknowledgeAlert(ctx context.Context, dietitianID, alertID int, now time.Time) (*models.Alert, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	alert, ok := r.db.alerts[alertID]
+	if !ok || alert.DietitianID != dietitianID {
+		return nil, ErrAlertNotFound
+	}
+	switch alert.Status {
+	case models.AlertOpen:
+		acknowledgedAt := now
+		alert.Status = models.AlertAcknowledged
+		alert.AcknowledgedAt = &acknowledgedAt
+		alert.SnoozedUntil = nil
+		r.db.alerts[alertID] = alert
+	case models.AlertResolved:
+		return nil, ErrAlertNotOpen
+	}
+
+	alert.FullName = r.db.users[alert.UserID].FullName
+	return &alert, nil
+}
+
+func (r *memoryAlertRepository) SnoozeAlert(ctx context.Context, dietitianID, alertID int, until time.Time) (*models.Alert, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	alert, ok := r.db.alerts[alertID]
+	if !ok || alert.DietitianID != dietitianID {
+		return nil, ErrAlertNotFound
+	}
+	if alert.Status != models.AlertOpen {
+		return nil, ErrAlertNotOpen
+	}
+
+	alert.SnoozedUntil = &until
+	r.db.alerts[alertID] = alert
+
+	alert.FullName = r.db.users[alert.UserID].FullName
+	return &alert, nil
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	models "HabitBite/backend/Models"
+)
+
+type memoryAuditRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryAuditRepository) RecordAudit(ctx context.Context, entry *models.AuditEntry) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	r.db.lastAuditID++
+	entry.ID = r.db.lastAuditID
+	entry.CreatedAt = time.Now()
+	r.db.audit = append(r.db.audit, *entry)
+
+	return nil
+}
+
+func (r *memoryAuditRepository) GetAuditEntries(ctx context.Context, targetUserID int, limit int) ([]models.AuditEntry, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	entries := []models.AuditEntry{}
+	for i := len(r.db.audit) - 1; i >= 0 && len(entries) < limit; i-- {
+		if targetUserID == 0 || r.db.audit[i].TargetUserID == targetUserID {
+			entries = append(entries, r.db.audit[i])
+		}
+	}
+	return entries, nil
+}
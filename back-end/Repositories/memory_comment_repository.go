@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	models "HabitBite/backend/Models"
+)
+
+type memoryCommentRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryCommentRepository) CreateComment(ctx context.Context, comment *models.Comment) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, id := range []int{comment.UserID, comment.DietitianID, comment.AuthorID} {
+		if _, ok := r.db.users[id]; !ok {
+			return ErrUserNotFound
+		}
+	}
+	if comment.EntryID != nil {
+		if _, ok := r.db.foodEntries[*comment.EntryID]; !ok {
+			return ErrFoodEntryNotFound
+		}
+	}
+	if comment.ParentID != nil {
+		if _, ok := r.db.comments[*comment.ParentID]; !ok {
+			return ErrCommentNotFound
+		}
+	}
+
+	r.db.lastCommentID++
+	stored := *comment
+	stored.ID = r.db.lastCommentID
+	stored.CreatedAt = time.Now()
+	stored.Replies = nil
+	if stored.EntryID != nil {
+		stored.Date = ""
+	}
+	r.db.comments[stored.ID] = stored
+
+	*comment = r.db.resolveComment(stored)
+	return nil
+}
+
+// resolveComment fills in the date of an entry comment and the author, the
+// way the SQL repository joins them. The caller must hold the lock.
+func (db *memoryDB) resolveComment(comment models.Comment) models.Comment {
+	if comment.EntryID != nil {
+		comment.Date = dateKey(db.foodEntries[*comment.EntryID].Date)
+	}
+	author := db.users[comment.AuthorID]
+	comment.AuthorName = author.FullName
+	comment.AuthorRole = author.Role
+	return comment
+}
+
+func (r *memoryCommentRepository) GetComment(ctx context.Context, userID, commentID int) (*models.Comment, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	comment, ok := r.db.comments[commentID]
+	if !ok || comment.UserID != userID {
+		return nil, ErrCommentNotFound
+	}
+	comment = r.db.resolveComment(comment)
+	return &comment, nil
+}
+
+func (r *memoryCommentRepository) GetComments(ctx context.Context, userID, dietitianID int, startDate, endDate time.Time) ([]models.Comment, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	start, end := dateKey(startDate), dateKey(endDate)
+	comments := []models.Comment{}
+	for _, comment := range r.db.comments {
+		if comment.UserID != userID || (dietitianID != 0 && comment.DietitianID != dietitianID) {
+			continue
+		}
+		comment = r.db.resolveComment(comment)
+		if comment.Date >= start && comment.Date <= end {
+			comments = append(comments, comment)
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		if !comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+		}
+		return comments[i].ID < comments[j].ID
+	})
+	return comments, nil
+}
+
+func (r *memoryCommentRepository) DeleteComment(ctx context.Context, userID, commentID int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if comment, ok := r.db.comments[commentID]; !ok || comment.UserID != userID {
+		return ErrCommentNotFound
+	}
+	r.db.deleteComments(func(comment *models.Comment) bool {
+		return comment.ID == commentID
+	})
+	return nil
+}
+
+// deleteComments deletes the comments matching the predicate with their
+// replies, like the cascading foreign keys of the schema. The caller must
+// hold the write lock.
+func (db *memoryDB) deleteComments(match func(comment *models.Comment) bool) {
+	for id, comment := range db.comments {
+		if match(&comment) {
+			delete(db.comments, id)
+		}
+	}
+	for id, comment := range db.comments {
+		if comment.ParentID != nil {
+			if _, ok := db.comments[*comment.ParentID]; !ok {
+				delete(db.comments, id)
+			}
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	models "HabitBite/backend/Models"
+)
+
+type memoryDietitianRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryDietitianRepository) CreateApplication(ctx context.Context, application *models.DietitianApplication) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.users[application.UserID]; !ok {
+		return wrapDatabaseError(errMemoryConstraint)
+	}
+	for _, existing := range r.db.applications {
+		if existing.UserID == application.UserID && existing.Status == models.ApplicationPending {
+			return ErrApplicationPending
+		}
+	}
+
+	application.Status = models.ApplicationPending
+	application.CreatedAt = time.Now()
+
+	r.db.lastApplicationID++
+	application.ID = r.db.lastApplicationID
+	r.db.applications[application.ID] = *application
+
+	return nil
+}
+
+func (r *memoryDietitianRepository) GetApplication(ctx context.Context, id int) (*models.DietitianApplication, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	application, ok := r.db.applications[id]
+	if !ok {
+		return nil, ErrApplicationNotFound
+	}
+	r.db.withApplicant(&application)
+	return &application, nil
+}
+
+func (r *memoryDietitianRepository) GetApplications(ctx context.Context, status string) ([]models.DietitianApplication, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	applications := []models.DietitianApplication{}
+	for id := r.db.lastApplicationID; id > 0; id-- {
+		application, ok := r.db.applications[id]
+		if !ok || (status != "" && application.Status != status) {
+			continue
+		}
+		r.db.withApplicant(&application)
+		applications = append(applications, application)
+	}
+	return applications, nil
+}
+
+func (r *memoryDietitianRepository) GetUserApplications(ctx context.Context, userID int) ([]models.DietitianApplication, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	applications := []models.DietitianApplication{}
+	for id := r.db.lastApplicationID; id > 0; id-- {
+		if application, ok := r.db.applications[id]; ok && application.UserID == userID {
+			applications = append(applications, application)
+		}
+	}
+	return applications, nil
+}
+
+func (r *memoryDietitianRepository) ApproveApplication(ctx context.Context, id int, reviewerID int, reason string) (*models.DietitianApplication, error) {
+	return r.review(id, reviewerID, reason, models.ApplicationApproved)
+}
+
+func (r *memoryDietitianRepository) RejectApplication(ctx context.Context, id int, reviewerID int, reason string) (*models.DietitianApplication, error) {
+	return r.review(id, reviewerID, reason, models.ApplicationRejected)
+}
+
+func (r *memoryDietitianRepository) review(id int, reviewerID int, reason string, status string) (*models.DietitianApplication, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	application, ok := r.db.applications[id]
+	if !ok {
+		return nil, ErrApplicationNotFound
+	}
+	if application.Status != models.ApplicationPending {
+		return nil, ErrApplicationNotPending
+	}
+
+	now := time.Now()
+	application.Status = status
+	application.ReviewedBy = &reviewerID
+	application.ReviewedAt = &now
+	application.ReviewReason = nil
+	if reason != "" {
+		application.ReviewReason = &reason
+	}
+	r.db.applications[id] = application
+
+	if status == models.ApplicationApproved {
+		user := r.db.users[application.UserID]
+		user.Role = models.RoleDietitian
+		user.UpdatedAt = now
+		r.db.users[user.ID] = user
+
+		r.db.saveProfile(&models.DietitianProfile{
+			UserID:        user.ID,
+			DisplayName:   user.FullName,
+			Credentials:   application.Credentials,
+			LicenseNumber: application.LicenseNumber,
+			Bio:           application.Bio,
+		})
+	}
+
+	return &application, nil
+}
+
+func (r *memoryDietitianRepository) GetProfile(ctx context.Context, dietitianID int) (*models.DietitianProfile, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	profile, ok := r.db.profiles[dietitianID]
+	if !ok {
+		return nil, ErrDietitianProfileMissing
+	}
+	return &profile, nil
+}
+
+func (r *memoryDietitianRepository) GetProfiles(ctx context.Context, dietitianIDs []int) (map[int]models.DietitianProfile, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	byUser := make(map[int]models.DietitianProfile, len(dietitianIDs))
+	for _, id := range dietitianIDs {
+		if profile, ok := r.db.profiles[id]; ok {
+			byUser[id] = profile
+		}
+	}
+	return byUser, nil
+}
+
+func (r *memoryDietitianRepository) SaveProfile(ctx context.Context, profile *models.DietitianProfile) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.users[profile.UserID]; !ok {
+		return wrapDatabaseError(errMemoryConstraint)
+	}
+	r.db.saveProfile(profile)
+	return nil
+}
+
+// saveProfile inserts or replaces a profile, keeping the original creation
+// time. The caller must hold the write lock.
+func (db *memoryDB) saveProfile(profile *models.DietitianProfile) {
+	now := time.Now()
+	profile.CreatedAt = now
+	if existing, ok := db.profiles[profile.UserID]; ok {
+		profile.CreatedAt = existing.CreatedAt
+	}
+	profile.UpdatedAt = now
+	db.profiles[profile.UserID] = *profile
+}
+
+// withApplicant fills in the applicant fields the SQL repository joins from
+// users. The caller must hold the lock.
+func (db *memoryDB) withApplicant(application *models.DietitianApplication) {
+	if user, ok := db.users[application.UserID]; ok {
+		application.ApplicantName = user.FullName
+		application.ApplicantEmail = user.Email
+	}
+}
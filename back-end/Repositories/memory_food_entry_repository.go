@@ -0,0 +1,272 @@
+package repositories
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	models "HabitBite/backend/Models"
+)
+
+type memoryFoodEntryRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryFoodEntryRepository) CreateFoodEntry(ctx context.Context, entry *models.FoodEntry) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	r.db.lastFoodEntryID++
+	entry.ID = r.db.lastFoodEntryID
+	entry.CreatedAt = now
+	entry.UpdatedAt = now
+	r.db.foodEntries[entry.ID] = *entry
+
+	r.db.recalculateDailyTotals(entry.UserID, entry.Date)
+	return nil
+}
+
+func (r *memoryFoodEntryRepository) GetFoodEntry(ctx context.Context, userID, entryID int) (*models.FoodEntry, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	entry, err := r.db.ownedEntry(userID, entryID)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetDailyEntries returns the entries on the calendar day of date, newest
+// first.
+func (r *memoryFoodEntryRepository) GetDailyEntries(ctx context.Context, userID int, date time.Time) ([]*models.FoodEntry, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var entries []*models.FoodEntry
+	for _, entry := range r.db.foodEntries {
+		if entry.UserID == userID && sameDay(entry.Date, date) {
+			entry := entry
+			entries = append(entries, &entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Date.After(entries[j].Date)
+	})
+	return entries, nil
+}
+
+func (r *memoryFoodEntryRepository) GetEntriesByDay(ctx context.Context, userID int, startDate, endDate time.Time) (map[string][]*models.FoodEntry, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	from, to := startDate.Format("2006-01-02"), endDate.Format("2006-01-02")
+	byDay := make(map[string][]*models.FoodEntry)
+	for _, entry := range r.db.foodEntries {
+		day := entry.Date.Format("2006-01-02")
+		if entry.UserID == userID && day >= from && day <= to {
+			entry := entry
+			byDay[day] = append(byDay[day], &entry)
+		}
+	}
+
+	for _, entries := range byDay {
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].Date.Before(entries[j].Date)
+		})
+	}
+	return byDay, nil
+}
+
+func (r *memoryFoodEntryRepository) UpdateFoodEntry(ctx context.Context, entry *models.FoodEntry) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	previous, err := r.db.ownedEntry(entry.UserID, entry.ID)
+	if err != nil {
+		return err
+	}
+
+	entry.CreatedAt = previous.CreatedAt
+	entry.UpdatedAt = time.Now()
+	r.db.foodEntries[entry.ID] = *entry
+
+	r.db.recalculateDailyTotals(entry.UserID, previous.Date)
+	if !sameDay(previous.Date, entry.Date) {
+		r.db.recalculateDailyTotals(entry.UserID, entry.Date)
+	}
+	return nil
+}
+
+func (r *memoryFoodEntryRepository) DeleteFoodEntry(ctx context.Context, userID, entryID int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	entry, err := r.db.ownedEntry(userID, entryID)
+	if err != nil {
+		return err
+	}
+
+	delete(r.db.foodEntries, entryID)
+	r.db.deleteComments(func(comment *models.Comment) bool {
+		return comment.EntryID != nil && *comment.EntryID == entryID
+	})
+	r.db.recalculateDailyTotals(userID, entry.Date)
+	return nil
+}
+
+func (r *memoryFoodEntryRepository) GetDailyNutrition(ctx context.Context, userID int, date time.Time) (*models.DailyNutrition, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	byMeal := make(map[string]*models.MealNutrition)
+	for _, entry := range r.db.foodEntries {
+		if entry.UserID != userID || !sameDay(entry.Date, date) {
+			continue
+		}
+		meal, ok := byMeal[entry.Meal]
+		if !ok {
+			meal = &models.MealNutrition{Meal: entry.Meal}
+			byMeal[entry.Meal] = meal
+		}
+		meal.TotalCalories = roundCents(meal.TotalCalories + entry.Calories)
+		meal.TotalProtein = roundCents(meal.TotalProtein + entry.Protein)
+		meal.TotalCarbs = roundCents(meal.TotalCarbs + entry.Carbs)
+		meal.TotalFats = roundCents(meal.TotalFats + entry.Fat)
+	}
+
+	nutrition := &models.DailyNutrition{Date: date}
+	for _, mealType := range models.MealTypes {
+		meal, ok := byMeal[mealType]
+		if !ok {
+			meal = &models.MealNutrition{Meal: mealType}
+		}
+		nutrition.Meals = append(nutrition.Meals, meal)
+		nutrition.TotalCalories = roundCents(nutrition.TotalCalories + meal.TotalCalories)
+		nutrition.TotalProtein = roundCents(nutrition.TotalProtein + meal.TotalProtein)
+		nutrition.TotalCarbs = roundCents(nutrition.TotalCarbs + meal.TotalCarbs)
+		nutrition.TotalFats = roundCents(nutrition.TotalFats + meal.TotalFats)
+	}
+
+	return nutrition, nil
+}
+
+// GetNutritionHistory returns one row per day of the range, read from the
+// daily_entries rollups and zero for days without entries.
+func (r *memoryFoodEntryRepository) GetNutritionHistory(ctx context.Context, userID int, startDate, endDate time.Time) ([]*models.DailyNutrition, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var history []*models.DailyNutrition
+	for currentDate := startDate; !currentDate.After(endDate); currentDate = currentDate.AddDate(0, 0, 1) {
+		date, _ := time.Parse("2006-01-02", dateKey(currentDate))
+		totals := r.db.dailyTotals[dailyKey{userID: userID, date: dateKey(currentDate)}]
+		history = append(history, &models.DailyNutrition{
+			Date:          date,
+			TotalCalories: totals.calories,
+			TotalProtein:  totals.protein,
+			TotalCarbs:    totals.carbs,
+			TotalFats:     totals.fats,
+		})
+	}
+
+	return history, nil
+}
+
+// ownedEntry returns a copy of the entry after checking that it belongs to
+// userID. The caller must hold the lock.
+func (db *memoryDB) ownedEntry(userID, entryID int) (models.FoodEntry, error) {
+	entry, ok := db.foodEntries[entryID]
+	if !ok {
+		return models.FoodEntry{}, ErrFoodEntryNotFound
+	}
+	if entry.UserID != userID {
+		return models.FoodEntry{}, ErrFoodEntryForbidden
+	}
+	return entry, nil
+}
+
+func (r *memoryFoodEntryRepository) GetDailyTotalsForUsers(ctx context.Context, userIDs []int, startDate, endDate time.Time) ([]models.DailyTotals, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	start, end := dateKey(startDate), dateKey(endDate)
+	totals := []models.DailyTotals{}
+	for _, userID := range userIDs {
+		for key, day := range r.db.dailyTotals {
+			if key.userID == userID && key.date >= start && key.date <= end {
+				totals = append(totals, models.DailyTotals{
+					UserID:   userID,
+					Date:     key.date,
+					Calories: day.calories,
+					Protein:  day.protein,
+					Carbs:    day.carbs,
+					Fats:     day.fats,
+				})
+			}
+		}
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].UserID != totals[j].UserID {
+			return totals[i].UserID < totals[j].UserID
+		}
+		return totals[i].Date < totals[j].Date
+	})
+	return totals, nil
+}
+
+func (r *memoryFoodEntryRepository) GetLastLogDates(ctx context.Context, userIDs []int, endDate time.Time) (map[int]time.Time, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	wanted := make(map[int]bool, len(userIDs))
+	for _, userID := range userIDs {
+		wanted[userID] = true
+	}
+	end := dateKey(endDate)
+	last := make(map[int]string)
+	for key := range r.db.dailyTotals {
+		if wanted[key.userID] && key.date <= end && key.date > last[key.userID] {
+			last[key.userID] = key.date
+		}
+	}
+
+	byUser := make(map[int]time.Time, len(last))
+	for userID, date := range last {
+		day, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return nil, err
+		}
+		byUser[userID] = day
+	}
+	return byUser, nil
+}
+
+// recalculateDailyTotals rebuilds the rollup of one user and day, removing it
+// once the day has no entries left. The caller must hold the write lock.
+func (db *memoryDB) recalculateDailyTotals(userID int, date time.Time) {
+	var totals dailyTotals
+	for _, entry := range db.foodEntries {
+		if entry.UserID == userID && sameDay(entry.Date, date) {
+			totals.calories = roundCents(totals.calories + entry.Calories)
+			totals.protein = roundCents(totals.protein + entry.Protein)
+			totals.carbs = roundCents(totals.carbs + entry.Carbs)
+			totals.fats = roundCents(totals.fats + entry.Fat)
+		}
+	}
+
+	key := dailyKey{userID: userID, date: dateKey(date)}
+	if totals.calories > 0 || totals.protein > 0 || totals.carbs > 0 || totals.fats > 0 {
+		db.dailyTotals[key] = totals
+	} else {
+		delete(db.dailyTotals, key)
+	}
+}
+
+// roundCents rounds a sum to the two decimals the DECIMAL columns keep.
+func roundCents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	models "HabitBite/backend/Models"
+)
+
+type memoryFoodRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryFoodRepository) FindByFdcID(ctx context.Context, fdcID int) (*models.Food, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	food, ok := r.db.foods[fdcID]
+	if !ok {
+		return nil, ErrFoodNotFound
+	}
+	return &food, nil
+}
+
+// SearchFoods collects candidates by substring like the LIKE fallback of the
+// SQL repository and ranks them with the same rules.
+func (r *memoryFoodRepository) SearchFoods(ctx context.Context, query string, page, pageSize int) (*models.FoodSearchPage, error) {
+	terms := tokenize(query)
+	prefixes := searchPrefixes(terms)
+	if len(prefixes) == 0 {
+		return &models.FoodSearchPage{Foods: []*models.Food{}}, nil
+	}
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	fdcIDs := make([]int, 0, len(r.db.foods))
+	for fdcID := range r.db.foods {
+		fdcIDs = append(fdcIDs, fdcID)
+	}
+	sort.Ints(fdcIDs)
+
+	var candidates []*models.Food
+	for _, fdcID := range fdcIDs {
+		food := r.db.foods[fdcID]
+		text := strings.ToLower(food.Description + " " + food.BrandOwner)
+		for _, prefix := range prefixes {
+			if strings.Contains(text, prefix) {
+				candidates = append(candidates, &food)
+				break
+			}
+		}
+		if len(candidates) == SearchCandidateLimit {
+			break
+		}
+	}
+
+	return rankedPage(terms, candidates, page, pageSize), nil
+}
+
+func (r *memoryFoodRepository) UpsertFoods(ctx context.Context, foods []*models.Food) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	for _, food := range foods {
+		stored := *food
+		stored.CreatedAt = now
+		if existing, ok := r.db.foods[food.FdcID]; ok {
+			stored.CreatedAt = existing.CreatedAt
+		}
+		stored.UpdatedAt = now
+		r.db.foods[food.FdcID] = stored
+	}
+
+	return nil
+}
+
+func (r *memoryFoodRepository) CountFoods(ctx context.Context) (int, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	return len(r.db.foods), nil
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	models "HabitBite/backend/Models"
+)
+
+type memoryJobRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryJobRepository) CreateJob(ctx context.Context, job *models.Job) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	r.db.lastJobID++
+	job.ID = r.db.lastJobID
+	r.db.jobs[job.ID] = *job
+
+	return nil
+}
+
+func (r *memoryJobRepository) GetJob(ctx context.Context, id int) (*models.Job, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	job, ok := r.db.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return &job, nil
+}
+
+func (r *memoryJobRepository) UpdateJob(ctx context.Context, job *models.Job) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.jobs[job.ID]; !ok {
+		return ErrJobNotFound
+	}
+
+	job.UpdatedAt = time.Now()
+	r.db.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *memoryJobRepository) GetUnfinishedJobs(ctx context.Context) ([]models.Job, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	jobs := []models.Job{}
+	for id := 1; id <= r.db.lastJobID; id++ {
+		if job, ok := r.db.jobs[id]; ok && !job.Finished() {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
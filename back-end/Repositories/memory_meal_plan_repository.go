@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	models "HabitBite/backend/Models"
+)
+
+type memoryMealPlanRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryMealPlanRepository) CreateMealPlan(ctx context.Context, plan *models.MealPlan) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if err := r.checkOverlap(plan); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	plan.CreatedAt = now
+	plan.UpdatedAt = now
+
+	r.db.lastMealPlanID++
+	plan.ID = r.db.lastMealPlanID
+	r.db.mealPlans[plan.ID] = r.db.storedPlan(plan)
+	return nil
+}
+
+func (r *memoryMealPlanRepository) ReplaceMealPlan(ctx context.Context, plan *models.MealPlan) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	previous, ok := r.db.mealPlans[plan.ID]
+	if !ok || previous.UserID != plan.UserID {
+		return ErrMealPlanNotFound
+	}
+	if err := r.checkOverlap(plan); err != nil {
+		return err
+	}
+
+	plan.CreatedAt = previous.CreatedAt
+	plan.UpdatedAt = time.Now()
+	r.db.mealPlans[plan.ID] = r.db.storedPlan(plan)
+	return nil
+}
+
+func (r *memoryMealPlanRepository) checkOverlap(plan *models.MealPlan) error {
+	if _, ok := r.db.users[plan.UserID]; !ok {
+		return ErrUserNotFound
+	}
+	for id, other := range r.db.mealPlans {
+		if id != plan.ID && other.UserID == plan.UserID && plan.Overlaps(&other) {
+			return ErrMealPlanOverlap
+		}
+	}
+	return nil
+}
+
+// storedPlan assigns IDs to the items of plan and returns the copy to keep.
+// The caller must hold the write lock.
+func (db *memoryDB) storedPlan(plan *models.MealPlan) models.MealPlan {
+	plan.StartDate = models.DateOnly(plan.StartDate)
+	plan.EndDate = models.DateOnly(plan.EndDate)
+	for i := range plan.Items {
+		db.lastMealPlanItemID++
+		plan.Items[i].ID = db.lastMealPlanItemID
+		plan.Items[i].PlanID = plan.ID
+	}
+
+	stored := *plan
+	stored.Items = append([]models.MealPlanItem(nil), plan.Items...)
+	return stored
+}
+
+func (r *memoryMealPlanRepository) DeleteMealPlan(ctx context.Context, userID, planID int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if plan, ok := r.db.mealPlans[planID]; !ok || plan.UserID != userID {
+		return ErrMealPlanNotFound
+	}
+	delete(r.db.mealPlans, planID)
+	return nil
+}
+
+func (r *memoryMealPlanRepository) GetMealPlan(ctx context.Context, userID, planID int) (*models.MealPlan, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	plan, ok := r.db.mealPlans[planID]
+	if !ok || plan.UserID != userID {
+		return nil, ErrMealPlanNotFound
+	}
+	return copyPlan(plan, true), nil
+}
+
+func (r *memoryMealPlanRepository) GetMealPlans(ctx context.Context, userID int) ([]models.MealPlan, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	plans := []models.MealPlan{}
+	for _, plan := range r.db.mealPlans {
+		if plan.UserID == userID {
+			plans = append(plans, *copyPlan(plan, false))
+		}
+	}
+	sort.Slice(plans, func(i, j int) bool {
+		return plans[i].StartDate.After(plans[j].StartDate)
+	})
+	return plans, nil
+}
+
+func (r *memoryMealPlanRepository) GetActiveMealPlan(ctx context.Context, userID int, date time.Time) (*models.MealPlan, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, plan := range r.db.mealPlans {
+		if _, ok := plan.DayOf(date); ok && plan.UserID == userID {
+			return copyPlan(plan, true), nil
+		}
+	}
+	return nil, ErrMealPlanNotFound
+}
+
+func (r *memoryMealPlanRepository) GetMealPlanItem(ctx context.Context, userID, itemID int) (*models.MealPlanItem, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, plan := range r.db.mealPlans {
+		if plan.UserID != userID {
+			continue
+		}
+		for _, item := range plan.Items {
+			if item.ID == itemID {
+				return &item, nil
+			}
+		}
+	}
+	return nil, ErrMealPlanItemNotFound
+}
+
+// copyPlan returns a copy of a stored plan that callers may modify, with or
+// without its items.
+func copyPlan(plan models.MealPlan, withItems bool) *models.MealPlan {
+	if withItems {
+		plan.Items = append([]models.MealPlanItem{}, plan.Items...)
+	} else {
+		plan.Items = nil
+	}
+	return &plan
+}
@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	models "HabitBite/backend/Models"
+)
+
+type memoryMessageRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryMessageRepository) GetOrCreateConversation(ctx context.Context, userID, dietitianID int) (*models.Conversation, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.users[userID]; !ok {
+		return nil, ErrUserNotFound
+	}
+	for _, conversation := range r.db.conversations {
+		if conversation.UserID == userID && conversation.DietitianID == dietitianID {
+			return &conversation, nil
+		}
+	}
+
+	r.db.lastConversationID++
+	conversation := models.Conversation{
+		ID:          r.db.lastConversationID,
+		UserID:      userID,
+		DietitianID: dietitianID,
+		CreatedAt:   time.Now(),
+	}
+	r.db.conversations[conversation.ID] = conversation
+	return &conversation, nil
+}
+
+func (r *memoryMessageRepository) GetConversation(ctx context.Context, conversationID int) (*models.Conversation, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	conversation, ok := r.db.conversations[conversationID]
+	if !ok {
+		return nil, ErrConversationNotFound
+	}
+	return &conversation, nil
+}
+
+func (r *memoryMessageRepository) GetConversations(ctx context.Context, participantID int) ([]models.ConversationSummary, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	conversations := []models.ConversationSummary{}
+	for _, conversation := range r.db.conversations {
+		if !conversation.HasParticipant(participantID) {
+			continue
+		}
+
+		summary := models.ConversationSummary{
+			Conversation:  conversation,
+			UserName:      r.db.users[conversation.UserID].FullName,
+			DietitianName: r.db.users[conversation.DietitianID].FullName,
+		}
+		messages := r.db.messages[conversation.ID]
+		if len(messages) > 0 {
+			body := messages[len(messages)-1].Body
+			summary.LastMessage = &body
+		}
+		for _, message := range messages {
+			if message.SenderID != participantID && message.ReadAt == nil {
+				summary.UnreadCount++
+			}
+		}
+		conversations = append(conversations, summary)
+	}
+
+	lastActive := func(c *models.ConversationSummary) time.Time {
+		if c.LastMessageAt != nil {
+			return *c.LastMessageAt
+		}
+		return c.CreatedAt
+	}
+	sort.Slice(conversations, func(i, j int) bool {
+		a, b := lastActive(&conversations[i]), lastActive(&conversations[j])
+		if !a.Equal(b) {
+			return a.After(b)
+		}
+		return conversations[i].ID > conversations[j].ID
+	})
+	return conversations, nil
+}
+
+func (r *memoryMessageRepository) CreateMessage(ctx context.Context, message *models.Message) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	conversation, ok := r.db.conversations[message.ConversationID]
+	if !ok {
+		return ErrConversationNotFound
+	}
+
+	r.db.lastMessageID++
+	message.ID = r.db.lastMessageID
+	message.CreatedAt = time.Now()
+	message.ReadAt = nil
+	r.db.messages[conversation.ID] = append(r.db.messages[conversation.ID], *message)
+
+	conversation.LastMessageAt = &message.CreatedAt
+	r.db.conversations[conversation.ID] = conversation
+	return nil
+}
+
+func (r *memoryMessageRepository) GetMessages(ctx context.Context, conversationID, beforeID, limit int) ([]models.Message, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	// Messages are stored in ascending ID order.
+	stored := r.db.messages[conversationID]
+	messages := []models.Message{}
+	for i := len(stored) - 1; i >= 0 && len(messages) < limit; i-- {
+		if beforeID > 0 && stored[i].ID >= beforeID {
+			continue
+		}
+		messages = append(messages, stored[i])
+	}
+	return messages, nil
+}
+
+func (r *memoryMessageRepository) MarkRead(ctx context.Context, conversationID, readerID, upTo int) (*models.ReadReceipt, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	receipt := &models.ReadReceipt{
+		ConversationID: conversationID,
+		ReaderID:       readerID,
+		ReadAt:         time.Now(),
+	}
+	messages := r.db.messages[conversationID]
+	for i := range messages {
+		message := &messages[i]
+		if message.SenderID == readerID || message.ReadAt != nil || (upTo > 0 && message.ID > upTo) {
+			continue
+		}
+		readAt := receipt.ReadAt
+		message.ReadAt = &readAt
+		receipt.UpTo = message.ID
+		receipt.Count++
+	}
+	return receipt, nil
+}
@@ -0,0 +1,195 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	models "HabitBite/backend/Models"
+)
+
+type memoryRefreshTokenRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryRefreshTokenRepository) CreateSession(ctx context.Context, session *models.Session, token *models.RefreshToken) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.users[session.UserID]; !ok {
+		return wrapDatabaseError(errMemoryConstraint)
+	}
+	for _, stored := range r.db.sessions {
+		if stored.FamilyID == session.FamilyID {
+			return wrapDatabaseError(errMemoryConstraint)
+		}
+	}
+	if err := r.db.insertRefreshToken(token); err != nil {
+		return err
+	}
+
+	r.db.lastSessionID++
+	session.ID = r.db.lastSessionID
+	r.db.sessions[session.ID] = *session
+	return nil
+}
+
+// insertRefreshToken stores token under a new ID. The caller must hold the
+// write lock.
+func (db *memoryDB) insertRefreshToken(token *models.RefreshToken) error {
+	if _, ok := db.users[token.UserID]; !ok {
+		return wrapDatabaseError(errMemoryConstraint)
+	}
+	for _, stored := range db.refreshTokens {
+		if stored.TokenHash == token.TokenHash {
+			return wrapDatabaseError(errMemoryConstraint)
+		}
+	}
+
+	db.lastRefreshTokenID++
+	token.ID = db.lastRefreshTokenID
+	db.refreshTokens[token.ID] = *token
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) RotateRefreshToken(ctx context.Context, tokenHash string, next *models.RefreshToken, userAgent, ipAddress string) (*models.Session, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	current, ok := r.db.findRefreshToken(tokenHash)
+	if !ok {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	now := next.CreatedAt
+	switch {
+	case current.RevokedAt != nil || !current.ExpiresAt.After(now):
+		return nil, ErrRefreshTokenInvalid
+	case current.RotatedAt != nil:
+		r.db.revokeRefreshTokenFamily(current.FamilyID, now)
+		return nil, ErrRefreshTokenReused
+	}
+
+	next.UserID = current.UserID
+	next.FamilyID = current.FamilyID
+	if err := r.db.insertRefreshToken(next); err != nil {
+		return nil, err
+	}
+	current.RotatedAt = &now
+	r.db.refreshTokens[current.ID] = current
+
+	for id, session := range r.db.sessions {
+		if session.FamilyID == current.FamilyID {
+			session.LastUsedAt = now
+			session.UserAgent = userAgent
+			session.IPAddress = ipAddress
+			r.db.sessions[id] = session
+			return &session, nil
+		}
+	}
+	return nil, ErrSessionNotFound
+}
+
+func (r *memoryRefreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, tokenHash string, now time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	token, ok := r.db.findRefreshToken(tokenHash)
+	if !ok {
+		return ErrRefreshTokenInvalid
+	}
+	r.db.revokeRefreshTokenFamily(token.FamilyID, now)
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) GetSessions(ctx context.Context, userID int, now time.Time) ([]models.Session, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	sessions := []models.Session{}
+	for _, session := range r.db.sessions {
+		if session.UserID == userID && sessionActive(&session, now) {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].LastUsedAt.Equal(sessions[j].LastUsedAt) {
+			return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+		}
+		return sessions[i].ID > sessions[j].ID
+	})
+	return sessions, nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeSession(ctx context.Context, userID, sessionID int, now time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	session, ok := r.db.sessions[sessionID]
+	if !ok || session.UserID != userID || !sessionActive(&session, now) {
+		return ErrSessionNotFound
+	}
+	r.db.revokeRefreshTokenFamily(session.FamilyID, now)
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeSessions(ctx context.Context, userID int, now time.Time) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	active := 0
+	for _, session := range r.db.sessions {
+		if session.UserID != userID {
+			continue
+		}
+		if sessionActive(&session, now) {
+			active++
+		}
+		r.db.revokeRefreshTokenFamily(session.FamilyID, now)
+	}
+	return active, nil
+}
+
+func (r *memoryRefreshTokenRepository) IsSessionActive(ctx context.Context, familyID string, now time.Time) (bool, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, session := range r.db.sessions {
+		if session.FamilyID == familyID {
+			return sessionActive(&session, now), nil
+		}
+	}
+	return false, nil
+}
+
+func sessionActive(session *models.Session, now time.Time) bool {
+	return session.RevokedAt == nil && session.LastUsedAt.After(sessionActiveSince(now))
+}
+
+// findRefreshToken looks a token up by hash. The caller must hold the lock.
+func (db *memoryDB) findRefreshToken(tokenHash string) (models.RefreshToken, bool) {
+	for _, token := range db.refreshTokens {
+		if token.TokenHash == tokenHash {
+			return token, true
+		}
+	}
+	return models.RefreshToken{}, false
+}
+
+// revokeRefreshTokenFamily revokes a session and its refresh tokens. The
+// caller must hold the write lock.
+func (db *memoryDB) revokeRefreshTokenFamily(familyID string, now time.Time) {
+	revokedAt := now
+	for id, token := range db.refreshTokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &revokedAt
+			db.refreshTokens[id] = token
+		}
+	}
+	for id, session := range db.sessions {
+		if session.FamilyID == familyID && session.RevokedAt == nil {
+			session.RevokedAt = &revokedAt
+			db.sessions[id] = session
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	models "HabitBite/backend/Models"
+)
+
+type memoryTwoFactorRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryTwoFactorRepository) GetTwoFactor(ctx context.Context, userID int) (*models.TwoFactor, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	twoFactor, ok := r.db.twoFactor[userID]
+	if !ok {
+		return nil, ErrTwoFactorNotFound
+	}
+	return &twoFactor, nil
+}
+
+func (r *memoryTwoFactorRepository) IsTwoFactorEnabled(ctx context.Context, userID int) (bool, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	twoFactor, ok := r.db.twoFactor[userID]
+	return ok && twoFactor.EnabledAt != nil, nil
+}
+
+func (r *memoryTwoFactorRepository) StartTwoFactor(ctx context.Context, twoFactor *models.TwoFactor) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.users[twoFactor.UserID]; !ok {
+		return wrapDatabaseError(errMemoryConstraint)
+	}
+	if existing, ok := r.db.twoFactor[twoFactor.UserID]; ok && existing.EnabledAt != nil {
+		return ErrTwoFactorAlreadyEnabled
+	}
+
+	stored := *twoFactor
+	stored.EnabledAt = nil
+	r.db.twoFactor[twoFactor.UserID] = stored
+	return nil
+}
+
+func (r *memoryTwoFactorRepository) EnableTwoFactor(ctx context.Context, userID int, step int64, codeHashes []string, now time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	twoFactor, ok := r.db.twoFactor[userID]
+	if !ok || twoFactor.EnabledAt != nil {
+		return ErrTwoFactorNotFound
+	}
+	enabledAt := now
+	twoFactor.EnabledAt = &enabledAt
+	twoFactor.LastUsedStep = step
+	r.db.twoFactor[userID] = twoFactor
+
+	r.db.replaceRecoveryCodes(userID, codeHashes, now)
+	return nil
+}
+
+func (r *memoryTwoFactorRepository) UseTwoFactorStep(ctx context.Context, userID int, step int64) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	twoFactor, ok := r.db.twoFactor[userID]
+	if !ok || twoFactor.LastUsedStep >= step {
+		return ErrTwoFactorStepUsed
+	}
+	twoFactor.LastUsedStep = step
+	r.db.twoFactor[userID] = twoFactor
+	return nil
+}
+
+func (r *memoryTwoFactorRepository) DisableTwoFactor(ctx context.Context, userID int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	delete(r.db.twoFactor, userID)
+	r.db.replaceRecoveryCodes(userID, nil, time.Time{})
+	return nil
+}
+
+func (r *memoryTwoFactorRepository) RecordTwoFactorFailure(ctx context.Context, userID, maxFailures int, lockedUntil time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	twoFactor, ok := r.db.twoFactor[userID]
+	if !ok {
+		return nil
+	}
+	twoFactor.FailedAttempts++
+	if twoFactor.FailedAttempts >= maxFailures {
+		twoFactor.FailedAttempts = 0
+		until := lockedUntil
+		twoFactor.LockedUntil = &until
+	}
+	r.db.twoFactor[userID] = twoFactor
+	return nil
+}
+
+func (r *memoryTwoFactorRepository) ResetTwoFactorFailures(ctx context.Context, userID int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if twoFactor, ok := r.db.twoFactor[userID]; ok {
+		twoFactor.FailedAttempts = 0
+		r.db.twoFactor[userID] = twoFactor
+	}
+	return nil
+}
+
+func (r *memoryTwoFactorRepository) CreateMFAChallenge(ctx context.Context, challenge *models.MFAChallenge) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.users[challenge.UserID]; !ok {
+		return wrapDatabaseError(errMemoryConstraint)
+	}
+	if _, ok := r.db.mfaChallenges[challenge.ID]; ok {
+		return wrapDatabaseError(errMemoryConstraint)
+	}
+	for id, stored := range r.db.mfaChallenges {
+		if stored.UserID == challenge.UserID && !stored.ExpiresAt.After(challenge.CreatedAt) {
+			delete(r.db.mfaChallenges, id)
+		}
+	}
+	r.db.mfaChallenges[challenge.ID] = *challenge
+	return nil
+}
+
+func (r *memoryTwoFactorRepository) AttemptMFAChallenge(ctx context.Context, id string, userID, maxAttempts int, now time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	challenge, ok := r.db.mfaChallenges[id]
+	if !ok || challenge.UserID != userID || challenge.UsedAt != nil || !challenge.ExpiresAt.After(now) || challenge.Attempts >= maxAttempts {
+		return ErrMFAChallengeInvalid
+	}
+	challenge.Attempts++
+	r.db.mfaChallenges[id] = challenge
+	return nil
+}
+
+func (r *memoryTwoFactorRepository) UseMFAChallenge(ctx context.Context, id string, now time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	challenge, ok := r.db.mfaChallenges[id]
+	if !ok || challenge.UsedAt != nil {
+		return ErrMFAChallengeInvalid
+	}
+	usedAt := now
+	challenge.UsedAt = &usedAt
+	r.db.mfaChallenges[id] = challenge
+	return nil
+}
+
+func (r *memoryTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string, now time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.users[userID]; !ok {
+		return wrapDatabaseError(errMemoryConstraint)
+	}
+	r.db.replaceRecoveryCodes(userID, codeHashes, now)
+	return nil
+}
+
+// replaceRecoveryCodes deletes the recovery codes of a user and stores the
+// given ones. The caller must hold the write lock.
+func (db *memoryDB) replaceRecoveryCodes(userID int, codeHashes []string, now time.Time) {
+	for id, code := range db.recoveryCodes {
+		if code.UserID == userID {
+			delete(db.recoveryCodes, id)
+		}
+	}
+	for _, codeHash := range codeHashes {
+		db.lastRecoveryCodeID++
+		db.recoveryCodes[db.lastRecoveryCodeID] = models.RecoveryCode{
+			ID:        db.lastRecoveryCodeID,
+			UserID:    userID,
+			CodeHash:  codeHash,
+			CreatedAt: now,
+		}
+	}
+}
+
+func (r *memoryTwoFactorRepository) UseRecoveryCode(ctx context.Context, userID int, codeHash string, now time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for id, code := range r.db.recoveryCodes {
+		if code.UserID == userID && code.CodeHash == codeHash && code.UsedAt == nil {
+			usedAt := now
+			code.UsedAt = &usedAt
+			r.db.recoveryCodes[id] = code
+			return nil
+		}
+	}
+	return ErrRecoveryCodeInvalid
+}
+
+func (r *memoryTwoFactorRepository) CountRecoveryCodes(ctx context.Context, userID int) (int, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	count := 0
+	for _, code := range r.db.recoveryCodes {
+		if code.UserID == userID && code.UsedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
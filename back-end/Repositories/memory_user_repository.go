@@ -0,0 +1,559 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	goals "HabitBite/backend/Goals"
+	models "HabitBite/backend/Models"
+)
+
+// errMemoryConstraint stands in for the unique and foreign key violations the
+// SQL schema would raise.
+var errMemoryConstraint = errors.New("constraint violation")
+
+type memoryUserRepository struct {
+	db      *memoryDB
+	planner *goals.Planner
+}
+
+func (r *memoryUserRepository) CreateUser(ctx context.Context, user *models.User) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, existing := range r.db.users {
+		if existing.Email == user.Email || existing.Username == user.Username {
+			return ErrUserAlreadyExists
+		}
+	}
+
+	now := time.Now()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	r.db.lastUserID++
+	user.ID = r.db.lastUserID
+	r.db.users[user.ID] = *user
+
+	r.db.goals[user.ID] = *r.planner.Goals(user, user.DailyCalorieGoal)
+
+	return nil
+}
+
+func (r *memoryUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.findUser(func(user models.User) bool { return user.Email == email })
+}
+
+func (r *memoryUserRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.findUser(func(user models.User) bool { return user.Username == username })
+}
+
+func (r *memoryUserRepository) FindByID(ctx context.Context, id int) (*models.User, error) {
+	return r.findUser(func(user models.User) bool { return user.ID == id })
+}
+
+func (r *memoryUserRepository) findUser(match func(models.User) bool) (*models.User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, user := range r.db.sortedUsers() {
+		if match(user) {
+			return &user, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (r *memoryUserRepository) UpdateUser(ctx context.Context, user *models.User) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	existing, ok := r.db.users[user.ID]
+	if !ok {
+		return ErrUserNotFound
+	}
+	for _, other := range r.db.users {
+		if other.ID != user.ID && (other.Email == user.Email || other.Username == user.Username) {
+			return wrapDatabaseError(errMemoryConstraint)
+		}
+	}
+
+	user.CreatedAt = existing.CreatedAt
+	user.EmailVerifiedAt = existing.EmailVerifiedAt
+	user.UpdatedAt = time.Now()
+	r.db.users[user.ID] = *user
+
+	r.saveCalorieTargets(user, user.DailyCalorieGoal)
+
+	return nil
+}
+
+func (r *memoryUserRepository) DeleteUser(ctx context.Context, id int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.users[id]; !ok {
+		return ErrUserNotFound
+	}
+
+	delete(r.db.users, id)
+	delete(r.db.goals, id)
+	for key := range r.db.subscriptions {
+		if key.userID == id || key.dietitianID == id {
+			delete(r.db.subscriptions, key)
+		}
+	}
+	for entryID, entry := range r.db.foodEntries {
+		if entry.UserID == id {
+			delete(r.db.foodEntries, entryID)
+		}
+	}
+	for key := range r.db.dailyTotals {
+		if key.userID == id {
+			delete(r.db.dailyTotals, key)
+		}
+	}
+	for entryID, entry := range r.db.weightEntries {
+		if entry.UserID == id {
+			delete(r.db.weightEntries, entryID)
+		}
+	}
+	for applicationID, application := range r.db.applications {
+		if application.UserID == id {
+			delete(r.db.applications, applicationID)
+		}
+	}
+	delete(r.db.profiles, id)
+	for planID, plan := range r.db.mealPlans {
+		if plan.UserID == id || plan.DietitianID == id {
+			delete(r.db.mealPlans, planID)
+		}
+	}
+	for conversationID, conversation := range r.db.conversations {
+		if conversation.HasParticipant(id) {
+			delete(r.db.conversations, conversationID)
+			delete(r.db.messages, conversationID)
+		}
+	}
+	r.db.deleteComments(func(comment *models.Comment) bool {
+		return comment.UserID == id || comment.DietitianID == id || comment.AuthorID == id
+	})
+	for alertID, alert := range r.db.alerts {
+		if alert.UserID == id || alert.DietitianID == id {
+			delete(r.db.alerts, alertID)
+		}
+	}
+	for tokenID, token := range r.db.refreshTokens {
+		if token.UserID == id {
+			delete(r.db.refreshTokens, tokenID)
+		}
+	}
+	for sessionID, session := range r.db.sessions {
+		if session.UserID == id {
+			delete(r.db.sessions, sessionID)
+		}
+	}
+	for tokenID, token := range r.db.userTokens {
+		if token.UserID == id {
+			delete(r.db.userTokens, tokenID)
+		}
+	}
+	delete(r.db.twoFactor, id)
+	r.db.replaceRecoveryCodes(id, nil, time.Time{})
+	for challengeID, challenge := range r.db.mfaChallenges {
+		if challenge.UserID == id {
+			delete(r.db.mfaChallenges, challengeID)
+		}
+	}
+
+	return nil
+}
+
+func (r *memoryUserRepository) MarkEmailVerified(ctx context.Context, userID int, at time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	user, ok := r.db.users[userID]
+	if ok && user.EmailVerifiedAt == nil {
+		verifiedAt := at
+		user.EmailVerifiedAt = &verifiedAt
+		r.db.users[userID] = user
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) IsEmailVerified(ctx context.Context, userID int) (bool, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	user, ok := r.db.users[userID]
+	if !ok {
+		return false, ErrUserNotFound
+	}
+	return user.EmailVerifiedAt != nil, nil
+}
+
+func (r *memoryUserRepository) GetUserGoals(ctx context.Context, userID int) (*models.UserGoals, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if goals, ok := r.db.goals[userID]; ok {
+		return &goals, nil
+	}
+
+	user, ok := r.db.users[userID]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+
+	generated := r.planner.Goals(&user, user.DailyCalorieGoal)
+	if err := r.storeGoals(generated); err != nil {
+		return nil, err
+	}
+	return generated, nil
+}
+
+func (r *memoryUserRepository) UpdateUserGoals(ctx context.Context, goals *models.UserGoals) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	return r.storeGoals(goals)
+}
+
+// storeGoals mirrors userRepository.UpdateUserGoals: missing macro targets are
+// derived from the calorie target and the user's calorie goal follows it. The
+// caller must hold the lock.
+func (r *memoryUserRepository) storeGoals(goals *models.UserGoals) error {
+	user, ok := r.db.users[goals.UserID]
+	if !ok {
+		return ErrUserNotFound
+	}
+
+	if goals.TargetProtein == 0 || goals.TargetCarbs == 0 || goals.TargetFats == 0 {
+		r.planner.FillMacros(goals, &user)
+	}
+	r.db.goals[goals.UserID] = *goals
+
+	user.DailyCalorieGoal = goals.TargetCalories
+	user.UpdatedAt = time.Now()
+	r.db.users[user.ID] = user
+
+	return nil
+}
+
+// saveCalorieTargets mirrors userRepository.saveCalorieTargets. The caller
+// must hold the lock.
+func (r *memoryUserRepository) saveCalorieTargets(user *models.User, calories int) {
+	generated := r.planner.Goals(user, calories)
+	if existing, ok := r.db.goals[user.ID]; ok {
+		generated.TargetWeight = existing.TargetWeight
+	}
+	r.db.goals[user.ID] = *generated
+}
+
+func (r *memoryUserRepository) SyncUserCalorieGoal(ctx context.Context, userID int, calorieGoal int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	user, ok := r.db.users[userID]
+	if !ok {
+		return ErrUserNotFound
+	}
+	user.DailyCalorieGoal = calorieGoal
+	user.UpdatedAt = time.Now()
+	r.db.users[userID] = user
+
+	r.saveCalorieTargets(&user, calorieGoal)
+
+	return nil
+}
+
+func (r *memoryUserRepository) GetAllUsers(ctx context.Context) ([]models.User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	return r.db.sortedUsers(), nil
+}
+
+func (r *memoryUserRepository) GetUsersAfter(ctx context.Context, afterID int, role string, limit int) ([]models.User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	users := []models.User{}
+	for _, user := range r.db.sortedUsers() {
+		if len(users) == limit {
+			break
+		}
+		if user.ID > afterID && (role == "" || user.Role == role) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (r *memoryUserRepository) CountUsers(ctx context.Context, role string) (int, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	count := 0
+	for _, user := range r.db.users {
+		if role == "" || user.Role == role {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryUserRepository) GetGoalsForUsers(ctx context.Context, userIDs []int) (map[int]models.UserGoals, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	byUser := make(map[int]models.UserGoals, len(userIDs))
+	for _, userID := range userIDs {
+		if goals, ok := r.db.goals[userID]; ok {
+			byUser[userID] = goals
+		}
+	}
+	return byUser, nil
+}
+
+func (r *memoryUserRepository) GetDietitiansForUsers(ctx context.Context, userIDs []int) (map[int][]int, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	byUser := make(map[int][]int, len(userIDs))
+	for _, userID := range userIDs {
+		for key, sub := range r.db.subscriptions {
+			if key.userID == userID && sub.Status == models.SubscriptionAccepted {
+				byUser[userID] = append(byUser[userID], key.dietitianID)
+			}
+		}
+		sort.Ints(byUser[userID])
+	}
+	for userID, dietitianIDs := range byUser {
+		if len(dietitianIDs) == 0 {
+			delete(byUser, userID)
+		}
+	}
+	return byUser, nil
+}
+
+func (r *memoryUserRepository) GetSubscribedUsers(ctx context.Context, dietitianID int) ([]models.User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	users := []models.User{}
+	for _, user := range r.db.sortedUsers() {
+		key := subscription{userID: user.ID, dietitianID: dietitianID}
+		if sub, ok := r.db.subscriptions[key]; ok && sub.Status == models.SubscriptionAccepted {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (r *memoryUserRepository) IsUserSubscribedToDietitian(ctx context.Context, userID string, dietitianID int) (bool, error) {
+	userIDInt, err := strconv.Atoi(userID)
+	if err != nil {
+		return false, err
+	}
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	sub, ok := r.db.subscriptions[subscription{userID: userIDInt, dietitianID: dietitianID}]
+	if !ok || sub.Status != models.SubscriptionAccepted {
+		return false, nil
+	}
+	return r.db.users[dietitianID].Role == models.RoleDietitian, nil
+}
+
+func (r *memoryUserRepository) SubscribeUserToDietitian(ctx context.Context, userID int, dietitianID int) (*models.DietitianSubscription, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if dietitian, ok := r.db.users[dietitianID]; !ok || dietitian.Role != models.RoleDietitian {
+		return nil, ErrDietitianNotFound
+	}
+	if _, ok := r.db.users[userID]; !ok {
+		return nil, wrapDatabaseError(errMemoryConstraint)
+	}
+
+	key := subscription{userID: userID, dietitianID: dietitianID}
+	sub, ok := r.db.subscriptions[key]
+	if ok && (sub.Status == models.SubscriptionPending || sub.Status == models.SubscriptionAccepted) {
+		return &sub, nil
+	}
+
+	sub = models.DietitianSubscription{
+		UserID:      userID,
+		DietitianID: dietitianID,
+		Status:      models.SubscriptionPending,
+		RequestedAt: time.Now(),
+	}
+	r.db.subscriptions[key] = sub
+	return &sub, nil
+}
+
+func (r *memoryUserRepository) RespondToSubscription(ctx context.Context, userID int, dietitianID int, accept bool) (*models.DietitianSubscription, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	key := subscription{userID: userID, dietitianID: dietitianID}
+	sub, ok := r.db.subscriptions[key]
+	if !ok {
+		return nil, ErrSubscriptionNotFound
+	}
+	if sub.Status != models.SubscriptionPending {
+		return nil, ErrSubscriptionNotPending
+	}
+
+	sub.Status = models.SubscriptionDeclined
+	if accept {
+		if capacity := r.db.profiles[dietitianID].ClientCapacity; capacity != nil {
+			clients := 0
+			for other, existing := range r.db.subscriptions {
+				if other.dietitianID == dietitianID && existing.Status == models.SubscriptionAccepted {
+					clients++
+				}
+			}
+			if clients >= *capacity {
+				return nil, ErrDietitianAtCapacity
+			}
+		}
+		sub.Status = models.SubscriptionAccepted
+	}
+
+	now := time.Now()
+	sub.RespondedAt = &now
+	r.db.subscriptions[key] = sub
+	return &sub, nil
+}
+
+func (r *memoryUserRepository) UnsubscribeUserFromDietitian(ctx context.Context, userID int, dietitianID int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	key := subscription{userID: userID, dietitianID: dietitianID}
+	sub, ok := r.db.subscriptions[key]
+	if !ok || (sub.Status != models.SubscriptionPending && sub.Status != models.SubscriptionAccepted) {
+		return ErrSubscriptionNotFound
+	}
+
+	now := time.Now()
+	sub.Status = models.SubscriptionEnded
+	sub.EndedAt = &now
+	r.db.subscriptions[key] = sub
+	return nil
+}
+
+func (r *memoryUserRepository) GetSubscriptionRequests(ctx context.Context, dietitianID int) ([]models.DietitianSubscription, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	requests := []models.DietitianSubscription{}
+	for key, sub := range r.db.subscriptions {
+		if key.dietitianID == dietitianID && sub.Status == models.SubscriptionPending {
+			sub.FullName = r.db.users[key.userID].FullName
+			requests = append(requests, sub)
+		}
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].RequestedAt.Before(requests[j].RequestedAt)
+	})
+	return requests, nil
+}
+
+func (r *memoryUserRepository) GetUserSubscriptions(ctx context.Context, userID int) ([]models.DietitianSubscription, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	subscriptions := []models.DietitianSubscription{}
+	for key, sub := range r.db.subscriptions {
+		if key.userID == userID {
+			sub.FullName = r.db.users[key.dietitianID].FullName
+			subscriptions = append(subscriptions, sub)
+		}
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].RequestedAt.After(subscriptions[j].RequestedAt)
+	})
+	return subscriptions, nil
+}
+
+func (r *memoryUserRepository) GetAvailableDietitians(ctx context.Context) ([]models.User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	dietitians := []models.User{}
+	for _, user := range r.db.sortedUsers() {
+		if user.Role == models.RoleDietitian {
+			dietitians = append(dietitians, user)
+		}
+	}
+	return dietitians, nil
+}
+
+// GetUserProgress returns the totals of the first 30 days with entries, like
+// the SQL query it mirrors.
+func (r *memoryUserRepository) GetUserProgress(ctx context.Context, userID string) (map[string]interface{}, error) {
+	userIDInt, err := strconv.Atoi(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var dates []string
+	for key := range r.db.dailyTotals {
+		if key.userID == userIDInt {
+			dates = append(dates, key.date)
+		}
+	}
+	sort.Strings(dates)
+	if len(dates) > 30 {
+		dates = dates[:30]
+	}
+
+	calories := make([]float64, len(dates))
+	protein := make([]float64, len(dates))
+	carbs := make([]float64, len(dates))
+	fats := make([]float64, len(dates))
+	for i, date := range dates {
+		totals := r.db.dailyTotals[dailyKey{userID: userIDInt, date: date}]
+		calories[i] = totals.calories
+		protein[i] = totals.protein
+		carbs[i] = totals.carbs
+		fats[i] = totals.fats
+	}
+	if dates == nil {
+		dates = []string{}
+	}
+
+	return map[string]interface{}{
+		"nutritionHistory": map[string]interface{}{
+			"dates":    dates,
+			"calories": calories,
+			"protein":  protein,
+			"carbs":    carbs,
+			"fats":     fats,
+		},
+	}, nil
+}
+
+// sortedUsers returns copies of all users ordered by ID. The caller must hold
+// the lock.
+func (db *memoryDB) sortedUsers() []models.User {
+	users := make([]models.User, 0, len(db.users))
+	for _, user := range db.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+	return users
+}
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	models "HabitBite/backend/Models"
+)
+
+type memoryUserTokenRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryUserTokenRepository) CreateUserToken(ctx context.Context, token *models.UserToken) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.users[token.UserID]; !ok {
+		return wrapDatabaseError(errMemoryConstraint)
+	}
+	for id, stored := range r.db.userTokens {
+		if stored.TokenHash == token.TokenHash {
+			return wrapDatabaseError(errMemoryConstraint)
+		}
+		if stored.UserID == token.UserID && stored.Purpose == token.Purpose && stored.UsedAt == nil {
+			delete(r.db.userTokens, id)
+		}
+	}
+
+	r.db.lastUserTokenID++
+	token.ID = r.db.lastUserTokenID
+	r.db.userTokens[token.ID] = *token
+	return nil
+}
+
+func (r *memoryUserTokenRepository) VerifyEmail(ctx context.Context, tokenHash string, now time.Time) (*models.UserToken, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	id, token, err := r.findValid(models.TokenEmailVerification, tokenHash, now)
+	if err != nil {
+		return nil, err
+	}
+	user, ok := r.db.users[token.UserID]
+	if !ok {
+		return nil, ErrUserTokenInvalid
+	}
+
+	if user.EmailVerifiedAt == nil {
+		verifiedAt := now
+		user.EmailVerifiedAt = &verifiedAt
+		r.db.users[user.ID] = user
+	}
+	usedAt := now
+	token.UsedAt = &usedAt
+	r.db.userTokens[id] = token
+	return &token, nil
+}
+
+func (r *memoryUserTokenRepository) ResetPassword(ctx context.Context, tokenHash, passwordHash string, now time.Time) (*models.UserToken, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	id, token, err := r.findValid(models.TokenPasswordReset, tokenHash, now)
+	if err != nil {
+		return nil, err
+	}
+	user, ok := r.db.users[token.UserID]
+	if !ok {
+		return nil, ErrUserTokenInvalid
+	}
+
+	user.PasswordHash = passwordHash
+	user.UpdatedAt = now
+	r.db.users[user.ID] = user
+
+	for otherID, other := range r.db.userTokens {
+		if other.UserID == token.UserID && other.Purpose == models.TokenPasswordReset && other.UsedAt == nil {
+			delete(r.db.userTokens, otherID)
+		}
+	}
+	usedAt := now
+	token.UsedAt = &usedAt
+	r.db.userTokens[id] = token
+	return &token, nil
+}
+
+// findValid returns the unused, unexpired token with the hash and purpose.
+// The caller must hold the lock.
+func (r *memoryUserTokenRepository) findValid(purpose, tokenHash string, now time.Time) (int, models.UserToken, error) {
+	for id, token := range r.db.userTokens {
+		if token.TokenHash != tokenHash || token.Purpose != purpose {
+			continue
+		}
+		if token.UsedAt != nil || !token.ExpiresAt.After(now) {
+			return 0, models.UserToken{}, ErrUserTokenInvalid
+		}
+		return id, token, nil
+	}
+	return 0, models.UserToken{}, ErrUserTokenInvalid
+}
+
+func (r *memoryUserTokenRepository) LatestUserTokenAt(ctx context.Context, userID int, purpose string) (*time.Time, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var latest *time.Time
+	for _, token := range r.db.userTokens {
+		if token.UserID == userID && token.Purpose == purpose && (latest == nil || token.CreatedAt.After(*latest)) {
+			createdAt := token.CreatedAt
+			latest = &createdAt
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	models "HabitBite/backend/Models"
+)
+
+type memoryWeightRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryWeightRepository) CreateWeightEntry(ctx context.Context, entry *models.WeightEntry) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.users[entry.UserID]; !ok {
+		return wrapDatabaseError(errMemoryConstraint)
+	}
+
+	r.db.lastWeightID++
+	entry.ID = r.db.lastWeightID
+	entry.CreatedAt = time.Now()
+	r.db.weightEntries[entry.ID] = *entry
+
+	r.db.syncUserWeight(entry.UserID)
+	return nil
+}
+
+func (r *memoryWeightRepository) GetWeightEntries(ctx context.Context, userID int) ([]models.WeightEntry, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	return r.db.userWeightEntries(userID), nil
+}
+
+func (r *memoryWeightRepository) GetWeightEntriesForUsers(ctx context.Context, userIDs []int, since time.Time) ([]models.WeightEntry, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	entries := []models.WeightEntry{}
+	for _, userID := range userIDs {
+		for _, entry := range r.db.userWeightEntries(userID) {
+			if !entry.RecordedAt.Before(since) {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].UserID < entries[j].UserID
+	})
+	return entries, nil
+}
+
+func (r *memoryWeightRepository) DeleteWeightEntry(ctx context.Context, userID, entryID int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	entry, ok := r.db.weightEntries[entryID]
+	if !ok {
+		return ErrWeightEntryNotFound
+	}
+	if entry.UserID != userID {
+		return ErrWeightEntryForbidden
+	}
+
+	delete(r.db.weightEntries, entryID)
+	r.db.syncUserWeight(userID)
+	return nil
+}
+
+// userWeightEntries returns the weigh-ins of a user, oldest first. The caller
+// must hold the lock.
+func (db *memoryDB) userWeightEntries(userID int) []models.WeightEntry {
+	entries := []models.WeightEntry{}
+	for _, entry := range db.weightEntries {
+		if entry.UserID == userID {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].RecordedAt.Equal(entries[j].RecordedAt) {
+			return entries[i].RecordedAt.Before(entries[j].RecordedAt)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	return entries
+}
+
+// syncUserWeight copies the latest weigh-in to the user. The caller must hold
+// the write lock.
+func (db *memoryDB) syncUserWeight(userID int) {
+	entries := db.userWeightEntries(userID)
+	user, ok := db.users[userID]
+	if len(entries) == 0 || !ok {
+		return
+	}
+
+	user.Weight = entries[len(entries)-1].Weight
+	user.UpdatedAt = time.Now()
+	db.users[userID] = user
+}
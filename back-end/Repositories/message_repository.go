@@ -0,0 +1,232 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	models "HabitBite/backend/Models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrConversationNotFound = errors.New("conversation not found")
+
+// MessageRepository stores the conversations between clients and their
+// dietitians. It does not check that the two are subscribed; the caller
+// does, since history stays readable after a subscription ends.
+type MessageRepository interface {
+	// GetOrCreateConversation returns the conversation between the client and
+	// the dietitian, creating it on first use.
+	GetOrCreateConversation(ctx context.Context, userID, dietitianID int) (*models.Conversation, error)
+	GetConversation(ctx context.Context, conversationID int) (*models.Conversation, error)
+	// GetConversations lists the conversations participantID takes part in,
+	// most recently active first, with their unread counts for participantID.
+	GetConversations(ctx context.Context, participantID int) ([]models.ConversationSummary, error)
+	// CreateMessage saves a message and bumps the conversation's activity.
+	CreateMessage(ctx context.Context, message *models.Message) error
+	// GetMessages returns up to limit messages older than beforeID, newest
+	// first. A beforeID of 0 starts from the latest message.
+	GetMessages(ctx context.Context, conversationID, beforeID, limit int) ([]models.Message, error)
+	// MarkRead marks the messages readerID received in the conversation as
+	// read, up to and including upTo, or all of them when upTo is 0. The
+	// receipt holds the highest message ID marked and how many were unread.
+	MarkRead(ctx context.Context, conversationID, readerID, upTo int) (*models.ReadReceipt, error)
+}
+
+type messageRepository struct {
+	db      *sqlx.DB
+	dialect dialect
+}
+
+func NewMessageRepository(db *sqlx.DB) MessageRepository {
+	return &messageRepository{db: db, dialect: dialectFor(db)}
+}
+
+func (r *messageRepository) GetOrCreateConversation(ctx context.Context, userID, dietitianID int) (*models.Conversation, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	// Lock the client's row so two first messages don't both create the
+	// conversation.
+	var lockedID int
+	err = tx.GetContext(ctx, &lockedID, tx.Rebind(`SELECT id FROM users WHERE id = ?`+r.dialect.ForUpdate()), userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, wrapDatabaseError(err)
+	}
+
+	var conversation models.Conversation
+	query := `SELECT * FROM conversations WHERE user_id = ? AND dietitian_id = ?`
+	err = tx.GetContext(ctx, &conversation, tx.Rebind(query), userID, dietitianID)
+	if err == nil {
+		return &conversation, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, wrapDatabaseError(err)
+	}
+
+	conversation = models.Conversation{
+		UserID:      userID,
+		DietitianID: dietitianID,
+		CreatedAt:   time.Now(),
+	}
+	insertQuery := `INSERT INTO conversations (user_id, dietitian_id, created_at) VALUES (?, ?, ?)`
+	id, err := r.dialect.InsertReturningID(ctx, tx, insertQuery, userID, dietitianID, conversation.CreatedAt)
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	conversation.ID = int(id)
+
+	if err = tx.Commit(); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	return &conversation, nil
+}
+
+func (r *messageRepository) GetConversation(ctx context.Context, conversationID int) (*models.Conversation, error) {
+	var conversation models.Conversation
+	err := r.db.GetContext(ctx, &conversation, r.db.Rebind(`SELECT * FROM conversations WHERE id = ?`), conversationID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrConversationNotFound
+		}
+		return nil, wrapDatabaseError(err)
+	}
+	return &conversation, nil
+}
+
+func (r *messageRepository) GetConversations(ctx context.Context, participantID int) ([]models.ConversationSummary, error) {
+	query := `
+		SELECT c.*, u.full_name AS user_name, d.full_name AS dietitian_name,
+			(
+				SELECT m.body FROM messages m
+				WHERE m.conversation_id = c.id
+				ORDER BY m.id DESC
+				LIMIT 1
+			) AS last_message,
+			(
+				SELECT COUNT(*) FROM messages m
+				WHERE m.conversation_id = c.id AND m.sender_id <> ? AND m.read_at IS NULL
+			) AS unread_count
+		FROM conversations c
+		JOIN users u ON u.id = c.user_id
+		JOIN users d ON d.id = c.dietitian_id
+		WHERE c.user_id = ? OR c.dietitian_id = ?
+		ORDER BY COALESCE(c.last_message_at, c.created_at) DESC, c.id DESC
+	`
+	conversations := []models.ConversationSummary{}
+	err := r.db.SelectContext(ctx, &conversations, r.db.Rebind(query), participantID, participantID, participantID)
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	return conversations, nil
+}
+
+func (r *messageRepository) CreateMessage(ctx context.Context, message *models.Message) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	message.CreatedAt = time.Now()
+	message.ReadAt = nil
+
+	query := `INSERT INTO messages (conversation_id, sender_id, body, created_at) VALUES (?, ?, ?, ?)`
+	id, err := r.dialect.InsertReturningID(ctx, tx, query,
+		message.ConversationID, message.SenderID, message.Body, message.CreatedAt)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	message.ID = int(id)
+
+	// Not checking the affected rows: MySQL reports none when two messages
+	// share the same second, and the foreign key already rejected unknown
+	// conversations.
+	_, err = tx.ExecContext(ctx, tx.Rebind(`UPDATE conversations SET last_message_at = ? WHERE id = ?`),
+		message.CreatedAt, message.ConversationID)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	return nil
+}
+
+func (r *messageRepository) GetMessages(ctx context.Context, conversationID, beforeID, limit int) ([]models.Message, error) {
+	query := `SELECT * FROM messages WHERE conversation_id = ?`
+	args := []interface{}{conversationID}
+	if beforeID > 0 {
+		query += ` AND id < ?`
+		args = append(args, beforeID)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	messages := []models.Message{}
+	if err := r.db.SelectContext(ctx, &messages, r.db.Rebind(query), args...); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	return messages, nil
+}
+
+func (r *messageRepository) MarkRead(ctx context.Context, conversationID, readerID, upTo int) (*models.ReadReceipt, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	receipt := &models.ReadReceipt{
+		ConversationID: conversationID,
+		ReaderID:       readerID,
+		ReadAt:         time.Now(),
+	}
+
+	query := `
+		SELECT COALESCE(MAX(id), 0) FROM messages
+		WHERE conversation_id = ? AND sender_id <> ? AND read_at IS NULL
+	`
+	args := []interface{}{conversationID, readerID}
+	if upTo > 0 {
+		query += ` AND id <= ?`
+		args = append(args, upTo)
+	}
+	if err := tx.GetContext(ctx, &receipt.UpTo, tx.Rebind(query), args...); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	if receipt.UpTo == 0 {
+		return receipt, nil
+	}
+
+	updateQuery := `
+		UPDATE messages SET read_at = ?
+		WHERE conversation_id = ? AND sender_id <> ? AND read_at IS NULL AND id <= ?
+	`
+	result, err := tx.ExecContext(ctx, tx.Rebind(updateQuery), receipt.ReadAt, conversationID, readerID, receipt.UpTo)
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	receipt.Count = int(rows)
+
+	if err = tx.Commit(); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	return receipt, nil
+}
@@ -0,0 +1,276 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	models "HabitBite/backend/Models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	// ErrRefreshTokenInvalid is returned for unknown, expired and revoked
+	// refresh tokens.
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid")
+	// ErrRefreshTokenReused is returned when a token that was already rotated
+	// is used again. Its family is revoked.
+	ErrRefreshTokenReused = errors.New("refresh token was already used")
+	ErrSessionNotFound    = errors.New("session not found")
+)
+
+// RefreshTokenRepository stores refresh tokens and the sessions they belong
+// to. Every login starts a session with a new token family; revoking the
+// session revokes the family.
+type RefreshTokenRepository interface {
+	// CreateSession stores a new session and its first refresh token, which
+	// must carry the session's family ID.
+	CreateSession(ctx context.Context, session *models.Session, token *models.RefreshToken) error
+	// RotateRefreshToken marks the token with the given hash as used and
+	// stores next in its place, in the same family and for the same user. It
+	// records the client in the session and returns the session.
+	RotateRefreshToken(ctx context.Context, tokenHash string, next *models.RefreshToken, userAgent, ipAddress string) (*models.Session, error)
+	// RevokeRefreshTokenFamily revokes the session the token with the given
+	// hash belongs to.
+	RevokeRefreshTokenFamily(ctx context.Context, tokenHash string, now time.Time) error
+	// GetSessions returns the active sessions of a user, most recently used
+	// first.
+	GetSessions(ctx context.Context, userID int, now time.Time) ([]models.Session, error)
+	// RevokeSession revokes an active session of the user.
+	RevokeSession(ctx context.Context, userID, sessionID int, now time.Time) error
+	// RevokeSessions revokes every session of the user and returns how many
+	// were active.
+	RevokeSessions(ctx context.Context, userID int, now time.Time) (int, error)
+	// IsSessionActive reports whether the session of a token family can still
+	// be used.
+	IsSessionActive(ctx context.Context, familyID string, now time.Time) (bool, error)
+}
+
+type refreshTokenRepository struct {
+	db      *sqlx.DB
+	dialect dialect
+}
+
+func NewRefreshTokenRepository(db *sqlx.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db, dialect: dialectFor(db)}
+}
+
+// sessionActiveSince is the oldest last use of a session that is not expired,
+// since its latest refresh token lives RefreshTokenLifetime.
+func sessionActiveSince(now time.Time) time.Time {
+	return now.Add(-models.RefreshTokenLifetime)
+}
+
+func (r *refreshTokenRepository) CreateSession(ctx context.Context, session *models.Session, token *models.RefreshToken) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO sessions (user_id, family_id, user_agent, ip_address, created_at, last_used_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	id, err := r.dialect.InsertReturningID(ctx, tx, query,
+		session.UserID, session.FamilyID, session.UserAgent, session.IPAddress, session.CreatedAt, session.LastUsedAt)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	session.ID = int(id)
+
+	if token.ID, err = r.insert(ctx, tx, token); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return wrapDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *refreshTokenRepository) insert(ctx context.Context, exec sqlx.ExtContext, token *models.RefreshToken) (int, error) {
+	query := `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	id, err := r.dialect.InsertReturningID(ctx, exec, query,
+		token.UserID, token.FamilyID, token.TokenHash, token.CreatedAt, token.ExpiresAt)
+	if err != nil {
+		return 0, wrapDatabaseError(err)
+	}
+	return int(id), nil
+}
+
+func (r *refreshTokenRepository) RotateRefreshToken(ctx context.Context, tokenHash string, next *models.RefreshToken, userAgent, ipAddress string) (*models.Session, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	var current models.RefreshToken
+	query := `SELECT * FROM refresh_tokens WHERE token_hash = ?` + r.dialect.ForUpdate()
+	if err := tx.GetContext(ctx, &current, tx.Rebind(query), tokenHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRefreshTokenInvalid
+		}
+		return nil, wrapDatabaseError(err)
+	}
+
+	now := next.CreatedAt
+	switch {
+	case current.RevokedAt != nil || !current.ExpiresAt.After(now):
+		return nil, ErrRefreshTokenInvalid
+	case current.RotatedAt != nil:
+		if err := revokeFamily(ctx, tx, current.FamilyID, now); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, wrapDatabaseError(err)
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(`UPDATE refresh_tokens SET rotated_at = ? WHERE id = ?`), now, current.ID); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	next.UserID = current.UserID
+	next.FamilyID = current.FamilyID
+	if next.ID, err = r.insert(ctx, tx, next); err != nil {
+		return nil, err
+	}
+
+	sessionQuery := `UPDATE sessions SET last_used_at = ?, user_agent = ?, ip_address = ? WHERE family_id = ?`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(sessionQuery), now, userAgent, ipAddress, current.FamilyID); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	var session models.Session
+	if err := tx.GetContext(ctx, &session, tx.Rebind(`SELECT * FROM sessions WHERE family_id = ?`), current.FamilyID); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	return &session, nil
+}
+
+func (r *refreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, tokenHash string, now time.Time) error {
+	var familyID string
+	err := r.db.GetContext(ctx, &familyID, r.db.Rebind(`SELECT family_id FROM refresh_tokens WHERE token_hash = ?`), tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrRefreshTokenInvalid
+		}
+		return wrapDatabaseError(err)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	if err := revokeFamily(ctx, tx, familyID, now); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return wrapDatabaseError(err)
+	}
+	return nil
+}
+
+// revokeFamily revokes a session and its refresh tokens.
+func revokeFamily(ctx context.Context, tx *sqlx.Tx, familyID string, now time.Time) error {
+	for _, query := range []string{
+		`UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL`,
+		`UPDATE sessions SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL`,
+	} {
+		if _, err := tx.ExecContext(ctx, tx.Rebind(query), now, familyID); err != nil {
+			return wrapDatabaseError(err)
+		}
+	}
+	return nil
+}
+
+func (r *refreshTokenRepository) GetSessions(ctx context.Context, userID int, now time.Time) ([]models.Session, error) {
+	query := `
+		SELECT * FROM sessions
+		WHERE user_id = ? AND revoked_at IS NULL AND last_used_at > ?
+		ORDER BY last_used_at DESC, id DESC
+	`
+	sessions := []models.Session{}
+	if err := r.db.SelectContext(ctx, &sessions, r.db.Rebind(query), userID, sessionActiveSince(now)); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	return sessions, nil
+}
+
+func (r *refreshTokenRepository) RevokeSession(ctx context.Context, userID, sessionID int, now time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	var familyID string
+	query := `
+		SELECT family_id FROM sessions
+		WHERE id = ? AND user_id = ? AND revoked_at IS NULL AND last_used_at > ?
+	`
+	if err := tx.GetContext(ctx, &familyID, tx.Rebind(query), sessionID, userID, sessionActiveSince(now)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSessionNotFound
+		}
+		return wrapDatabaseError(err)
+	}
+
+	if err := revokeFamily(ctx, tx, familyID, now); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return wrapDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *refreshTokenRepository) RevokeSessions(ctx context.Context, userID int, now time.Time) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	var active int
+	countQuery := `SELECT COUNT(*) FROM sessions WHERE user_id = ? AND revoked_at IS NULL AND last_used_at > ?`
+	if err := tx.GetContext(ctx, &active, tx.Rebind(countQuery), userID, sessionActiveSince(now)); err != nil {
+		return 0, wrapDatabaseError(err)
+	}
+
+	for _, query := range []string{
+		`UPDATE refresh_tokens SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL`,
+		`UPDATE sessions SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL`,
+	} {
+		if _, err := tx.ExecContext(ctx, tx.Rebind(query), now, userID); err != nil {
+			return 0, wrapDatabaseError(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, wrapDatabaseError(err)
+	}
+	return active, nil
+}
+
+func (r *refreshTokenRepository) IsSessionActive(ctx context.Context, familyID string, now time.Time) (bool, error) {
+	var active int
+	query := `SELECT COUNT(*) FROM sessions WHERE family_id = ? AND revoked_at IS NULL AND last_used_at > ?`
+	if err := r.db.GetContext(ctx, &active, r.db.Rebind(query), familyID, sessionActiveSince(now)); err != nil {
+		return false, wrapDatabaseError(err)
+	}
+	return active > 0, nil
+}
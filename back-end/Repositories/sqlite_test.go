@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	config "HabitBite/backend/Config"
+	goals "HabitBite/backend/Goals"
+	migrations "HabitBite/backend/Migrations"
+	models "HabitBite/backend/Models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// openSQLite opens a migrated database in a temporary file, with the
+// connection settings the server uses.
+func openSQLite(t *testing.T) (*sqlx.DB, *migrations.Migrator) {
+	t.Helper()
+
+	db, err := config.NewSQLiteDB(&config.Config{DBPath: filepath.Join(t.TempDir(), "habitbite.db")})
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		t.Fatalf("loading migrations: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("applying migrations: %v", err)
+	}
+
+	return db, migrator
+}
+
+func TestSQLiteMigrationsUpAndDown(t *testing.T) {
+	db, migrator := openSQLite(t)
+	testMigrationsUpAndDown(t, db, migrator, `
+		SELECT COUNT(*) FROM sqlite_master
+		WHERE type = 'table' AND name NOT IN ('schema_migrations', 'sqlite_sequence')
+	`)
+}
+
+func TestSQLiteFoodEntryRepository(t *testing.T) {
+	db, _ := openSQLite(t)
+	testFoodEntryRepository(t, db)
+}
+
+func TestSQLiteConcurrentFoodEntries(t *testing.T) {
+	db, _ := openSQLite(t)
+	users := NewUserRepository(db, goals.DefaultPlanner())
+	entries := NewFoodEntryRepository(db)
+	ctx := context.Background()
+
+	user := createTestUser(t, users, "eater")
+	day := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	const writers = 20
+	logged := make([]*models.FoodEntry, writers)
+	for i := range logged {
+		logged[i] = &models.FoodEntry{
+			UserID: user.ID, FoodID: "171688", Name: "Apples, raw, with skin", Amount: 100,
+			Calories: 10, Protein: 1, Carbs: 2, Fat: 0.5, Meal: models.MealSnack, Date: day,
+		}
+		if err := entries.CreateFoodEntry(ctx, logged[i]); err != nil {
+			t.Fatalf("CreateFoodEntry: %v", err)
+		}
+	}
+
+	// Updates of the same day rebuild the same rollup row. With the server's
+	// settings concurrent writers wait for the lock instead of failing.
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for _, entry := range logged {
+		wg.Add(1)
+		go func(entry *models.FoodEntry) {
+			defer wg.Done()
+			entry.Rescale(200)
+			errs <- entries.UpdateFoodEntry(ctx, entry)
+		}(entry)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("UpdateFoodEntry: %v", err)
+		}
+	}
+
+	totals, err := entries.GetDailyTotalsForUsers(ctx, []int{user.ID}, day, day)
+	if err != nil {
+		t.Fatalf("GetDailyTotalsForUsers: %v", err)
+	}
+	if len(totals) != 1 || totals[0].Calories != writers*20 {
+		t.Errorf("daily_entries = %+v, want one day with %d calories", totals, writers*20)
+	}
+}
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	goals "HabitBite/backend/Goals"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Store bundles the repositories the HTTP layer depends on, so the routes can
+// be wired against either the SQL database or the in-memory implementations.
+type Store struct {
+	Users         UserRepository
+	FoodEntries   FoodEntryRepository
+	Foods         FoodRepository
+	Audit         AuditRepository
+	Weights       WeightRepository
+	Jobs          JobRepository
+	Dietitians    DietitianRepository
+	MealPlans     MealPlanRepository
+	Messages      MessageRepository
+	Comments      CommentRepository
+	Alerts        AlertRepository
+	RefreshTokens RefreshTokenRepository
+	UserTokens    UserTokenRepository
+	TwoFactor     TwoFactorRepository
+
+	// Goals calculates the calorie and macro targets the user repository
+	// writes, and is shared with the handlers that suggest goals.
+	Goals *goals.Planner
+}
+
+// NewStore returns the SQL repositories backed by db.
+func NewStore(db *sqlx.DB, planner *goals.Planner) *Store {
+	return &Store{
+		Users:         NewUserRepository(db, planner),
+		FoodEntries:   NewFoodEntryRepository(db),
+		Foods:         NewFoodRepository(db),
+		Audit:         NewAuditRepository(db),
+		Weights:       NewWeightRepository(db),
+		Jobs:          NewJobRepository(db),
+		Dietitians:    NewDietitianRepository(db),
+		MealPlans:     NewMealPlanRepository(db),
+		Messages:      NewMessageRepository(db),
+		Comments:      NewCommentRepository(db),
+		Alerts:        NewAlertRepository(db),
+		RefreshTokens: NewRefreshTokenRepository(db),
+		UserTokens:    NewUserTokenRepository(db),
+		TwoFactor:     NewTwoFactorRepository(db),
+		Goals:         planner,
+	}
+}
@@ -0,0 +1,344 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	models "HabitBite/backend/Models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrTwoFactorNotFound       = errors.New("two-factor authentication is not set up")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrTwoFactorStepUsed is returned when a code of the same or an earlier
+	// time step was already accepted.
+	ErrTwoFactorStepUsed   = errors.New("code was already used")
+	ErrRecoveryCodeInvalid = errors.New("recovery code is invalid or used")
+	// ErrMFAChallengeInvalid is returned for unknown, expired, used and
+	// exhausted login challenges.
+	ErrMFAChallengeInvalid = errors.New("login challenge is invalid or expired")
+)
+
+// TwoFactorRepository stores the TOTP enrollments and recovery codes of
+// users.
+type TwoFactorRepository interface {
+	// GetTwoFactor returns the enrollment of a user, pending or enabled.
+	GetTwoFactor(ctx context.Context, userID int) (*models.TwoFactor, error)
+	IsTwoFactorEnabled(ctx context.Context, userID int) (bool, error)
+	// StartTwoFactor stores a pending enrollment, replacing an earlier
+	// pending one. It fails with ErrTwoFactorAlreadyEnabled if two-factor
+	// login is enabled.
+	StartTwoFactor(ctx context.Context, twoFactor *models.TwoFactor) error
+	// EnableTwoFactor enables the pending enrollment of a user, recording the
+	// step of the code that confirmed it, and stores the hashes of their
+	// recovery codes.
+	EnableTwoFactor(ctx context.Context, userID int, step int64, codeHashes []string, now time.Time) error
+	// UseTwoFactorStep records that a code of step was accepted. It fails with
+	// ErrTwoFactorStepUsed unless step is later than the last one used.
+	UseTwoFactorStep(ctx context.Context, userID int, step int64) error
+	// DisableTwoFactor removes the enrollment and recovery codes of a user.
+	DisableTwoFactor(ctx context.Context, userID int) error
+	// RecordTwoFactorFailure counts a wrong code of the user. The count
+	// reaching maxFailures locks the user until lockedUntil and starts over.
+	RecordTwoFactorFailure(ctx context.Context, userID, maxFailures int, lockedUntil time.Time) error
+	// ResetTwoFactorFailures clears the count after an accepted code.
+	ResetTwoFactorFailures(ctx context.Context, userID int) error
+
+	// CreateMFAChallenge stores the challenge of a login waiting for its
+	// second factor. The user's expired challenges are deleted.
+	CreateMFAChallenge(ctx context.Context, challenge *models.MFAChallenge) error
+	// AttemptMFAChallenge counts an attempt at the user's challenge. It fails
+	// with ErrMFAChallengeInvalid if the challenge doesn't exist, expired,
+	// was used or already had maxAttempts attempts.
+	AttemptMFAChallenge(ctx context.Context, id string, userID, maxAttempts int, now time.Time) error
+	// UseMFAChallenge marks the challenge as used. It fails with
+	// ErrMFAChallengeInvalid if it was used before.
+	UseMFAChallenge(ctx context.Context, id string, now time.Time) error
+
+	// ReplaceRecoveryCodes replaces every recovery code of a user.
+	ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string, now time.Time) error
+	// UseRecoveryCode marks an unused recovery code of the user as used.
+	UseRecoveryCode(ctx context.Context, userID int, codeHash string, now time.Time) error
+	CountRecoveryCodes(ctx context.Context, userID int) (int, error)
+}
+
+type twoFactorRepository struct {
+	db      *sqlx.DB
+	dialect dialect
+}
+
+func NewTwoFactorRepository(db *sqlx.DB) TwoFactorRepository {
+	return &twoFactorRepository{db: db, dialect: dialectFor(db)}
+}
+
+func (r *twoFactorRepository) GetTwoFactor(ctx context.Context, userID int) (*models.TwoFactor, error) {
+	var twoFactor models.TwoFactor
+	if err := r.db.GetContext(ctx, &twoFactor, r.db.Rebind(`SELECT * FROM user_two_factor WHERE user_id = ?`), userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTwoFactorNotFound
+		}
+		return nil, wrapDatabaseError(err)
+	}
+	return &twoFactor, nil
+}
+
+func (r *twoFactorRepository) IsTwoFactorEnabled(ctx context.Context, userID int) (bool, error) {
+	var enabled int
+	query := `SELECT COUNT(*) FROM user_two_factor WHERE user_id = ? AND enabled_at IS NOT NULL`
+	if err := r.db.GetContext(ctx, &enabled, r.db.Rebind(query), userID); err != nil {
+		return false, wrapDatabaseError(err)
+	}
+	return enabled > 0, nil
+}
+
+func (r *twoFactorRepository) StartTwoFactor(ctx context.Context, twoFactor *models.TwoFactor) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	var enabled int
+	query := `SELECT COUNT(*) FROM user_two_factor WHERE user_id = ? AND enabled_at IS NOT NULL`
+	if err := tx.GetContext(ctx, &enabled, tx.Rebind(query), twoFactor.UserID); err != nil {
+		return wrapDatabaseError(err)
+	}
+	if enabled > 0 {
+		return ErrTwoFactorAlreadyEnabled
+	}
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(`DELETE FROM user_two_factor WHERE user_id = ?`), twoFactor.UserID); err != nil {
+		return wrapDatabaseError(err)
+	}
+	insertQuery := `
+		INSERT INTO user_two_factor (user_id, secret_encrypted, last_used_step, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(insertQuery),
+		twoFactor.UserID, twoFactor.SecretEncrypted, twoFactor.LastUsedStep, twoFactor.CreatedAt); err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return wrapDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) EnableTwoFactor(ctx context.Context, userID int, step int64, codeHashes []string, now time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE user_two_factor SET enabled_at = ?, last_used_step = ? WHERE user_id = ? AND enabled_at IS NULL`
+	result, err := tx.ExecContext(ctx, tx.Rebind(query), now, step, userID)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	if rowsAffected == 0 {
+		return ErrTwoFactorNotFound
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes, now); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return wrapDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) UseTwoFactorStep(ctx context.Context, userID int, step int64) error {
+	query := `UPDATE user_two_factor SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?`
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), step, userID, step)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	if rowsAffected == 0 {
+		return ErrTwoFactorStepUsed
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) DisableTwoFactor(ctx context.Context, userID int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		`DELETE FROM recovery_codes WHERE user_id = ?`,
+		`DELETE FROM user_two_factor WHERE user_id = ?`,
+	} {
+		if _, err := tx.ExecContext(ctx, tx.Rebind(query), userID); err != nil {
+			return wrapDatabaseError(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return wrapDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) RecordTwoFactorFailure(ctx context.Context, userID, maxFailures int, lockedUntil time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE user_two_factor SET failed_attempts = failed_attempts + 1 WHERE user_id = ?`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), userID); err != nil {
+		return wrapDatabaseError(err)
+	}
+	lockQuery := `UPDATE user_two_factor SET failed_attempts = 0, locked_until = ? WHERE user_id = ? AND failed_attempts >= ?`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(lockQuery), lockedUntil, userID, maxFailures); err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return wrapDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) ResetTwoFactorFailures(ctx context.Context, userID int) error {
+	query := `UPDATE user_two_factor SET failed_attempts = 0 WHERE user_id = ?`
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), userID); err != nil {
+		return wrapDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) CreateMFAChallenge(ctx context.Context, challenge *models.MFAChallenge) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	deleteQuery := `DELETE FROM mfa_challenges WHERE user_id = ? AND expires_at <= ?`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(deleteQuery), challenge.UserID, challenge.CreatedAt); err != nil {
+		return wrapDatabaseError(err)
+	}
+	query := `
+		INSERT INTO mfa_challenges (id, user_id, attempts, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query),
+		challenge.ID, challenge.UserID, challenge.Attempts, challenge.CreatedAt, challenge.ExpiresAt); err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return wrapDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) AttemptMFAChallenge(ctx context.Context, id string, userID, maxAttempts int, now time.Time) error {
+	query := `
+		UPDATE mfa_challenges SET attempts = attempts + 1
+		WHERE id = ? AND user_id = ? AND used_at IS NULL AND expires_at > ? AND attempts < ?
+	`
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), id, userID, now, maxAttempts)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	if rowsAffected == 0 {
+		return ErrMFAChallengeInvalid
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) UseMFAChallenge(ctx context.Context, id string, now time.Time) error {
+	query := `UPDATE mfa_challenges SET used_at = ? WHERE id = ? AND used_at IS NULL`
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), now, id)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	if rowsAffected == 0 {
+		return ErrMFAChallengeInvalid
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string, now time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes, now); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return wrapDatabaseError(err)
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sqlx.Tx, userID int, codeHashes []string, now time.Time) error {
+	if _, err := tx.ExecContext(ctx, tx.Rebind(`DELETE FROM recovery_codes WHERE user_id = ?`), userID); err != nil {
+		return wrapDatabaseError(err)
+	}
+	query := tx.Rebind(`INSERT INTO recovery_codes (user_id, code_hash, created_at) VALUES (?, ?, ?)`)
+	for _, codeHash := range codeHashes {
+		if _, err := tx.ExecContext(ctx, query, userID, codeHash, now); err != nil {
+			return wrapDatabaseError(err)
+		}
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID int, codeHash string, now time.Time) error {
+	query := `UPDATE recovery_codes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL`
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), now, userID, codeHash)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	if rowsAffected == 0 {
+		return ErrRecoveryCodeInvalid
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) CountRecoveryCodes(ctx context.Context, userID int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM recovery_codes WHERE user_id = ? AND used_at IS NULL`
+	if err := r.db.GetContext(ctx, &count, r.db.Rebind(query), userID); err != nil {
+		return 0, wrapDatabaseError(err)
+	}
+	return count, nil
+}
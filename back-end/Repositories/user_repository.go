@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"time"
 
+	goals "HabitBite/backend/Goals"
 	models "HabitBite/backend/Models"
 
 	"github.com/jmoiron/sqlx"
@@ -17,6 +18,11 @@ var (
 	ErrUserNotFound      = errors.New("user not found")
 	ErrUserAlreadyExists = errors.New("user already exists")
 	ErrDatabaseOperation = errors.New("database operation failed")
+
+	ErrDietitianNotFound      = errors.New("dietitian not found")
+	ErrSubscriptionNotFound   = errors.New("subscription not found")
+	ErrSubscriptionNotPending = errors.New("subscription request has already been answered")
+	ErrDietitianAtCapacity    = errors.New("dietitian has no capacity for new clients")
 )
 
 type UserRepository interface {
@@ -24,28 +30,64 @@ type UserRepository interface {
 	FindByEmail(ctx context.Context, email string) (*models.User, error)
 	FindByUsername(ctx context.Context, username string) (*models.User, error)
 	FindByID(ctx context.Context, id int) (*models.User, error)
+	// UpdateUser saves the user's profile. The email verification is only
+	// changed by MarkEmailVerified.
 	UpdateUser(ctx context.Context, user *models.User) error
 	DeleteUser(ctx context.Context, id int) error
+	// MarkEmailVerified records that the user verified their email, unless it
+	// already was.
+	MarkEmailVerified(ctx context.Context, userID int, at time.Time) error
+	IsEmailVerified(ctx context.Context, userID int) (bool, error)
 	GetAllUsers(ctx context.Context) ([]models.User, error)
+	// GetUsersAfter returns up to limit users with an ID above afterID in ID
+	// order, only those with the given role unless it is empty.
+	GetUsersAfter(ctx context.Context, afterID int, role string, limit int) ([]models.User, error)
+	CountUsers(ctx context.Context, role string) (int, error)
 
 	GetUserGoals(ctx context.Context, userID int) (*models.UserGoals, error)
 	UpdateUserGoals(ctx context.Context, goals *models.UserGoals) error
 	SyncUserCalorieGoal(ctx context.Context, userID int, calorieGoal int) error
+	// GetGoalsForUsers returns the stored goals of the given users, keyed by
+	// user ID. Users without goals are left out rather than generated.
+	GetGoalsForUsers(ctx context.Context, userIDs []int) (map[int]models.UserGoals, error)
 
+	// GetSubscribedUsers returns the clients with an accepted subscription.
 	GetSubscribedUsers(ctx context.Context, dietitianID int) ([]models.User, error)
+	// IsUserSubscribedToDietitian reports whether the user has an accepted
+	// subscription to someone who is still a dietitian.
 	IsUserSubscribedToDietitian(ctx context.Context, userID string, dietitianID int) (bool, error)
 	GetUserProgress(ctx context.Context, userID string) (map[string]interface{}, error)
-	SubscribeUserToDietitian(ctx context.Context, userID int, dietitianID int) error
+	// SubscribeUserToDietitian requests a subscription, reopening a declined
+	// or ended one. An existing request or subscription is returned as is.
+	SubscribeUserToDietitian(ctx context.Context, userID int, dietitianID int) (*models.DietitianSubscription, error)
+	// RespondToSubscription accepts or declines a pending request. Accepting
+	// fails with ErrDietitianAtCapacity when the dietitian's profile limits
+	// their clients and the limit is reached.
+	RespondToSubscription(ctx context.Context, userID int, dietitianID int, accept bool) (*models.DietitianSubscription, error)
+	// UnsubscribeUserFromDietitian ends a pending or accepted subscription.
 	UnsubscribeUserFromDietitian(ctx context.Context, userID int, dietitianID int) error
+	// GetSubscriptionRequests returns the pending requests of a dietitian,
+	// oldest first, with the client's name.
+	GetSubscriptionRequests(ctx context.Context, dietitianID int) ([]models.DietitianSubscription, error)
+	// GetUserSubscriptions returns every subscription of a user in any state,
+	// newest first, with the dietitian's name.
+	GetUserSubscriptions(ctx context.Context, userID int) ([]models.DietitianSubscription, error)
 	GetAvailableDietitians(ctx context.Context) ([]models.User, error)
+	// GetDietitiansForUsers returns the dietitians each of the users has an
+	// accepted subscription with. Users without any are missing.
+	GetDietitiansForUsers(ctx context.Context, userIDs []int) (map[int][]int, error)
 }
 
 type userRepository struct {
-	db *sqlx.DB
+	db      *sqlx.DB
+	dialect dialect
+	planner *goals.Planner
 }
 
-func NewUserRepository(db *sqlx.DB) UserRepository {
-	return &userRepository{db: db}
+// NewUserRepository returns the SQL user repository. Macro targets that are
+// derived rather than set explicitly are calculated by planner.
+func NewUserRepository(db *sqlx.DB, planner *goals.Planner) UserRepository {
+	return &userRepository{db: db, dialect: dialectFor(db), planner: planner}
 }
 
 func (r *userRepository) CreateUser(ctx context.Context, user *models.User) error {
@@ -77,64 +119,21 @@ func (r *userRepository) CreateUser(ctx context.Context, user *models.User) erro
 
 	query := `INSERT INTO users (
         email, username, password_hash, full_name, birthdate, gender, 
-        height, weight, goal_type, activity_level, daily_calorie_goal, role, created_at, updated_at
-    ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+        height, weight, body_fat, goal_type, activity_level, daily_calorie_goal, role, email_verified_at, created_at, updated_at
+    ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	result, err := tx.ExecContext(ctx, query,
+	id, err := r.dialect.InsertReturningID(ctx, tx, query,
 		user.Email, user.Username, user.PasswordHash, user.FullName,
-		user.Birthdate, user.Gender, user.Height, user.Weight,
-		user.GoalType, user.ActivityLevel, user.DailyCalorieGoal, user.Role, user.CreatedAt, user.UpdatedAt)
-
-	if err != nil {
-		return wrapDatabaseError(err)
-	}
-
-	id, err := result.LastInsertId()
+		user.Birthdate, user.Gender, user.Height, user.Weight, user.BodyFat,
+		user.GoalType, user.ActivityLevel, user.DailyCalorieGoal, user.Role, user.EmailVerifiedAt, user.CreatedAt, user.UpdatedAt)
 	if err != nil {
 		return wrapDatabaseError(err)
 	}
 
 	user.ID = int(id)
 
-	var targetProtein, targetCarbs, targetFats float64
-	caloriesFromProtein := 4.0 // 4 calories per gram of protein
-	caloriesFromCarbs := 4.0   // 4 calories per gram of carbs
-	caloriesFromFats := 9.0    // 9 calories per gram of fat
-
-	switch user.GoalType {
-	case "lose":
-		// For weight loss: protein 35%, fats 35%, carbs 30%
-		targetProtein = float64(user.DailyCalorieGoal) * 0.35 / caloriesFromProtein
-		targetFats = float64(user.DailyCalorieGoal) * 0.35 / caloriesFromFats
-		targetCarbs = float64(user.DailyCalorieGoal) * 0.30 / caloriesFromCarbs
-	case "maintain":
-		// For weight maintenance: protein 25%, fats 25%, carbs 50%
-		targetProtein = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromProtein
-		targetFats = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromFats
-		targetCarbs = float64(user.DailyCalorieGoal) * 0.50 / caloriesFromCarbs
-	case "gain":
-		// For weight gain: protein 30%, fats 25%, carbs 45%
-		targetProtein = float64(user.DailyCalorieGoal) * 0.30 / caloriesFromProtein
-		targetFats = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromFats
-		targetCarbs = float64(user.DailyCalorieGoal) * 0.45 / caloriesFromCarbs
-	default:
-		// Default to maintenance if goal type is invalid
-		targetProtein = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromProtein
-		targetFats = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromFats
-		targetCarbs = float64(user.DailyCalorieGoal) * 0.50 / caloriesFromCarbs
-	}
-
-	targetWeight := user.Weight
-
-	goalsQuery := `INSERT INTO user_goals (
-		user_id, target_calories, target_protein, target_carbs, target_fats, target_weight
-	) VALUES (?, ?, ?, ?, ?, ?)`
-
-	_, err = tx.ExecContext(ctx, goalsQuery,
-		user.ID, user.DailyCalorieGoal, targetProtein, targetCarbs, targetFats, targetWeight)
-
-	if err != nil {
-		return wrapDatabaseError(err)
+	if err := r.saveCalorieTargets(ctx, tx, r.planner.Goals(user, user.DailyCalorieGoal)); err != nil {
+		return err
 	}
 
 	if err = tx.Commit(); err != nil {
@@ -147,7 +146,7 @@ func (r *userRepository) CreateUser(ctx context.Context, user *models.User) erro
 func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `SELECT * FROM users WHERE email = ? LIMIT 1`
 	var user models.User
-	err := r.db.GetContext(ctx, &user, query, email)
+	err := r.db.GetContext(ctx, &user, r.db.Rebind(query), email)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -162,7 +161,7 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models
 func (r *userRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
 	query := `SELECT * FROM users WHERE username = ? LIMIT 1`
 	var user models.User
-	err := r.db.GetContext(ctx, &user, query, username)
+	err := r.db.GetContext(ctx, &user, r.db.Rebind(query), username)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -177,7 +176,7 @@ func (r *userRepository) FindByUsername(ctx context.Context, username string) (*
 func (r *userRepository) FindByID(ctx context.Context, id int) (*models.User, error) {
 	query := `SELECT * FROM users WHERE id = ? LIMIT 1`
 	var user models.User
-	err := r.db.GetContext(ctx, &user, query, id)
+	err := r.db.GetContext(ctx, &user, r.db.Rebind(query), id)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -200,13 +199,13 @@ func (r *userRepository) UpdateUser(ctx context.Context, user *models.User) erro
 
 	query := `UPDATE users SET 
 		email = ?, username = ?, password_hash = ?, full_name = ?, 
-		birthdate = ?, gender = ?, height = ?, weight = ?, 
+		birthdate = ?, gender = ?, height = ?, weight = ?, body_fat = ?,
 		goal_type = ?, activity_level = ?, daily_calorie_goal = ?, role = ?, updated_at = ?
 		WHERE id = ?`
 
-	result, err := tx.ExecContext(ctx, query,
+	result, err := tx.ExecContext(ctx, tx.Rebind(query),
 		user.Email, user.Username, user.PasswordHash, user.FullName,
-		user.Birthdate, user.Gender, user.Height, user.Weight,
+		user.Birthdate, user.Gender, user.Height, user.Weight, user.BodyFat,
 		user.GoalType, user.ActivityLevel, user.DailyCalorieGoal, user.Role, user.UpdatedAt,
 		user.ID)
 
@@ -223,93 +222,10 @@ func (r *userRepository) UpdateUser(ctx context.Context, user *models.User) erro
 		return ErrUserNotFound
 	}
 
-	goalsUpdateQuery := `
-		UPDATE user_goals 
-		SET target_calories = ? 
-		WHERE user_id = ?
-	`
-
-	_, err = tx.ExecContext(ctx, goalsUpdateQuery, user.DailyCalorieGoal, user.ID)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			var targetProtein, targetCarbs, targetFats float64
-			caloriesFromProtein := 4.0 // 4 calories per gram of protein
-			caloriesFromCarbs := 4.0   // 4 calories per gram of carbs
-			caloriesFromFats := 9.0    // 9 calories per gram of fat
-
-			switch user.GoalType {
-			case "lose":
-				// For weight loss: protein 35%, fats 35%, carbs 30%
-				targetProtein = float64(user.DailyCalorieGoal) * 0.35 / caloriesFromProtein
-				targetFats = float64(user.DailyCalorieGoal) * 0.35 / caloriesFromFats
-				targetCarbs = float64(user.DailyCalorieGoal) * 0.30 / caloriesFromCarbs
-			case "maintain":
-				// For weight maintenance: protein 25%, fats 25%, carbs 50%
-				targetProtein = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromProtein
-				targetFats = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromFats
-				targetCarbs = float64(user.DailyCalorieGoal) * 0.50 / caloriesFromCarbs
-			case "gain":
-				// For weight gain: protein 30%, fats 25%, carbs 45%
-				targetProtein = float64(user.DailyCalorieGoal) * 0.30 / caloriesFromProtein
-				targetFats = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromFats
-				targetCarbs = float64(user.DailyCalorieGoal) * 0.45 / caloriesFromCarbs
-			default:
-				// Default to maintenance if goal type is invalid
-				targetProtein = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromProtein
-				targetFats = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromFats
-				targetCarbs = float64(user.DailyCalorieGoal) * 0.50 / caloriesFromCarbs
-			}
-
-			insertQuery := `
-				INSERT INTO user_goals (user_id, target_calories, target_protein, target_carbs, target_fats, target_weight)
-				VALUES (?, ?, ?, ?, ?, ?)
-			`
-			_, err = tx.ExecContext(ctx, insertQuery, user.ID, user.DailyCalorieGoal, targetProtein, targetCarbs, targetFats, user.Weight)
-			if err != nil {
-				return wrapDatabaseError(err)
-			}
-		} else {
-			return wrapDatabaseError(err)
-		}
-	} else {
-		var targetProtein, targetCarbs, targetFats float64
-		caloriesFromProtein := 4.0 // 4 calories per gram of protein
-		caloriesFromCarbs := 4.0   // 4 calories per gram of carbs
-		caloriesFromFats := 9.0    // 9 calories per gram of fat
-
-		switch user.GoalType {
-		case "lose":
-			// For weight loss: protein 35%, fats 35%, carbs 30%
-			targetProtein = float64(user.DailyCalorieGoal) * 0.35 / caloriesFromProtein
-			targetFats = float64(user.DailyCalorieGoal) * 0.35 / caloriesFromFats
-			targetCarbs = float64(user.DailyCalorieGoal) * 0.30 / caloriesFromCarbs
-		case "maintain":
-			// For weight maintenance: protein 25%, fats 25%, carbs 50%
-			targetProtein = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromProtein
-			targetFats = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromFats
-			targetCarbs = float64(user.DailyCalorieGoal) * 0.50 / caloriesFromCarbs
-		case "gain":
-			// For weight gain: protein 30%, fats 25%, carbs 45%
-			targetProtein = float64(user.DailyCalorieGoal) * 0.30 / caloriesFromProtein
-			targetFats = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromFats
-			targetCarbs = float64(user.DailyCalorieGoal) * 0.45 / caloriesFromCarbs
-		default:
-			// Default to maintenance if goal type is invalid
-			targetProtein = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromProtein
-			targetFats = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromFats
-			targetCarbs = float64(user.DailyCalorieGoal) * 0.50 / caloriesFromCarbs
-		}
-
-		// Update macronutrient targets
-		macroUpdateQuery := `
-			UPDATE user_goals 
-			SET target_protein = ?, target_carbs = ?, target_fats = ?
-			WHERE user_id = ?
-		`
-		_, err = tx.ExecContext(ctx, macroUpdateQuery, targetProtein, targetCarbs, targetFats, user.ID)
-		if err != nil {
-			return wrapDatabaseError(err)
-		}
+	// The calorie goal may have changed, and so may the goal type and weight
+	// the macro targets depend on.
+	if err := r.saveCalorieTargets(ctx, tx, r.planner.Goals(user, user.DailyCalorieGoal)); err != nil {
+		return err
 	}
 
 	if err = tx.Commit(); err != nil {
@@ -327,13 +243,13 @@ func (r *userRepository) DeleteUser(ctx context.Context, id int) error {
 	defer tx.Rollback()
 
 	goalsQuery := `DELETE FROM user_goals WHERE user_id = ?`
-	_, err = tx.ExecContext(ctx, goalsQuery, id)
+	_, err = tx.ExecContext(ctx, tx.Rebind(goalsQuery), id)
 	if err != nil {
 		return wrapDatabaseError(err)
 	}
 
 	userQuery := `DELETE FROM users WHERE id = ?`
-	result, err := tx.ExecContext(ctx, userQuery, id)
+	result, err := tx.ExecContext(ctx, tx.Rebind(userQuery), id)
 	if err != nil {
 		return wrapDatabaseError(err)
 	}
@@ -354,11 +270,31 @@ func (r *userRepository) DeleteUser(ctx context.Context, id int) error {
 	return nil
 }
 
+func (r *userRepository) MarkEmailVerified(ctx context.Context, userID int, at time.Time) error {
+	query := `UPDATE users SET email_verified_at = ? WHERE id = ? AND email_verified_at IS NULL`
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), at, userID); err != nil {
+		return wrapDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *userRepository) IsEmailVerified(ctx context.Context, userID int) (bool, error) {
+	var verifiedAt *time.Time
+	err := r.db.GetContext(ctx, &verifiedAt, r.db.Rebind(`SELECT email_verified_at FROM users WHERE id = ?`), userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, ErrUserNotFound
+		}
+		return false, wrapDatabaseError(err)
+	}
+	return verifiedAt != nil, nil
+}
+
 func (r *userRepository) GetUserGoals(ctx context.Context, userID int) (*models.UserGoals, error) {
 	query := `SELECT * FROM user_goals WHERE user_id = ?`
-	var goals models.UserGoals
+	var userGoals models.UserGoals
 
-	err := r.db.GetContext(ctx, &goals, query, userID)
+	err := r.db.GetContext(ctx, &userGoals, r.db.Rebind(query), userID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			user, err := r.FindByID(ctx, userID)
@@ -366,54 +302,17 @@ func (r *userRepository) GetUserGoals(ctx context.Context, userID int) (*models.
 				return nil, err
 			}
 
-			var targetProtein, targetCarbs, targetFats float64
-			caloriesFromProtein := 4.0 // 4 calories per gram of protein
-			caloriesFromCarbs := 4.0   // 4 calories per gram of carbs
-			caloriesFromFats := 9.0    // 9 calories per gram of fat
-
-			switch user.GoalType {
-			case "lose":
-				// For weight loss: protein 35%, fats 35%, carbs 30%
-				targetProtein = float64(user.DailyCalorieGoal) * 0.35 / caloriesFromProtein
-				targetFats = float64(user.DailyCalorieGoal) * 0.35 / caloriesFromFats
-				targetCarbs = float64(user.DailyCalorieGoal) * 0.30 / caloriesFromCarbs
-			case "maintain":
-				// For weight maintenance: protein 25%, fats 25%, carbs 50%
-				targetProtein = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromProtein
-				targetFats = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromFats
-				targetCarbs = float64(user.DailyCalorieGoal) * 0.50 / caloriesFromCarbs
-			case "gain":
-				// For weight gain: protein 30%, fats 25%, carbs 45%
-				targetProtein = float64(user.DailyCalorieGoal) * 0.30 / caloriesFromProtein
-				targetFats = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromFats
-				targetCarbs = float64(user.DailyCalorieGoal) * 0.45 / caloriesFromCarbs
-			default:
-				// Default to maintenance if goal type is invalid
-				targetProtein = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromProtein
-				targetFats = float64(user.DailyCalorieGoal) * 0.25 / caloriesFromFats
-				targetCarbs = float64(user.DailyCalorieGoal) * 0.50 / caloriesFromCarbs
-			}
-
-			goals = models.UserGoals{
-				UserID:         userID,
-				TargetCalories: user.DailyCalorieGoal,
-				TargetProtein:  targetProtein,
-				TargetCarbs:    targetCarbs,
-				TargetFats:     targetFats,
-				TargetWeight:   user.Weight,
-			}
-
-			err = r.UpdateUserGoals(ctx, &goals)
-			if err != nil {
+			generated := r.planner.Goals(user, user.DailyCalorieGoal)
+			if err := r.UpdateUserGoals(ctx, generated); err != nil {
 				return nil, err
 			}
 
-			return &goals, nil
+			return generated, nil
 		}
 		return nil, wrapDatabaseError(err)
 	}
 
-	return &goals, nil
+	return &userGoals, nil
 }
 
 func (r *userRepository) UpdateUserGoals(ctx context.Context, goals *models.UserGoals) error {
@@ -423,47 +322,18 @@ func (r *userRepository) UpdateUserGoals(ctx context.Context, goals *models.User
 	}
 	defer tx.Rollback()
 
-	var goalType string
-	userQuery := `SELECT goal_type FROM users WHERE id = ?`
-	err = tx.GetContext(ctx, &goalType, userQuery, goals.UserID)
+	user, err := findUserTx(ctx, tx, goals.UserID)
 	if err != nil {
-		return wrapDatabaseError(err)
+		return err
 	}
 
-	recalculateMacros := goals.TargetProtein == 0 || goals.TargetCarbs == 0 || goals.TargetFats == 0
-
-	if recalculateMacros {
-		caloriesFromProtein := 4.0 // 4 calories per gram of protein
-		caloriesFromCarbs := 4.0   // 4 calories per gram of carbs
-		caloriesFromFats := 9.0    // 9 calories per gram of fat
-
-		switch goalType {
-		case "lose":
-			// For weight loss: protein 35%, fats 35%, carbs 30%
-			goals.TargetProtein = float64(goals.TargetCalories) * 0.35 / caloriesFromProtein
-			goals.TargetFats = float64(goals.TargetCalories) * 0.35 / caloriesFromFats
-			goals.TargetCarbs = float64(goals.TargetCalories) * 0.30 / caloriesFromCarbs
-		case "maintain":
-			// For weight maintenance: protein 25%, fats 25%, carbs 50%
-			goals.TargetProtein = float64(goals.TargetCalories) * 0.25 / caloriesFromProtein
-			goals.TargetFats = float64(goals.TargetCalories) * 0.25 / caloriesFromFats
-			goals.TargetCarbs = float64(goals.TargetCalories) * 0.50 / caloriesFromCarbs
-		case "gain":
-			// For weight gain: protein 30%, fats 25%, carbs 45%
-			goals.TargetProtein = float64(goals.TargetCalories) * 0.30 / caloriesFromProtein
-			goals.TargetFats = float64(goals.TargetCalories) * 0.25 / caloriesFromFats
-			goals.TargetCarbs = float64(goals.TargetCalories) * 0.45 / caloriesFromCarbs
-		default:
-			// Default to maintenance if goal type is invalid
-			goals.TargetProtein = float64(goals.TargetCalories) * 0.25 / caloriesFromProtein
-			goals.TargetFats = float64(goals.TargetCalories) * 0.25 / caloriesFromFats
-			goals.TargetCarbs = float64(goals.TargetCalories) * 0.50 / caloriesFromCarbs
-		}
+	if goals.TargetProtein == 0 || goals.TargetCarbs == 0 || goals.TargetFats == 0 {
+		r.planner.FillMacros(goals, user)
 	}
 
 	checkQuery := `SELECT COUNT(*) FROM user_goals WHERE user_id = ?`
 	var count int
-	err = tx.GetContext(ctx, &count, checkQuery, goals.UserID)
+	err = tx.GetContext(ctx, &count, tx.Rebind(checkQuery), goals.UserID)
 	if err != nil {
 		return wrapDatabaseError(err)
 	}
@@ -475,7 +345,7 @@ func (r *userRepository) UpdateUserGoals(ctx context.Context, goals *models.User
 				user_id, target_calories, target_protein, target_carbs, target_fats, target_weight
 			) VALUES (?, ?, ?, ?, ?, ?)
 		`
-		result, err = tx.ExecContext(ctx, insertQuery,
+		result, err = tx.ExecContext(ctx, tx.Rebind(insertQuery),
 			goals.UserID, goals.TargetCalories, goals.TargetProtein,
 			goals.TargetCarbs, goals.TargetFats, goals.TargetWeight)
 	} else {
@@ -485,7 +355,7 @@ func (r *userRepository) UpdateUserGoals(ctx context.Context, goals *models.User
 				target_fats = ?, target_weight = ?
 			WHERE user_id = ?
 		`
-		result, err = tx.ExecContext(ctx, updateQuery,
+		result, err = tx.ExecContext(ctx, tx.Rebind(updateQuery),
 			goals.TargetCalories, goals.TargetProtein, goals.TargetCarbs,
 			goals.TargetFats, goals.TargetWeight, goals.UserID)
 	}
@@ -509,7 +379,7 @@ func (r *userRepository) UpdateUserGoals(ctx context.Context, goals *models.User
 		WHERE id = ?
 	`
 
-	_, err = tx.ExecContext(ctx, userUpdateQuery, goals.TargetCalories, time.Now(), goals.UserID)
+	_, err = tx.ExecContext(ctx, tx.Rebind(userUpdateQuery), goals.TargetCalories, time.Now(), goals.UserID)
 	if err != nil {
 		return wrapDatabaseError(err)
 	}
@@ -537,7 +407,7 @@ func (r *userRepository) SyncUserCalorieGoal(ctx context.Context, userID int, ca
 		WHERE id = ?
 	`
 
-	userResult, err := tx.ExecContext(ctx, userUpdateQuery, calorieGoal, time.Now(), userID)
+	userResult, err := tx.ExecContext(ctx, tx.Rebind(userUpdateQuery), calorieGoal, time.Now(), userID)
 	if err != nil {
 		return wrapDatabaseError(err)
 	}
@@ -551,99 +421,155 @@ func (r *userRepository) SyncUserCalorieGoal(ctx context.Context, userID int, ca
 		return ErrUserNotFound
 	}
 
-	var goalType string
-	goalTypeQuery := `SELECT goal_type FROM users WHERE id = ?`
-	err = tx.GetContext(ctx, &goalType, goalTypeQuery, userID)
+	user, err := findUserTx(ctx, tx, userID)
 	if err != nil {
-		return wrapDatabaseError(err)
+		return err
 	}
 
-	caloriesFromProtein := 4.0 // 4 calories per gram of protein
-	caloriesFromCarbs := 4.0   // 4 calories per gram of carbs
-	caloriesFromFats := 9.0    // 9 calories per gram of fat
-
-	var targetProtein, targetCarbs, targetFats float64
-
-	switch goalType {
-	case "lose":
-		// For weight loss: protein 35%, fats 35%, carbs 30%
-		targetProtein = float64(calorieGoal) * 0.35 / caloriesFromProtein
-		targetFats = float64(calorieGoal) * 0.35 / caloriesFromFats
-		targetCarbs = float64(calorieGoal) * 0.30 / caloriesFromCarbs
-	case "maintain":
-		// For weight maintenance: protein 25%, fats 25%, carbs 50%
-		targetProtein = float64(calorieGoal) * 0.25 / caloriesFromProtein
-		targetFats = float64(calorieGoal) * 0.25 / caloriesFromFats
-		targetCarbs = float64(calorieGoal) * 0.50 / caloriesFromCarbs
-	case "gain":
-		// For weight gain: protein 30%, fats 25%, carbs 45%
-		targetProtein = float64(calorieGoal) * 0.30 / caloriesFromProtein
-		targetFats = float64(calorieGoal) * 0.25 / caloriesFromFats
-		targetCarbs = float64(calorieGoal) * 0.45 / caloriesFromCarbs
-	default:
-		// Default to maintenance if goal type is invalid
-		targetProtein = float64(calorieGoal) * 0.25 / caloriesFromProtein
-		targetFats = float64(calorieGoal) * 0.25 / caloriesFromFats
-		targetCarbs = float64(calorieGoal) * 0.50 / caloriesFromCarbs
+	if err := r.saveCalorieTargets(ctx, tx, r.planner.Goals(user, calorieGoal)); err != nil {
+		return err
 	}
 
-	checkQuery := `SELECT 1 FROM user_goals WHERE user_id = ?`
-	var exists bool
-	err = tx.GetContext(ctx, &exists, checkQuery, userID)
-
-	if err != nil && errors.Is(err, sql.ErrNoRows) {
-		var weight float64
-		weightQuery := `SELECT weight FROM users WHERE id = ?`
-		err = tx.GetContext(ctx, &weight, weightQuery, userID)
-		if err != nil {
-			return wrapDatabaseError(err)
-		}
-
-		insertQuery := `
-			INSERT INTO user_goals (
-				user_id, target_calories, target_protein, target_carbs, target_fats, target_weight
-			) VALUES (?, ?, ?, ?, ?, ?)
-		`
-		_, err = tx.ExecContext(ctx, insertQuery, userID, calorieGoal, targetProtein, targetCarbs, targetFats, weight)
-	} else {
-		updateQuery := `
-			UPDATE user_goals 
-			SET target_calories = ?, target_protein = ?, target_carbs = ?, target_fats = ?
-			WHERE user_id = ?
-		`
-		_, err = tx.ExecContext(ctx, updateQuery, calorieGoal, targetProtein, targetCarbs, targetFats, userID)
+	if err = tx.Commit(); err != nil {
+		return wrapDatabaseError(err)
 	}
 
+	return nil
+}
+
+// saveCalorieTargets writes the calorie and macro targets of goals. The target
+// weight is only written when the user has no goals yet.
+func (r *userRepository) saveCalorieTargets(ctx context.Context, tx *sqlx.Tx, goals *models.UserGoals) error {
+	query := `
+		INSERT INTO user_goals (
+			user_id, target_calories, target_protein, target_carbs, target_fats, target_weight
+		) VALUES (?, ?, ?, ?, ?, ?)
+	` + r.dialect.Upsert(
+		[]string{"user_id"},
+		[]string{"target_calories", "target_protein", "target_carbs", "target_fats"},
+	)
+
+	_, err := tx.ExecContext(ctx, tx.Rebind(query),
+		goals.UserID, goals.TargetCalories, goals.TargetProtein,
+		goals.TargetCarbs, goals.TargetFats, goals.TargetWeight)
 	if err != nil {
 		return wrapDatabaseError(err)
 	}
+	return nil
+}
 
-	if err = tx.Commit(); err != nil {
-		return wrapDatabaseError(err)
+// findUserTx loads a user inside tx, so the rows written in the same
+// transaction are visible.
+func findUserTx(ctx context.Context, tx *sqlx.Tx, id int) (*models.User, error) {
+	var user models.User
+	err := tx.GetContext(ctx, &user, tx.Rebind(`SELECT * FROM users WHERE id = ? LIMIT 1`), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, wrapDatabaseError(err)
 	}
-
-	return nil
+	return &user, nil
 }
 
 func (r *userRepository) GetAllUsers(ctx context.Context) ([]models.User, error) {
 	var users []models.User
 	query := `SELECT * FROM users`
-	err := r.db.SelectContext(ctx, &users, query)
+	err := r.db.SelectContext(ctx, &users, r.db.Rebind(query))
 	if err != nil {
 		return nil, wrapDatabaseError(err)
 	}
 	return users, nil
 }
 
+func (r *userRepository) GetUsersAfter(ctx context.Context, afterID int, role string, limit int) ([]models.User, error) {
+	query := `SELECT * FROM users WHERE id > ? ORDER BY id LIMIT ?`
+	args := []interface{}{afterID, limit}
+	if role != "" {
+		query = `SELECT * FROM users WHERE id > ? AND role = ? ORDER BY id LIMIT ?`
+		args = []interface{}{afterID, role, limit}
+	}
+
+	users := []models.User{}
+	if err := r.db.SelectContext(ctx, &users, r.db.Rebind(query), args...); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	return users, nil
+}
+
+func (r *userRepository) CountUsers(ctx context.Context, role string) (int, error) {
+	query := `SELECT COUNT(*) FROM users`
+	args := []interface{}{}
+	if role != "" {
+		query = `SELECT COUNT(*) FROM users WHERE role = ?`
+		args = append(args, role)
+	}
+
+	var count int
+	if err := r.db.GetContext(ctx, &count, r.db.Rebind(query), args...); err != nil {
+		return 0, wrapDatabaseError(err)
+	}
+	return count, nil
+}
+
+func (r *userRepository) GetGoalsForUsers(ctx context.Context, userIDs []int) (map[int]models.UserGoals, error) {
+	byUser := make(map[int]models.UserGoals, len(userIDs))
+	if len(userIDs) == 0 {
+		return byUser, nil
+	}
+
+	query, args, err := sqlx.In(`SELECT * FROM user_goals WHERE user_id IN (?)`, userIDs)
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	var rows []models.UserGoals
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	for _, row := range rows {
+		byUser[row.UserID] = row
+	}
+	return byUser, nil
+}
+
+func (r *userRepository) GetDietitiansForUsers(ctx context.Context, userIDs []int) (map[int][]int, error) {
+	byUser := make(map[int][]int, len(userIDs))
+	if len(userIDs) == 0 {
+		return byUser, nil
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT user_id, dietitian_id FROM user_dietitian
+		WHERE user_id IN (?) AND status = ?
+		ORDER BY user_id, dietitian_id
+	`, userIDs, models.SubscriptionAccepted)
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	var rows []struct {
+		UserID      int `db:"user_id"`
+		DietitianID int `db:"dietitian_id"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	for _, row := range rows {
+		byUser[row.UserID] = append(byUser[row.UserID], row.DietitianID)
+	}
+	return byUser, nil
+}
+
 func (r *userRepository) GetSubscribedUsers(ctx context.Context, dietitianID int) ([]models.User, error) {
 	query := `
 		SELECT u.* 
 		FROM users u 
 		JOIN user_dietitian ud ON u.id = ud.user_id 
-		WHERE ud.dietitian_id = ?
+		WHERE ud.dietitian_id = ? AND ud.status = ?
 	`
 	var users []models.User
-	err := r.db.SelectContext(ctx, &users, query, dietitianID)
+	err := r.db.SelectContext(ctx, &users, r.db.Rebind(query), dietitianID, models.SubscriptionAccepted)
 	if err != nil {
 		return nil, errors.Join(ErrDatabaseOperation, err)
 	}
@@ -664,115 +590,154 @@ func (r *userRepository) IsUserSubscribedToDietitian(ctx context.Context, userID
 	query := `
 		SELECT EXISTS(
 			SELECT 1 
-			FROM user_dietitian 
-			WHERE user_id = ? AND dietitian_id = ?
+			FROM user_dietitian ud
+			JOIN users d ON d.id = ud.dietitian_id
+			WHERE ud.user_id = ? AND ud.dietitian_id = ? AND ud.status = ? AND d.role = ?
 		)
 	`
 	var exists bool
-	err = r.db.GetContext(ctx, &exists, query, userIDInt, dietitianID)
+	err = r.db.GetContext(ctx, &exists, r.db.Rebind(query), userIDInt, dietitianID, models.SubscriptionAccepted, models.RoleDietitian)
 	if err != nil {
 		return false, ErrDatabaseOperation
 	}
 	return exists, nil
 }
 
-func (r *userRepository) SubscribeUserToDietitian(ctx context.Context, userID int, dietitianID int) error {
+func (r *userRepository) SubscribeUserToDietitian(ctx context.Context, userID int, dietitianID int) (*models.DietitianSubscription, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
 	dietitianQuery := `SELECT EXISTS(SELECT 1 FROM users WHERE id = ? AND role = 'dietitian')`
 	var dietitianExists bool
-	err := r.db.GetContext(ctx, &dietitianExists, dietitianQuery, dietitianID)
+	err = tx.GetContext(ctx, &dietitianExists, tx.Rebind(dietitianQuery), dietitianID)
 	if err != nil {
-		fmt.Printf("Error checking if dietitian exists: %v\n", err)
-		return errors.Join(ErrDatabaseOperation, err)
+		return nil, wrapDatabaseError(err)
 	}
-
 	if !dietitianExists {
-		return errors.New("dietitian not found")
+		return nil, ErrDietitianNotFound
 	}
 
-	query := `
-		SELECT EXISTS(
-			SELECT 1 
-			FROM user_dietitian 
+	subscription, err := r.findSubscriptionTx(ctx, tx, userID, dietitianID)
+	switch {
+	case errors.Is(err, ErrSubscriptionNotFound):
+		subscription = &models.DietitianSubscription{
+			UserID:      userID,
+			DietitianID: dietitianID,
+			Status:      models.SubscriptionPending,
+			RequestedAt: time.Now(),
+		}
+		insertQuery := `INSERT INTO user_dietitian (user_id, dietitian_id, status, assigned_at) VALUES (?, ?, ?, ?)`
+		_, err = tx.ExecContext(ctx, tx.Rebind(insertQuery), userID, dietitianID, subscription.Status, subscription.RequestedAt)
+		if err != nil {
+			return nil, wrapDatabaseError(err)
+		}
+	case err != nil:
+		return nil, err
+	case subscription.Status == models.SubscriptionPending || subscription.Status == models.SubscriptionAccepted:
+		// Asking again does not reset a request or an active relationship.
+		return subscription, nil
+	default:
+		subscription.Status = models.SubscriptionPending
+		subscription.RequestedAt = time.Now()
+		subscription.RespondedAt = nil
+		subscription.EndedAt = nil
+		updateQuery := `
+			UPDATE user_dietitian
+			SET status = ?, assigned_at = ?, responded_at = NULL, ended_at = NULL
 			WHERE user_id = ? AND dietitian_id = ?
-		)
-	`
-	var exists bool
-	err = r.db.GetContext(ctx, &exists, query, userID, dietitianID)
-	if err != nil {
-		fmt.Printf("Error checking if subscription exists: %v\n", err)
-		return errors.Join(ErrDatabaseOperation, err)
+		`
+		_, err = tx.ExecContext(ctx, tx.Rebind(updateQuery), subscription.Status, subscription.RequestedAt, userID, dietitianID)
+		if err != nil {
+			return nil, wrapDatabaseError(err)
+		}
 	}
 
-	if exists {
-		return nil
+	if err = tx.Commit(); err != nil {
+		return nil, wrapDatabaseError(err)
 	}
 
-	createTableQuery := `
-		CREATE TABLE IF NOT EXISTS user_dietitian (
-			user_id INT NOT NULL,
-			dietitian_id INT NOT NULL,
-			created_at TIMESTAMP NOT NULL,
-			PRIMARY KEY (user_id, dietitian_id),
-			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
-			FOREIGN KEY (dietitian_id) REFERENCES users(id) ON DELETE CASCADE
-		)
-	`
-	_, err = r.db.ExecContext(ctx, createTableQuery)
+	return subscription, nil
+}
+
+func (r *userRepository) RespondToSubscription(ctx context.Context, userID int, dietitianID int, accept bool) (*models.DietitianSubscription, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		fmt.Printf("Error creating user_dietitian table: %v\n", err)
-		return errors.Join(ErrDatabaseOperation, err)
+		return nil, wrapDatabaseError(err)
 	}
+	defer tx.Rollback()
 
-	columnCheckQuery := `
-		SELECT COUNT(*) 
-		FROM information_schema.COLUMNS 
-		WHERE 
-			TABLE_SCHEMA = DATABASE() AND 
-			TABLE_NAME = 'user_dietitian' AND 
-			COLUMN_NAME = 'created_at'
-	`
-	var createdAtExists int
-	err = r.db.GetContext(ctx, &createdAtExists, columnCheckQuery)
+	// Locking the dietitian serializes concurrent accepts against the
+	// capacity check below.
+	var lockedID int
+	err = tx.GetContext(ctx, &lockedID,
+		tx.Rebind(`SELECT id FROM users WHERE id = ?`+r.dialect.ForUpdate()), dietitianID)
 	if err != nil {
-		fmt.Printf("Error checking if created_at column exists: %v\n", err)
-		return errors.Join(ErrDatabaseOperation, err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSubscriptionNotFound
+		}
+		return nil, wrapDatabaseError(err)
 	}
 
-	if createdAtExists == 0 {
-		fmt.Println("Adding created_at column to user_dietitian table...")
-		alterTableQuery := `ALTER TABLE user_dietitian ADD COLUMN created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP`
-		_, err = r.db.ExecContext(ctx, alterTableQuery)
-		if err != nil {
-			fmt.Printf("Error adding created_at column: %v\n", err)
-			simpleInsertQuery := `INSERT INTO user_dietitian (user_id, dietitian_id) VALUES (?, ?)`
-			_, err = r.db.ExecContext(ctx, simpleInsertQuery, userID, dietitianID)
+	var capacity sql.NullInt64
+	err = tx.GetContext(ctx, &capacity,
+		tx.Rebind(`SELECT client_capacity FROM dietitian_profiles WHERE user_id = ?`), dietitianID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, wrapDatabaseError(err)
+	}
+
+	subscription, err := r.findSubscriptionTx(ctx, tx, userID, dietitianID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.Status != models.SubscriptionPending {
+		return nil, ErrSubscriptionNotPending
+	}
+
+	subscription.Status = models.SubscriptionDeclined
+	if accept {
+		if capacity.Valid {
+			var clients int64
+			err = tx.GetContext(ctx, &clients,
+				tx.Rebind(`SELECT COUNT(*) FROM user_dietitian WHERE dietitian_id = ? AND status = ?`),
+				dietitianID, models.SubscriptionAccepted)
 			if err != nil {
-				fmt.Printf("Error inserting subscription without created_at: %v\n", err)
-				return errors.Join(ErrDatabaseOperation, err)
+				return nil, wrapDatabaseError(err)
+			}
+			if clients >= capacity.Int64 {
+				return nil, ErrDietitianAtCapacity
 			}
-			return nil
 		}
+		subscription.Status = models.SubscriptionAccepted
 	}
 
-	if createdAtExists > 0 {
-		insertQuery := `INSERT INTO user_dietitian (user_id, dietitian_id, created_at) VALUES (?, ?, ?)`
-		_, err = r.db.ExecContext(ctx, insertQuery, userID, dietitianID, time.Now())
-	} else {
-		insertQuery := `INSERT INTO user_dietitian (user_id, dietitian_id) VALUES (?, ?)`
-		_, err = r.db.ExecContext(ctx, insertQuery, userID, dietitianID)
+	now := time.Now()
+	subscription.RespondedAt = &now
+	_, err = tx.ExecContext(ctx,
+		tx.Rebind(`UPDATE user_dietitian SET status = ?, responded_at = ? WHERE user_id = ? AND dietitian_id = ?`),
+		subscription.Status, now, userID, dietitianID)
+	if err != nil {
+		return nil, wrapDatabaseError(err)
 	}
 
-	if err != nil {
-		fmt.Printf("Error inserting subscription: %v\n", err)
-		return errors.Join(ErrDatabaseOperation, err)
+	if err = tx.Commit(); err != nil {
+		return nil, wrapDatabaseError(err)
 	}
 
-	return nil
+	return subscription, nil
 }
 
 func (r *userRepository) UnsubscribeUserFromDietitian(ctx context.Context, userID int, dietitianID int) error {
-	deleteQuery := `DELETE FROM user_dietitian WHERE user_id = ? AND dietitian_id = ?`
-	result, err := r.db.ExecContext(ctx, deleteQuery, userID, dietitianID)
+	updateQuery := `
+		UPDATE user_dietitian
+		SET status = ?, ended_at = ?
+		WHERE user_id = ? AND dietitian_id = ? AND status IN (?, ?)
+	`
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(updateQuery),
+		models.SubscriptionEnded, time.Now(), userID, dietitianID,
+		models.SubscriptionPending, models.SubscriptionAccepted)
 	if err != nil {
 		return ErrDatabaseOperation
 	}
@@ -783,16 +748,63 @@ func (r *userRepository) UnsubscribeUserFromDietitian(ctx context.Context, userI
 	}
 
 	if rowsAffected == 0 {
-		return nil
+		return ErrSubscriptionNotFound
 	}
 
 	return nil
 }
 
+func (r *userRepository) GetSubscriptionRequests(ctx context.Context, dietitianID int) ([]models.DietitianSubscription, error) {
+	query := `
+		SELECT ud.*, u.full_name
+		FROM user_dietitian ud
+		JOIN users u ON u.id = ud.user_id
+		WHERE ud.dietitian_id = ? AND ud.status = ?
+		ORDER BY ud.assigned_at
+	`
+	requests := []models.DietitianSubscription{}
+	if err := r.db.SelectContext(ctx, &requests, r.db.Rebind(query), dietitianID, models.SubscriptionPending); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	return requests, nil
+}
+
+func (r *userRepository) GetUserSubscriptions(ctx context.Context, userID int) ([]models.DietitianSubscription, error) {
+	query := `
+		SELECT ud.*, d.full_name
+		FROM user_dietitian ud
+		JOIN users d ON d.id = ud.dietitian_id
+		WHERE ud.user_id = ?
+		ORDER BY ud.assigned_at DESC
+	`
+	subscriptions := []models.DietitianSubscription{}
+	if err := r.db.SelectContext(ctx, &subscriptions, r.db.Rebind(query), userID); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *userRepository) findSubscriptionTx(ctx context.Context, tx *sqlx.Tx, userID int, dietitianID int) (*models.DietitianSubscription, error) {
+	var subscription models.DietitianSubscription
+	err := tx.GetContext(ctx, &subscription,
+		tx.Rebind(`SELECT * FROM user_dietitian WHERE user_id = ? AND dietitian_id = ?`+r.dialect.ForUpdate()),
+		userID, dietitianID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSubscriptionNotFound
+		}
+		return nil, wrapDatabaseError(err)
+	}
+
+	return &subscription, nil
+}
+
 func (r *userRepository) GetAvailableDietitians(ctx context.Context) ([]models.User, error) {
 	query := `SELECT * FROM users WHERE role = 'dietitian'`
 	var dietitians []models.User
-	err := r.db.SelectContext(ctx, &dietitians, query)
+	err := r.db.SelectContext(ctx, &dietitians, r.db.Rebind(query))
 	if err != nil {
 		return nil, ErrDatabaseOperation
 	}
@@ -810,19 +822,19 @@ func (r *userRepository) GetUserProgress(ctx context.Context, userID string) (ma
 		return nil, err
 	}
 
-	nutritionQuery := `
+	nutritionQuery := fmt.Sprintf(`
 		SELECT 
-			DATE(entry_date) as date,
+			%[1]s as date,
 			SUM(calories) as total_calories,
 			SUM(protein) as total_protein,
 			SUM(carbs) as total_carbs,
 			SUM(fats) as total_fats
 		FROM consumed_foods
 		WHERE user_id = ?
-		GROUP BY DATE(entry_date)
-		ORDER BY DATE(entry_date) ASC
+		GROUP BY %[1]s
+		ORDER BY %[1]s ASC
 		LIMIT 30
-	`
+	`, r.dialect.DateString("entry_date"))
 
 	type NutritionData struct {
 		Date     string  `db:"date"`
@@ -833,15 +845,15 @@ func (r *userRepository) GetUserProgress(ctx context.Context, userID string) (ma
 	}
 
 	var nutritionHistory []NutritionData
-	err = r.db.SelectContext(ctx, &nutritionHistory, nutritionQuery, userIDInt)
+	err = r.db.SelectContext(ctx, &nutritionHistory, r.db.Rebind(nutritionQuery), userIDInt)
 	if err != nil {
 		return nil, errors.Join(ErrDatabaseOperation, err)
 	}
 
 	if len(nutritionHistory) == 0 {
-		dailyEntriesQuery := `
+		dailyEntriesQuery := fmt.Sprintf(`
 			SELECT 
-				entry_date as date,
+				%s as date,
 				total_calories,
 				total_protein,
 				total_carbs,
@@ -850,18 +862,18 @@ func (r *userRepository) GetUserProgress(ctx context.Context, userID string) (ma
 			WHERE user_id = ?
 			ORDER BY entry_date ASC
 			LIMIT 30
-		`
+		`, r.dialect.DateString("entry_date"))
 
 		type DailyEntryData struct {
 			Date     string  `db:"date"`
-			Calories int     `db:"total_calories"`
+			Calories float64 `db:"total_calories"`
 			Protein  float64 `db:"total_protein"`
 			Carbs    float64 `db:"total_carbs"`
 			Fats     float64 `db:"total_fats"`
 		}
 
 		var dailyEntries []DailyEntryData
-		err = r.db.SelectContext(ctx, &dailyEntries, dailyEntriesQuery, userIDInt)
+		err = r.db.SelectContext(ctx, &dailyEntries, r.db.Rebind(dailyEntriesQuery), userIDInt)
 		if err != nil {
 			return map[string]interface{}{
 				"nutritionHistory": map[string]interface{}{
@@ -879,7 +891,7 @@ func (r *userRepository) GetUserProgress(ctx context.Context, userID string) (ma
 			for i, de := range dailyEntries {
 				nutritionHistory[i] = NutritionData{
 					Date:     de.Date,
-					Calories: float64(de.Calories),
+					Calories: de.Calories,
 					Protein:  de.Protein,
 					Carbs:    de.Carbs,
 					Fats:     de.Fats,
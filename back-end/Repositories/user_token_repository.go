@@ -0,0 +1,166 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	models "HabitBite/backend/Models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrUserTokenInvalid is returned for unknown, expired and already used user
+// tokens.
+var ErrUserTokenInvalid = errors.New("token is invalid or expired")
+
+// UserTokenRepository stores the single-use tokens mailed to users.
+type UserTokenRepository interface {
+	// CreateUserToken stores a token. The unused tokens the user has for the
+	// same purpose are deleted, so only the latest link works.
+	CreateUserToken(ctx context.Context, token *models.UserToken) error
+	// VerifyEmail marks the email verification token with the given hash as
+	// used and the email of its user as verified, in one transaction, so a
+	// failed update leaves the link usable. It fails with ErrUserTokenInvalid
+	// if the token doesn't exist, expired or was used before.
+	VerifyEmail(ctx context.Context, tokenHash string, now time.Time) (*models.UserToken, error)
+	// ResetPassword consumes the password reset token with the given hash,
+	// sets the password hash of its user and deletes the user's other unused
+	// reset tokens, all in one transaction. It fails like VerifyEmail.
+	ResetPassword(ctx context.Context, tokenHash, passwordHash string, now time.Time) (*models.UserToken, error)
+	// LatestUserTokenAt returns when the user's latest token for the purpose
+	// was created, or nil if they have none.
+	LatestUserTokenAt(ctx context.Context, userID int, purpose string) (*time.Time, error)
+}
+
+type userTokenRepository struct {
+	db      *sqlx.DB
+	dialect dialect
+}
+
+func NewUserTokenRepository(db *sqlx.DB) UserTokenRepository {
+	return &userTokenRepository{db: db, dialect: dialectFor(db)}
+}
+
+func (r *userTokenRepository) CreateUserToken(ctx context.Context, token *models.UserToken) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	deleteQuery := `DELETE FROM user_tokens WHERE user_id = ? AND purpose = ? AND used_at IS NULL`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(deleteQuery), token.UserID, token.Purpose); err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	query := `
+		INSERT INTO user_tokens (user_id, purpose, token_hash, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	id, err := r.dialect.InsertReturningID(ctx, tx, query,
+		token.UserID, token.Purpose, token.TokenHash, token.CreatedAt, token.ExpiresAt)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	token.ID = int(id)
+
+	if err := tx.Commit(); err != nil {
+		return wrapDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *userTokenRepository) VerifyEmail(ctx context.Context, tokenHash string, now time.Time) (*models.UserToken, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	token, err := r.consumeUserToken(ctx, tx, models.TokenEmailVerification, tokenHash, now)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `UPDATE users SET email_verified_at = ? WHERE id = ? AND email_verified_at IS NULL`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), now, token.UserID); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	return token, nil
+}
+
+func (r *userTokenRepository) ResetPassword(ctx context.Context, tokenHash, passwordHash string, now time.Time) (*models.UserToken, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	token, err := r.consumeUserToken(ctx, tx, models.TokenPasswordReset, tokenHash, now)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := tx.ExecContext(ctx, tx.Rebind(`UPDATE users SET password_hash = ?, updated_at = ? WHERE id = ?`),
+		passwordHash, now, token.UserID)
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	if rowsAffected == 0 {
+		return nil, ErrUserTokenInvalid
+	}
+
+	// Other reset links mailed before this one must not work after it.
+	deleteQuery := `DELETE FROM user_tokens WHERE user_id = ? AND purpose = ? AND used_at IS NULL`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(deleteQuery), token.UserID, models.TokenPasswordReset); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	return token, nil
+}
+
+// consumeUserToken marks a valid token as used within tx and returns it.
+func (r *userTokenRepository) consumeUserToken(ctx context.Context, tx *sqlx.Tx, purpose, tokenHash string, now time.Time) (*models.UserToken, error) {
+	var token models.UserToken
+	query := `SELECT * FROM user_tokens WHERE token_hash = ? AND purpose = ?` + r.dialect.ForUpdate()
+	if err := tx.GetContext(ctx, &token, tx.Rebind(query), tokenHash, purpose); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserTokenInvalid
+		}
+		return nil, wrapDatabaseError(err)
+	}
+	if token.UsedAt != nil || !token.ExpiresAt.After(now) {
+		return nil, ErrUserTokenInvalid
+	}
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(`UPDATE user_tokens SET used_at = ? WHERE id = ?`), now, token.ID); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	token.UsedAt = &now
+	return &token, nil
+}
+
+func (r *userTokenRepository) LatestUserTokenAt(ctx context.Context, userID int, purpose string) (*time.Time, error) {
+	var createdAt time.Time
+	query := `SELECT created_at FROM user_tokens WHERE user_id = ? AND purpose = ? ORDER BY created_at DESC LIMIT 1`
+	if err := r.db.GetContext(ctx, &createdAt, r.db.Rebind(query), userID, purpose); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, wrapDatabaseError(err)
+	}
+	return &createdAt, nil
+}
@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	models "HabitBite/backend/Models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrWeightEntryNotFound  = errors.New("weight entry not found")
+	ErrWeightEntryForbidden = errors.New("weight entry belongs to another user")
+)
+
+// Writes keep users.weight equal to the most recent weigh-in, so the rest of
+// the application can keep reading the current weight from the user row.
+
+type WeightRepository interface {
+	CreateWeightEntry(ctx context.Context, entry *models.WeightEntry) error
+	GetWeightEntries(ctx context.Context, userID int) ([]models.WeightEntry, error)
+	// GetWeightEntriesForUsers returns the weigh-ins of the users recorded
+	// since the given time, ordered by user and then oldest first.
+	GetWeightEntriesForUsers(ctx context.Context, userIDs []int, since time.Time) ([]models.WeightEntry, error)
+	DeleteWeightEntry(ctx context.Context, userID, entryID int) error
+}
+
+type weightRepository struct {
+	db      *sqlx.DB
+	dialect dialect
+}
+
+func NewWeightRepository(db *sqlx.DB) WeightRepository {
+	return &weightRepository{db: db, dialect: dialectFor(db)}
+}
+
+func (r *weightRepository) CreateWeightEntry(ctx context.Context, entry *models.WeightEntry) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	entry.CreatedAt = time.Now()
+
+	query := `INSERT INTO weight_entries (user_id, weight, recorded_at, created_at) VALUES (?, ?, ?, ?)`
+	id, err := r.dialect.InsertReturningID(ctx, tx, query,
+		entry.UserID, entry.Weight, entry.RecordedAt, entry.CreatedAt)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	entry.ID = int(id)
+
+	if err := syncUserWeight(ctx, tx, entry.UserID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	return nil
+}
+
+// GetWeightEntries returns every weigh-in of the user, oldest first.
+func (r *weightRepository) GetWeightEntries(ctx context.Context, userID int) ([]models.WeightEntry, error) {
+	query := `SELECT * FROM weight_entries WHERE user_id = ? ORDER BY recorded_at ASC, id ASC`
+
+	entries := []models.WeightEntry{}
+	if err := r.db.SelectContext(ctx, &entries, r.db.Rebind(query), userID); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	return entries, nil
+}
+
+func (r *weightRepository) GetWeightEntriesForUsers(ctx context.Context, userIDs []int, since time.Time) ([]models.WeightEntry, error) {
+	entries := []models.WeightEntry{}
+	if len(userIDs) == 0 {
+		return entries, nil
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT * FROM weight_entries
+		WHERE user_id IN (?) AND recorded_at >= ?
+		ORDER BY user_id, recorded_at ASC, id ASC
+	`, userIDs, since)
+	if err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+
+	if err := r.db.SelectContext(ctx, &entries, r.db.Rebind(query), args...); err != nil {
+		return nil, wrapDatabaseError(err)
+	}
+	return entries, nil
+}
+
+func (r *weightRepository) DeleteWeightEntry(ctx context.Context, userID, entryID int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	var ownerID int
+	err = tx.GetContext(ctx, &ownerID,
+		tx.Rebind(`SELECT user_id FROM weight_entries WHERE id = ?`+r.dialect.ForUpdate()), entryID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrWeightEntryNotFound
+		}
+		return wrapDatabaseError(err)
+	}
+	if ownerID != userID {
+		return ErrWeightEntryForbidden
+	}
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(`DELETE FROM weight_entries WHERE id = ? AND user_id = ?`), entryID, userID); err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	if err := syncUserWeight(ctx, tx, userID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	return nil
+}
+
+// syncUserWeight copies the latest weigh-in to users.weight. The stored
+// weight is left alone once a user has no weigh-ins.
+func syncUserWeight(ctx context.Context, tx *sqlx.Tx, userID int) error {
+	var latest float64
+	err := tx.GetContext(ctx, &latest, tx.Rebind(`
+		SELECT weight FROM weight_entries
+		WHERE user_id = ?
+		ORDER BY recorded_at DESC, id DESC
+		LIMIT 1
+	`), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+
+	_, err = tx.ExecContext(ctx, tx.Rebind(`UPDATE users SET weight = ?, updated_at = ? WHERE id = ?`),
+		latest, time.Now(), userID)
+	if err != nil {
+		return wrapDatabaseError(err)
+	}
+	return nil
+}
@@ -15,7 +15,7 @@ func SetupAuthRoutes(router *gin.Engine, authController *controllers.AuthControl
 		auth.POST("/login", authController.Login)
 		auth.POST("/logout", authController.Logout)
 		auth.GET("/profile", middleware.AuthMiddleware(cfg), authController.GetCurrentUser)
-		auth.POST("/refresh", middleware.AuthMiddleware(cfg), authController.RefreshToken)
+		auth.POST("/refresh", authController.RefreshToken)
 		auth.GET("/csrf", authController.GetCSRFToken)
 	}
 }
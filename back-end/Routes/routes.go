@@ -1,32 +1,55 @@
 package Routes
 
 import (
+	"time"
+
 	config "HabitBite/backend/Config"
 	controllers "HabitBite/backend/Controllers"
+	jobs "HabitBite/backend/Jobs"
+	mailer "HabitBite/backend/Mailer"
+	messaging "HabitBite/backend/Messaging"
 	middleware "HabitBite/backend/Middleware"
 	models "HabitBite/backend/Models"
 	repositories "HabitBite/backend/Repositories"
+	twofactor "HabitBite/backend/TwoFactor"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jmoiron/sqlx"
+	"golang.org/x/time/rate"
 )
 
-func SetupRoutes(router *gin.Engine, db *sqlx.DB, cfg *config.Config) {
-	userRepo := repositories.NewUserRepository(db)
-	foodEntryRepo := repositories.NewFoodEntryRepository(db)
+func SetupRoutes(router *gin.Engine, store *repositories.Store, runner *jobs.Runner, hub *messaging.Hub, mail mailer.Mailer, secrets *twofactor.Cipher, cfg *config.Config) {
+	userRepo := store.Users
+	foodEntryRepo := store.FoodEntries
+	foodRepo := store.Foods
+	auditRepo := store.Audit
+	weightRepo := store.Weights
 
 	userService := models.NewUserService(userRepo)
 
-	authController := controllers.NewAuthControllerWithService(userService, cfg)
-	foodEntryController := controllers.NewFoodEntryController(foodEntryRepo)
-	adminController := controllers.NewAdminController(userRepo)
-	dietitianController := controllers.NewDietitianController(userRepo)
+	verificationController := controllers.NewVerificationController(userRepo, store.UserTokens, mail, cfg)
+	twoFactorController := controllers.NewTwoFactorController(userRepo, store.TwoFactor, secrets, cfg)
+	authController := controllers.NewAuthControllerWithService(userService, store.RefreshTokens, verificationController, twoFactorController, store.Goals, cfg)
+	foodEntryController := controllers.NewFoodEntryController(foodEntryRepo, foodRepo, userRepo, auditRepo, store.Comments)
+	foodController := controllers.NewFoodController(foodRepo)
+	adminController := controllers.NewAdminController(userRepo, auditRepo)
+	dietitianController := controllers.NewDietitianController(userRepo, store.Comments)
+	weightController := controllers.NewWeightController(weightRepo, userRepo)
+	tdeeController := controllers.NewTDEEController(foodEntryRepo, weightRepo, userRepo, store.Goals)
+	jobController := controllers.NewJobController(runner, store.Jobs)
+	applicationController := controllers.NewDietitianApplicationController(store.Dietitians, userRepo, auditRepo)
+	mealPlanController := controllers.NewMealPlanController(store.MealPlans, foodRepo, foodEntryRepo, userRepo)
+	messageController := controllers.NewMessageController(store.Messages, userRepo, store.RefreshTokens, hub)
+	commentController := controllers.NewCommentController(store.Comments, foodEntryRepo, userRepo)
+	alertController := controllers.NewAlertController(store.Alerts)
+	sessionController := controllers.NewSessionController(store.RefreshTokens, userRepo, auditRepo, cfg)
+	dashboardController := controllers.NewDashboardController(userRepo, foodEntryRepo, weightRepo, store.Alerts)
+	passwordController := controllers.NewPasswordController(userRepo, store.UserTokens, store.RefreshTokens, mail, cfg)
 
 	router.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "http://localhost:3000")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -40,23 +63,71 @@ func SetupRoutes(router *gin.Engine, db *sqlx.DB, cfg *config.Config) {
 	{
 		public.POST("/auth/register", authController.Register)
 		public.POST("/auth/login", authController.Login)
+		public.POST("/auth/login/2fa", middleware.RateLimiter(rate.Every(time.Minute), 5), authController.LoginTwoFactor)
 		public.POST("/auth/logout", authController.Logout)
+		public.POST("/auth/refresh", authController.RefreshToken)
 		public.GET("/auth/csrf", authController.GetCSRFToken)
+
+		public.POST("/auth/password/forgot", middleware.RateLimiter(rate.Every(time.Minute), 5), passwordController.ForgotPassword)
+		public.POST("/auth/password/reset", middleware.RateLimiter(rate.Every(time.Minute), 5), passwordController.ResetPassword)
+		public.GET("/auth/verify", middleware.RateLimiter(rate.Every(time.Minute), 10), verificationController.VerifyEmail)
+		public.POST("/auth/verify/resend", middleware.RateLimiter(rate.Every(time.Minute), 5), verificationController.ResendVerification)
 	}
 	protected := router.Group("/api")
-	protected.Use(middleware.AuthMiddleware(cfg))
+	protected.Use(middleware.AuthMiddleware(cfg), middleware.SessionMiddleware(store.RefreshTokens))
+	if cfg.EmailVerification == config.VerificationRestrict {
+		protected.Use(middleware.RequireVerifiedEmail(userRepo))
+	}
+	if len(cfg.MFARequiredRoles) > 0 {
+		protected.Use(middleware.RequireTwoFactor(cfg, store.TwoFactor))
+	}
 	{
 		protected.GET("/auth/profile", authController.GetCurrentUser)
-		protected.POST("/auth/refresh", authController.RefreshToken)
+		protected.GET("/auth/sessions", sessionController.GetSessions)
+		protected.DELETE("/auth/sessions", sessionController.RevokeAllSessions)
+		protected.DELETE("/auth/sessions/:id", sessionController.RevokeSession)
+		protected.GET("/auth/2fa", twoFactorController.GetStatus)
+		protected.DELETE("/auth/2fa", twoFactorController.Disable)
+		protected.POST("/auth/2fa/enroll", twoFactorController.Enroll)
+		protected.POST("/auth/2fa/confirm", middleware.RateLimiter(rate.Every(time.Minute), 5), twoFactorController.Confirm)
+		protected.POST("/auth/2fa/recovery-codes", middleware.RateLimiter(rate.Every(time.Minute), 5), twoFactorController.RegenerateRecoveryCodes)
 		protected.GET("/user/goals", authController.GetUserGoals)
 		protected.PUT("/user/goals", authController.UpdateUserGoals)
+		protected.GET("/user/dietitians", dietitianController.GetMySubscriptions)
+		protected.GET("/user/tdee", tdeeController.GetTDEE)
+		protected.POST("/user/tdee/accept", tdeeController.AcceptSuggestedGoal)
 
 		protected.POST("/consumed-foods", foodEntryController.AddFoodEntry)
 		protected.GET("/consumed-foods/daily", foodEntryController.GetDailyEntries)
 		protected.GET("/consumed-foods/nutrition", foodEntryController.GetDailyNutrition)
+		protected.PUT("/consumed-foods/:id", foodEntryController.UpdateFoodEntry)
+		protected.PATCH("/consumed-foods/:id", foodEntryController.UpdateFoodEntry)
 		protected.DELETE("/consumed-foods/:id", foodEntryController.DeleteFoodEntry)
 		protected.GET("/consumed-foods/history", foodEntryController.GetNutritionHistory)
 
+		protected.GET("/comments", commentController.GetComments)
+		protected.POST("/comments/:commentId/replies", commentController.Reply)
+		protected.DELETE("/comments/:commentId", commentController.DeleteComment)
+
+		protected.POST("/weight", weightController.AddWeightEntry)
+		protected.GET("/weight", weightController.GetWeightEntries)
+		protected.DELETE("/weight/:id", weightController.DeleteWeightEntry)
+
+		protected.GET("/meal-plans", mealPlanController.GetMealPlans)
+		protected.GET("/meal-plans/today", mealPlanController.GetTodayPlan)
+		protected.GET("/meal-plans/:planId", mealPlanController.GetMealPlan)
+		protected.GET("/meal-plans/:planId/adherence", mealPlanController.GetAdherence)
+		protected.POST("/meal-plans/items/:itemId/log", mealPlanController.LogPlanItem)
+
+		protected.GET("/conversations", messageController.GetConversations)
+		protected.POST("/conversations", messageController.StartConversation)
+		protected.GET("/conversations/:id/messages", messageController.GetMessages)
+		protected.POST("/conversations/:id/messages", messageController.SendMessage)
+		protected.POST("/conversations/:id/read", messageController.MarkRead)
+		protected.GET("/conversations/:id/stream", messageController.StreamConversation)
+
+		protected.GET("/foods/search", middleware.RateLimiter(rate.Limit(5), 20), foodController.SearchFoods)
+
 		admin := protected.Group("/admin")
 		admin.Use(middleware.AdminAuthMiddleware())
 		{
@@ -64,20 +135,62 @@ func SetupRoutes(router *gin.Engine, db *sqlx.DB, cfg *config.Config) {
 			admin.POST("/users", adminController.CreateUser)
 			admin.PUT("/users/:id", adminController.UpdateUser)
 			admin.DELETE("/users/:id", adminController.DeleteUser)
-			admin.POST("/recalculate-goals", authController.RecalculateAllUserGoals)
+			admin.GET("/users/:id/sessions", sessionController.AdminGetSessions)
+			admin.DELETE("/users/:id/sessions", sessionController.AdminRevokeAllSessions)
+			admin.DELETE("/users/:id/sessions/:sessionId", sessionController.AdminRevokeSession)
+			admin.POST("/jobs/recalculate-goals", jobController.StartRecalculateGoals)
+			admin.POST("/jobs/evaluate-alerts", jobController.StartEvaluateAlerts)
+			admin.GET("/jobs/:id", jobController.GetJob)
+			admin.GET("/users/:id/consumed-foods/daily", foodEntryController.AdminGetDailyEntries)
+			admin.PUT("/users/:id/consumed-foods/:entryId", foodEntryController.AdminUpdateFoodEntry)
+			admin.PATCH("/users/:id/consumed-foods/:entryId", foodEntryController.AdminUpdateFoodEntry)
+			admin.DELETE("/users/:id/consumed-foods/:entryId", foodEntryController.AdminDeleteFoodEntry)
+			admin.GET("/audit-log", adminController.GetAuditLog)
+			admin.GET("/dietitian-applications", applicationController.GetApplications)
+			admin.GET("/dietitian-applications/:id", applicationController.GetApplication)
+			admin.POST("/dietitian-applications/:id/approve", applicationController.ApproveApplication)
+			admin.POST("/dietitian-applications/:id/reject", applicationController.RejectApplication)
 		}
 
 		protected.GET("/dietitians", dietitianController.GetAvailableDietitians)
 		protected.POST("/dietitians/:dietitianId/subscribe", dietitianController.SubscribeToDietitian)
 		protected.DELETE("/dietitians/:dietitianId/subscribe", dietitianController.UnsubscribeFromDietitian)
+		protected.GET("/dietitians/:dietitianId/profile", applicationController.GetDietitianProfile)
+		protected.POST("/dietitian-applications", applicationController.Apply)
+		protected.GET("/dietitian-applications/mine", applicationController.GetMyApplications)
 
 		dietitian := protected.Group("/dietitian")
 		dietitian.Use(middleware.DietitianAuthMiddleware())
 		{
 			dietitian.GET("/users", dietitianController.GetSubscribedUsers)
+			dietitian.GET("/dashboard", dashboardController.GetDashboard)
+			dietitian.DELETE("/users/:userId", dietitianController.EndSubscription)
+			dietitian.GET("/users/:userId/meal-plans", mealPlanController.DietitianGetMealPlans)
+			dietitian.POST("/users/:userId/meal-plans", mealPlanController.DietitianCreateMealPlan)
+			dietitian.GET("/users/:userId/meal-plans/:planId", mealPlanController.DietitianGetMealPlan)
+			dietitian.PUT("/users/:userId/meal-plans/:planId", mealPlanController.DietitianUpdateMealPlan)
+			dietitian.DELETE("/users/:userId/meal-plans/:planId", mealPlanController.DietitianDeleteMealPlan)
+			dietitian.GET("/users/:userId/meal-plans/:planId/adherence", mealPlanController.DietitianGetAdherence)
+			dietitian.GET("/requests", dietitianController.GetSubscriptionRequests)
+			dietitian.POST("/requests/:userId/accept", dietitianController.AcceptSubscription)
+			dietitian.POST("/requests/:userId/decline", dietitianController.DeclineSubscription)
+			dietitian.PUT("/profile", applicationController.UpdateProfile)
 			dietitian.GET("/users/:userId/progress", dietitianController.GetUserProgress)
 			dietitian.GET("/users/:userId/goals", dietitianController.GetUserGoals)
 			dietitian.PUT("/users/:userId/goals", dietitianController.UpdateUserGoals)
+			dietitian.GET("/users/:userId/tdee", tdeeController.DietitianGetTDEE)
+			dietitian.POST("/users/:userId/tdee/accept", tdeeController.DietitianAcceptSuggestedGoal)
+			dietitian.GET("/users/:userId/consumed-foods/daily", foodEntryController.DietitianGetDailyEntries)
+			dietitian.PUT("/users/:userId/consumed-foods/:entryId", foodEntryController.DietitianUpdateFoodEntry)
+			dietitian.PATCH("/users/:userId/consumed-foods/:entryId", foodEntryController.DietitianUpdateFoodEntry)
+			dietitian.DELETE("/users/:userId/consumed-foods/:entryId", foodEntryController.DietitianDeleteFoodEntry)
+			dietitian.GET("/users/:userId/comments", commentController.DietitianGetComments)
+			dietitian.POST("/users/:userId/comments", commentController.DietitianCreateComment)
+			dietitian.POST("/users/:userId/comments/:commentId/replies", commentController.DietitianReply)
+			dietitian.DELETE("/users/:userId/comments/:commentId", commentController.DietitianDeleteComment)
+			dietitian.GET("/alerts", alertController.GetAlerts)
+			dietitian.POST("/alerts/:id/acknowledge", alertController.AcknowledgeAlert)
+			dietitian.POST("/alerts/:id/snooze", alertController.SnoozeAlert)
 		}
 	}
 }
@@ -0,0 +1,875 @@
+package Routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	alerts "HabitBite/backend/Alerts"
+	config "HabitBite/backend/Config"
+	goals "HabitBite/backend/Goals"
+	jobs "HabitBite/backend/Jobs"
+	mailer "HabitBite/backend/Mailer"
+	messaging "HabitBite/backend/Messaging"
+	models "HabitBite/backend/Models"
+	repositories "HabitBite/backend/Repositories"
+	twofactor "HabitBite/backend/TwoFactor"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testServer serves the API in-process on the memory store, the way the
+// --demo mode does.
+type testServer struct {
+	t      *testing.T
+	router *gin.Engine
+	store  *repositories.Store
+
+	// clients counts the addresses loginTwoFactor has sent from.
+	clients int
+}
+
+const testPassword = "password123"
+
+// newTestServer returns a server on a fresh memory store. The configure
+// functions can change the configuration before the routes are set up.
+func newTestServer(t *testing.T, configure ...func(*config.Config)) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	store := repositories.NewMemoryStore(goals.DefaultPlanner())
+	cfg := &config.Config{
+		JWTSecret:         "test-secret",
+		JWTExpiryHours:    1,
+		EmailVerification: config.VerificationOff,
+		AlertHour:         -1,
+	}
+	for _, change := range configure {
+		change(cfg)
+	}
+	secrets, err := twofactor.NewCipher(make([]byte, twofactor.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := jobs.NewRunner(store, alerts.DefaultRules())
+	t.Cleanup(func() { runner.Shutdown(context.Background()) })
+	hub := messaging.NewHub()
+	t.Cleanup(hub.Close)
+
+	router := gin.New()
+	SetupRoutes(router, store, runner, hub, mailer.NewFileMailer(""), secrets, cfg)
+
+	foods := []*models.Food{
+		{FdcID: 171477, Description: "Chicken, broilers or fryers, breast, meat only, cooked, roasted", DataType: models.FoodTypeSRLegacy, Calories: 165, Protein: 31.02, Carbs: 0, Fat: 3.57},
+		{FdcID: 173944, Description: "Bananas, raw", DataType: models.FoodTypeSRLegacy, Calories: 89, Protein: 1.09, Carbs: 22.84, Fat: 0.33},
+	}
+	if err := store.Foods.UpsertFoods(context.Background(), foods); err != nil {
+		t.Fatal(err)
+	}
+
+	return &testServer{t: t, router: router, store: store}
+}
+
+// createUser stores a verified account with the given role and returns it.
+func (s *testServer) createUser(name, role string) *models.User {
+	s.t.Helper()
+
+	verifiedAt := time.Now()
+	return s.storeUser(name, role, &verifiedAt)
+}
+
+// createUnverifiedUser stores a user who hasn't verified their email yet.
+func (s *testServer) createUnverifiedUser(name string) *models.User {
+	s.t.Helper()
+
+	return s.storeUser(name, models.RoleUser, nil)
+}
+
+func (s *testServer) storeUser(name, role string, verifiedAt *time.Time) *models.User {
+	s.t.Helper()
+
+	user := &models.User{
+		Email:            name + "@example.com",
+		Username:         name,
+		FullName:         "Test " + name,
+		Birthdate:        time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+		Gender:           "female",
+		Height:           170,
+		Weight:           65,
+		GoalType:         models.GoalMaintain,
+		ActivityLevel:    models.ActivityModerate,
+		DailyCalorieGoal: 2000,
+		Role:             role,
+		EmailVerifiedAt:  verifiedAt,
+	}
+	if err := user.SetPassword(testPassword); err != nil {
+		s.t.Fatal(err)
+	}
+	if err := s.store.Users.CreateUser(context.Background(), user); err != nil {
+		s.t.Fatalf("creating %s: %v", name, err)
+	}
+	return user
+}
+
+// login returns an access token for the user.
+func (s *testServer) login(user *models.User) string {
+	s.t.Helper()
+
+	var body struct {
+		Token string `json:"token"`
+	}
+	s.expect("", http.MethodPost, "/api/auth/login", gin.H{"email": user.Email, "password": testPassword}, http.StatusOK, &body)
+	return body.Token
+}
+
+// enableTwoFactor sets up two-factor login for the user of the access token
+// and returns the TOTP secret and recovery codes.
+func (s *testServer) enableTwoFactor(token string) (string, []string) {
+	s.t.Helper()
+
+	var enrollment struct {
+		Secret string `json:"secret"`
+	}
+	s.expect(token, http.MethodPost, "/api/auth/2fa/enroll", gin.H{"password": testPassword}, http.StatusOK, &enrollment)
+	var confirmed struct {
+		RecoveryCodes []string `json:"recoveryCodes"`
+	}
+	s.expect(token, http.MethodPost, "/api/auth/2fa/confirm", gin.H{"code": totp(s.t, enrollment.Secret, 0)}, http.StatusOK, &confirmed)
+	return enrollment.Secret, confirmed.RecoveryCodes
+}
+
+// startLogin checks the password of a user with two-factor login and returns
+// the mfaToken of the second step.
+func (s *testServer) startLogin(user *models.User) string {
+	s.t.Helper()
+
+	var body struct {
+		MFARequired bool   `json:"mfaRequired"`
+		MFAToken    string `json:"mfaToken"`
+	}
+	s.expect("", http.MethodPost, "/api/auth/login", gin.H{"email": user.Email, "password": testPassword}, http.StatusOK, &body)
+	if !body.MFARequired || body.MFAToken == "" {
+		s.t.Fatalf("login of %s did not ask for a second factor", user.Username)
+	}
+	return body.MFAToken
+}
+
+// loginTwoFactor sends the second step of a login. Every call comes from
+// another forwarded address, so the per-address rate limit doesn't apply and
+// only the limits of the mfaToken and the user are left.
+func (s *testServer) loginTwoFactor(body gin.H) *httptest.ResponseRecorder {
+	s.t.Helper()
+
+	var payload bytes.Buffer
+	if err := json.NewEncoder(&payload).Encode(body); err != nil {
+		s.t.Fatal(err)
+	}
+	s.clients++
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/login/2fa", &payload)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d", s.clients))
+
+	recorder := httptest.NewRecorder()
+	s.router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// totp returns the code of secret offset periods from now.
+func totp(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+
+	code, err := twofactor.Code(secret, twofactor.Step(time.Now())+offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// request sends a JSON request with the access token, if any.
+func (s *testServer) request(token, method, path string, body interface{}) *httptest.ResponseRecorder {
+	s.t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			s.t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	recorder := httptest.NewRecorder()
+	s.router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// expect sends a request, fails the test unless it is answered with status
+// and decodes the response into out, if given.
+func (s *testServer) expect(token, method, path string, body interface{}, status int, out interface{}) {
+	s.t.Helper()
+
+	recorder := s.request(token, method, path, body)
+	if recorder.Code != status {
+		s.t.Fatalf("%s %s: status %d, want %d: %s", method, path, recorder.Code, status, recorder.Body.String())
+	}
+	if out != nil {
+		if err := json.Unmarshal(recorder.Body.Bytes(), out); err != nil {
+			s.t.Fatalf("%s %s: decoding %s: %v", method, path, recorder.Body.String(), err)
+		}
+	}
+}
+
+// dailyCalories returns the daily_entries rollup of the user for each day
+// that has one.
+func (s *testServer) dailyCalories(userID int, start, end time.Time) map[string]float64 {
+	s.t.Helper()
+
+	totals, err := s.store.FoodEntries.GetDailyTotalsForUsers(context.Background(), []int{userID}, start, end)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	calories := make(map[string]float64)
+	for _, total := range totals {
+		if total.Calories != 0 {
+			calories[total.Date] = total.Calories
+		}
+	}
+	return calories
+}
+
+func (s *testServer) addEntry(token, foodID string, amount float64, meal string, date time.Time) models.FoodEntry {
+	s.t.Helper()
+
+	var entry models.FoodEntry
+	s.expect(token, http.MethodPost, "/api/consumed-foods",
+		gin.H{"foodId": foodID, "amount": amount, "meal": meal, "date": date}, http.StatusCreated, &entry)
+	return entry
+}
+
+func TestFoodEntryRollups(t *testing.T) {
+	s := newTestServer(t)
+	user := s.createUser("alice", models.RoleUser)
+	token := s.login(user)
+
+	day1 := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	chicken := s.addEntry(token, "171477", 150, models.MealLunch, day1)
+	if chicken.Calories != 247.5 || chicken.Protein != 46.53 {
+		t.Errorf("150 g of chicken = %v kcal, %v g protein; want 247.5 and 46.53", chicken.Calories, chicken.Protein)
+	}
+	banana := s.addEntry(token, "173944", 100, models.MealSnack, day1)
+
+	if got := s.dailyCalories(user.ID, day1, day2); got["2026-03-10"] != 336.5 || len(got) != 1 {
+		t.Errorf("rollups after adding = %v, want 2026-03-10: 336.5", got)
+	}
+
+	var nutrition models.DailyNutrition
+	s.expect(token, http.MethodGet, "/api/consumed-foods/nutrition?date=2026-03-10", nil, http.StatusOK, &nutrition)
+	if nutrition.TotalCalories != 336.5 {
+		t.Errorf("day nutrition = %v kcal, want 336.5", nutrition.TotalCalories)
+	}
+
+	// Editing the amount back and forth recalculates from the catalog rather
+	// than compounding the rounding of the stored values.
+	path := fmt.Sprintf("/api/consumed-foods/%d", chicken.ID)
+	var edited models.FoodEntry
+	s.expect(token, http.MethodPatch, path, gin.H{"amount": 1}, http.StatusOK, &edited)
+	s.expect(token, http.MethodPatch, path, gin.H{"amount": 150}, http.StatusOK, &edited)
+	if edited.Calories != 247.5 || edited.Protein != 46.53 {
+		t.Errorf("after 150 g -> 1 g -> 150 g: %v kcal, %v g protein; want 247.5 and 46.53", edited.Calories, edited.Protein)
+	}
+
+	s.expect(token, http.MethodPatch, fmt.Sprintf("/api/consumed-foods/%d", banana.ID), gin.H{"date": day2}, http.StatusOK, nil)
+	got := s.dailyCalories(user.ID, day1, day2)
+	if got["2026-03-10"] != 247.5 || got["2026-03-11"] != 89 {
+		t.Errorf("rollups after moving an entry = %v, want 2026-03-10: 247.5, 2026-03-11: 89", got)
+	}
+
+	var history []models.DailyNutrition
+	s.expect(token, http.MethodGet, "/api/consumed-foods/history?startDate=2026-03-10&endDate=2026-03-11", nil, http.StatusOK, &history)
+	if len(history) != 2 || history[0].TotalCalories != 247.5 || history[1].TotalCalories != 89 {
+		t.Errorf("history = %+v, want 247.5 and 89 kcal", history)
+	}
+
+	s.expect(token, http.MethodDelete, path, nil, http.StatusNoContent, nil)
+	s.expect(token, http.MethodDelete, path, nil, http.StatusNotFound, nil)
+	if got := s.dailyCalories(user.ID, day1, day2); got["2026-03-10"] != 0 || got["2026-03-11"] != 89 {
+		t.Errorf("rollups after deleting = %v, want only 2026-03-11: 89", got)
+	}
+}
+
+func TestFoodEntryAccessScoping(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.createUser("alice", models.RoleUser)
+	bob := s.createUser("bob", models.RoleUser)
+	admin := s.createUser("admin", models.RoleAdmin)
+	dietitian := s.createUser("dietitian", models.RoleDietitian)
+	aliceToken, bobToken := s.login(alice), s.login(bob)
+	adminToken, dietitianToken := s.login(admin), s.login(dietitian)
+
+	day := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	entry := s.addEntry(aliceToken, "173944", 100, models.MealSnack, day)
+	ownPath := fmt.Sprintf("/api/consumed-foods/%d", entry.ID)
+
+	// Another user can neither change nor delete the entry.
+	s.expect(bobToken, http.MethodPatch, ownPath, gin.H{"amount": 50}, http.StatusForbidden, nil)
+	s.expect(bobToken, http.MethodDelete, ownPath, nil, http.StatusForbidden, nil)
+	s.expect(bobToken, http.MethodGet, fmt.Sprintf("/api/admin/users/%d/consumed-foods/daily?date=2026-03-10", alice.ID), nil, http.StatusForbidden, nil)
+
+	// A dietitian without a subscription is refused too.
+	dietitianPath := fmt.Sprintf("/api/dietitian/users/%d/consumed-foods/%d", alice.ID, entry.ID)
+	s.expect(dietitianToken, http.MethodPatch, dietitianPath, gin.H{"amount": 50}, http.StatusForbidden, nil)
+	s.expect(dietitianToken, http.MethodGet, fmt.Sprintf("/api/dietitian/users/%d/consumed-foods/daily?date=2026-03-10", alice.ID), nil, http.StatusForbidden, nil)
+
+	// An admin can act on any log, but only on entries of the user in the path.
+	s.expect(adminToken, http.MethodPatch, fmt.Sprintf("/api/admin/users/%d/consumed-foods/%d", bob.ID, entry.ID), gin.H{"amount": 50}, http.StatusForbidden, nil)
+	var edited models.FoodEntry
+	s.expect(adminToken, http.MethodPatch, fmt.Sprintf("/api/admin/users/%d/consumed-foods/%d", alice.ID, entry.ID), gin.H{"amount": 50}, http.StatusOK, &edited)
+	if edited.Calories != 44.5 {
+		t.Errorf("50 g of banana = %v kcal, want 44.5", edited.Calories)
+	}
+
+	audit, err := s.store.Audit.GetAuditEntries(context.Background(), alice.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(audit) != 1 || audit[0].ActorID != admin.ID || audit[0].Action != models.AuditFoodEntryUpdate {
+		t.Errorf("audit log for alice = %+v, want one update by the admin", audit)
+	}
+
+	// The owner still has full access.
+	s.expect(aliceToken, http.MethodDelete, ownPath, nil, http.StatusNoContent, nil)
+}
+
+func TestSubscriptionGating(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.createUser("alice", models.RoleUser)
+	dietitian := s.createUser("dietitian", models.RoleDietitian)
+	aliceToken, dietitianToken := s.login(alice), s.login(dietitian)
+
+	day := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	s.addEntry(aliceToken, "173944", 100, models.MealSnack, day)
+	dailyPath := fmt.Sprintf("/api/dietitian/users/%d/consumed-foods/daily?date=2026-03-10", alice.ID)
+	subscribePath := fmt.Sprintf("/api/dietitians/%d/subscribe", dietitian.ID)
+
+	// A pending request gives the dietitian no access yet.
+	s.expect(aliceToken, http.MethodPost, subscribePath, nil, http.StatusAccepted, nil)
+	s.expect(dietitianToken, http.MethodGet, dailyPath, nil, http.StatusForbidden, nil)
+
+	s.expect(dietitianToken, http.MethodPost, fmt.Sprintf("/api/dietitian/requests/%d/accept", alice.ID), nil, http.StatusOK, nil)
+	var daily struct {
+		Meals []struct {
+			Meal    string            `json:"meal"`
+			Entries []json.RawMessage `json:"entries"`
+		} `json:"meals"`
+	}
+	s.expect(dietitianToken, http.MethodGet, dailyPath, nil, http.StatusOK, &daily)
+	entries := 0
+	for _, meal := range daily.Meals {
+		entries += len(meal.Entries)
+	}
+	if entries != 1 {
+		t.Errorf("dietitian sees %d entries, want 1", entries)
+	}
+
+	// Ending the subscription takes the access away again, with the same
+	// status on every dietitian route.
+	s.expect(aliceToken, http.MethodDelete, subscribePath, nil, http.StatusOK, nil)
+	s.expect(dietitianToken, http.MethodGet, dailyPath, nil, http.StatusForbidden, nil)
+	for _, path := range []string{"progress", "goals", "meal-plans", "tdee", "comments"} {
+		s.expect(dietitianToken, http.MethodGet, fmt.Sprintf("/api/dietitian/users/%d/%s", alice.ID, path), nil, http.StatusForbidden, nil)
+	}
+	s.expect(dietitianToken, http.MethodDelete, fmt.Sprintf("/api/dietitian/users/%d", alice.ID), nil, http.StatusForbidden, nil)
+
+	// Only dietitians can use the dietitian routes at all.
+	s.expect(aliceToken, http.MethodGet, "/api/dietitian/users", nil, http.StatusForbidden, nil)
+}
+
+func TestDeleteUserCascades(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.createUser("alice", models.RoleUser)
+	admin := s.createUser("admin", models.RoleAdmin)
+	aliceToken, adminToken := s.login(alice), s.login(admin)
+
+	day := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	s.addEntry(aliceToken, "173944", 100, models.MealSnack, day)
+	s.expect(aliceToken, http.MethodPost, "/api/weight", gin.H{"weight": 64.5, "recordedAt": day}, http.StatusCreated, nil)
+
+	s.expect(adminToken, http.MethodDelete, fmt.Sprintf("/api/admin/users/%d", alice.ID), nil, http.StatusOK, nil)
+
+	ctx := context.Background()
+	entries, err := s.store.FoodEntries.GetEntriesByDay(ctx, alice.ID, day, day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("%d days of food entries are left after deleting the user", len(entries))
+	}
+	if got := s.dailyCalories(alice.ID, day, day); len(got) != 0 {
+		t.Errorf("rollups left after deleting the user: %v", got)
+	}
+	weights, err := s.store.Weights.GetWeightEntries(ctx, alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(weights) != 0 {
+		t.Errorf("%d weight entries are left after deleting the user", len(weights))
+	}
+
+	// The deleted user's session ends with the account.
+	s.expect(aliceToken, http.MethodGet, "/api/auth/profile", nil, http.StatusUnauthorized, nil)
+}
+
+func TestLogPlanItem(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.createUser("alice", models.RoleUser)
+	dietitian := s.createUser("dietitian", models.RoleDietitian)
+	aliceToken, dietitianToken := s.login(alice), s.login(dietitian)
+
+	s.expect(aliceToken, http.MethodPost, fmt.Sprintf("/api/dietitians/%d/subscribe", dietitian.ID), nil, http.StatusAccepted, nil)
+	s.expect(dietitianToken, http.MethodPost, fmt.Sprintf("/api/dietitian/requests/%d/accept", alice.ID), nil, http.StatusOK, nil)
+
+	var plan models.MealPlan
+	s.expect(dietitianToken, http.MethodPost, fmt.Sprintf("/api/dietitian/users/%d/meal-plans", alice.ID), gin.H{
+		"name": "Two days", "days": 2, "startDate": "2026-03-10", "endDate": "2026-03-16",
+		"items": []gin.H{
+			{"day": 1, "meal": models.MealLunch, "foodId": "171477", "amount": 150},
+			{"day": 2, "meal": models.MealSnack, "foodId": "173944", "amount": 100},
+		},
+	}, http.StatusCreated, &plan)
+	var dayOne, dayTwo models.MealPlanItem
+	for _, item := range plan.Items {
+		if item.Day == 1 {
+			dayOne = item
+		} else {
+			dayTwo = item
+		}
+	}
+
+	// Dates are YYYY-MM-DD like everywhere else in the API, and the item must
+	// be planned for that day.
+	logPath := fmt.Sprintf("/api/meal-plans/items/%d/log", dayOne.ID)
+	var entry models.FoodEntry
+	s.expect(aliceToken, http.MethodPost, logPath, gin.H{"date": "2026-03-12", "amount": 1}, http.StatusCreated, &entry)
+	if got := entry.Date.Format("2006-01-02"); got != "2026-03-12" || entry.Amount != 1 || entry.Protein != 0.31 {
+		t.Errorf("logged %v g on %s with %v g protein, want 1 g on 2026-03-12 with 0.31", entry.Amount, got, entry.Protein)
+	}
+	s.expect(aliceToken, http.MethodPost, logPath, gin.H{"date": "2026-03-11"}, http.StatusConflict, nil)
+	s.expect(aliceToken, http.MethodPost, logPath, gin.H{"date": "2026-03-12T12:00:00Z"}, http.StatusBadRequest, nil)
+	s.expect(aliceToken, http.MethodPost, fmt.Sprintf("/api/meal-plans/items/%d/log", dayTwo.ID), gin.H{"date": "2026-03-11"}, http.StatusCreated, nil)
+}
+
+// verificationLink stores an email verification token for the user and
+// returns the verify path with it.
+func (s *testServer) verificationLink(user *models.User, now time.Time) string {
+	s.t.Helper()
+
+	token, stored, err := models.NewUserToken(user.ID, models.TokenEmailVerification, models.EmailVerificationLifetime, now)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	if err := s.store.UserTokens.CreateUserToken(context.Background(), stored); err != nil {
+		s.t.Fatal(err)
+	}
+	return "/api/auth/verify?token=" + url.QueryEscape(token)
+}
+
+func TestVerifyEmail(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.EmailVerification = config.VerificationRestrict
+	})
+	bob := s.createUnverifiedUser("bob")
+	token := s.login(bob)
+	entry := gin.H{"foodId": "173944", "amount": 120, "meal": models.MealSnack, "date": time.Now()}
+
+	// Until they verify, users can read and manage their account, but not
+	// make changes.
+	s.expect(token, http.MethodGet, "/api/user/dietitians", nil, http.StatusOK, nil)
+	s.expect(token, http.MethodGet, "/api/auth/2fa", nil, http.StatusOK, nil)
+	s.expect(token, http.MethodPost, "/api/auth/2fa/enroll", gin.H{"password": testPassword}, http.StatusOK, nil)
+	s.expect(token, http.MethodPost, "/api/consumed-foods", entry, http.StatusForbidden, nil)
+
+	// Expired and unknown links don't verify.
+	s.expect("", http.MethodGet, s.verificationLink(bob, time.Now().Add(-models.EmailVerificationLifetime)), nil, http.StatusBadRequest, nil)
+	s.expect("", http.MethodGet, "/api/auth/verify?token=unknown", nil, http.StatusBadRequest, nil)
+	s.expect("", http.MethodGet, "/api/auth/verify", nil, http.StatusBadRequest, nil)
+	s.expect(token, http.MethodPost, "/api/consumed-foods", entry, http.StatusForbidden, nil)
+
+	// A link verifies once.
+	link := s.verificationLink(bob, time.Now())
+	s.expect("", http.MethodGet, link, nil, http.StatusOK, nil)
+	s.expect("", http.MethodGet, link, nil, http.StatusBadRequest, nil)
+	s.expect(token, http.MethodPost, "/api/consumed-foods", entry, http.StatusCreated, nil)
+}
+
+func TestVerificationBlocksLogin(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.EmailVerification = config.VerificationBlock
+	})
+	bob := s.createUnverifiedUser("bob")
+	credentials := gin.H{"email": bob.Email, "password": testPassword}
+
+	s.expect("", http.MethodPost, "/api/auth/login", credentials, http.StatusForbidden, nil)
+	s.expect("", http.MethodGet, s.verificationLink(bob, time.Now()), nil, http.StatusOK, nil)
+
+	// Once verified, users aren't restricted either.
+	token := s.login(bob)
+	s.expect(token, http.MethodPost, "/api/consumed-foods",
+		gin.H{"foodId": "173944", "amount": 120, "meal": models.MealSnack, "date": time.Now()}, http.StatusCreated, nil)
+}
+
+func TestPasswordReset(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.createUser("alice", models.RoleUser)
+	aliceToken := s.login(alice)
+
+	link, stored, err := models.NewUserToken(alice.ID, models.TokenPasswordReset, models.PasswordResetLifetime, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.store.UserTokens.CreateUserToken(context.Background(), stored); err != nil {
+		t.Fatal(err)
+	}
+
+	// A rejected password leaves the link usable.
+	s.expect("", http.MethodPost, "/api/auth/password/reset", gin.H{"token": link, "password": "short"}, http.StatusBadRequest, nil)
+	s.expect("", http.MethodPost, "/api/auth/password/reset", gin.H{"token": link, "password": "new-password"}, http.StatusOK, nil)
+	s.expect("", http.MethodPost, "/api/auth/password/reset", gin.H{"token": link, "password": "other-password"}, http.StatusBadRequest, nil)
+
+	// Every session is signed out and only the new password works.
+	s.expect(aliceToken, http.MethodGet, "/api/auth/sessions", nil, http.StatusUnauthorized, nil)
+	s.expect("", http.MethodPost, "/api/auth/login", gin.H{"email": alice.Email, "password": testPassword}, http.StatusUnauthorized, nil)
+	s.expect("", http.MethodPost, "/api/auth/login", gin.H{"email": alice.Email, "password": "new-password"}, http.StatusOK, nil)
+}
+
+func TestLoginTwoFactorLimits(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.createUser("alice", models.RoleUser)
+	secret, recoveryCodes := s.enableTwoFactor(s.login(alice))
+	wrongCode := totp(t, secret, 5)
+
+	expectLogin := func(body gin.H, status int, message string) {
+		t.Helper()
+		recorder := s.loginTwoFactor(body)
+		if recorder.Code != status || !strings.Contains(recorder.Body.String(), message) {
+			t.Fatalf("second step: status %d %s, want %d %q", recorder.Code, recorder.Body.String(), status, message)
+		}
+	}
+
+	// An mfaToken takes five codes, however many addresses they come from.
+	mfaToken := s.startLogin(alice)
+	for i := 0; i < 5; i++ {
+		expectLogin(gin.H{"mfaToken": mfaToken, "code": wrongCode}, http.StatusUnauthorized, "Invalid two-factor code")
+	}
+	expectLogin(gin.H{"mfaToken": mfaToken, "code": totp(t, secret, 1)}, http.StatusUnauthorized, "Login expired")
+
+	// A right code logs in once per mfaToken.
+	mfaToken = s.startLogin(alice)
+	expectLogin(gin.H{"mfaToken": mfaToken, "code": totp(t, secret, 1)}, http.StatusOK, "token")
+	expectLogin(gin.H{"mfaToken": mfaToken, "recoveryCode": recoveryCodes[0]}, http.StatusUnauthorized, "Login expired")
+
+	// Ten wrong codes in a row lock the user, even across fresh mfaTokens.
+	for i := 0; i < 2; i++ {
+		mfaToken = s.startLogin(alice)
+		for j := 0; j < 5; j++ {
+			expectLogin(gin.H{"mfaToken": mfaToken, "code": wrongCode}, http.StatusUnauthorized, "Invalid two-factor code")
+		}
+	}
+	expectLogin(gin.H{"mfaToken": s.startLogin(alice), "recoveryCode": recoveryCodes[0]}, http.StatusTooManyRequests, "Too many invalid codes")
+}
+
+func TestLoginTwoFactor(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.createUser("alice", models.RoleUser)
+	secret, recoveryCodes := s.enableTwoFactor(s.login(alice))
+
+	loginWith := func(body gin.H, status int) string {
+		t.Helper()
+		body["mfaToken"] = s.startLogin(alice)
+		recorder := s.loginTwoFactor(body)
+		if recorder.Code != status {
+			t.Fatalf("second step with %v: status %d %s, want %d", body, recorder.Code, recorder.Body.String(), status)
+		}
+		var login struct {
+			Token string `json:"token"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &login); err != nil {
+			t.Fatal(err)
+		}
+		return login.Token
+	}
+
+	// The second step hands out an access token that works like any other.
+	code := totp(t, secret, 1)
+	token := loginWith(gin.H{"code": code}, http.StatusOK)
+	var status models.TwoFactorStatus
+	s.expect(token, http.MethodGet, "/api/auth/2fa", nil, http.StatusOK, &status)
+	if !status.Enabled {
+		t.Error("status after two-factor login is not enabled")
+	}
+
+	// A code can't be replayed, even with a fresh mfaToken.
+	loginWith(gin.H{"code": code}, http.StatusUnauthorized)
+
+	// Recovery codes are accepted in any case and without dashes, once each.
+	recoveryCode := strings.ToLower(strings.ReplaceAll(recoveryCodes[0], "-", ""))
+	loginWith(gin.H{"recoveryCode": recoveryCode}, http.StatusOK)
+	loginWith(gin.H{"recoveryCode": recoveryCodes[0]}, http.StatusUnauthorized)
+	loginWith(gin.H{"recoveryCode": recoveryCodes[1]}, http.StatusOK)
+
+	// Without the second step the mfaToken is no access token.
+	if recorder := s.request(s.startLogin(alice), http.MethodGet, "/api/auth/2fa", nil); recorder.Code != http.StatusUnauthorized {
+		t.Errorf("mfaToken as access token: status %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestRequireTwoFactor(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.MFARequiredRoles = []string{models.RoleDietitian}
+	})
+	alice := s.createUser("alice", models.RoleUser)
+	dietitian := s.createUser("dietitian", models.RoleDietitian)
+
+	// Roles that don't require it are let through without a second factor.
+	s.expect(s.login(alice), http.MethodGet, "/api/user/dietitians", nil, http.StatusOK, nil)
+
+	// A dietitian without it only reaches /api/auth/, to set it up.
+	token := s.login(dietitian)
+	s.expect(token, http.MethodGet, "/api/dietitian/users", nil, http.StatusForbidden, nil)
+	s.expect(token, http.MethodGet, "/api/user/dietitians", nil, http.StatusForbidden, nil)
+	var status models.TwoFactorStatus
+	s.expect(token, http.MethodGet, "/api/auth/2fa", nil, http.StatusOK, &status)
+	if !status.Required || status.Enabled {
+		t.Errorf("status before setup = %+v, want required and not enabled", status)
+	}
+
+	secret, _ := s.enableTwoFactor(token)
+	s.expect(token, http.MethodGet, "/api/dietitian/users", nil, http.StatusOK, nil)
+
+	// Nor can it be turned off again.
+	s.expect(token, http.MethodDelete, "/api/auth/2fa", gin.H{"password": testPassword, "code": totp(t, secret, 1)}, http.StatusForbidden, nil)
+}
+
+func TestSearchFoodsPages(t *testing.T) {
+	s := newTestServer(t)
+	token := s.login(s.createUser("alice", models.RoleUser))
+
+	var result struct {
+		Foods      []models.FDCSearchFood `json:"foods"`
+		TotalHits  int                    `json:"totalHits"`
+		TotalPages int                    `json:"totalPages"`
+	}
+	s.expect(token, http.MethodGet, "/api/foods/search?q=banana&pageSize=1", nil, http.StatusOK, &result)
+	if len(result.Foods) != 1 || result.TotalHits != 1 || result.TotalPages != 1 {
+		t.Errorf("first page = %+v, want the banana alone", result)
+	}
+	s.expect(token, http.MethodGet, "/api/foods/search?q=banana&page=2", nil, http.StatusOK, &result)
+	if len(result.Foods) != 0 {
+		t.Errorf("page past the matches has %d foods", len(result.Foods))
+	}
+
+	for _, page := range []string{"0", "-1", "1002", "9223372036854775807"} {
+		s.expect(token, http.MethodGet, "/api/foods/search?q=banana&pageSize=1&page="+page, nil, http.StatusBadRequest, nil)
+	}
+}
+
+func TestWeightTrend(t *testing.T) {
+	s := newTestServer(t)
+	token := s.login(s.createUser("alice", models.RoleUser))
+
+	type weightResponse struct {
+		Entries       []models.WeightTrendPoint `json:"entries"`
+		TrendWeight   *float64                  `json:"trendWeight"`
+		LatestWeight  *float64                  `json:"latestWeight"`
+		WeeklyChange  float64                   `json:"weeklyChange"`
+		ProjectedDate *time.Time                `json:"projectedDate"`
+	}
+	var empty weightResponse
+	s.expect(token, http.MethodGet, "/api/weight", nil, http.StatusOK, &empty)
+	if len(empty.Entries) != 0 || empty.TrendWeight != nil || empty.LatestWeight != nil || empty.ProjectedDate != nil {
+		t.Errorf("without weigh-ins: %+v", empty)
+	}
+
+	now := time.Now()
+	for i, weight := range []float64{80, 79.5, 79, 78.5} {
+		recordedAt := now.AddDate(0, 0, 7*(i-3))
+		s.expect(token, http.MethodPost, "/api/weight", gin.H{"weight": weight, "recordedAt": recordedAt}, http.StatusCreated, nil)
+	}
+	setTarget := func(weight float64) {
+		t.Helper()
+		s.expect(token, http.MethodPut, "/api/user/goals",
+			gin.H{"targetCalories": 1800, "targetProtein": 120, "targetCarbs": 180, "targetFats": 60, "targetWeight": weight}, http.StatusOK, nil)
+	}
+
+	// The window only limits the entries; the trend runs over all of them.
+	setTarget(75)
+	var losing weightResponse
+	s.expect(token, http.MethodGet, "/api/weight?days=10", nil, http.StatusOK, &losing)
+	if len(losing.Entries) != 2 || losing.Entries[0].Weight != 79 {
+		t.Fatalf("last 10 days: %+v, want the last two weigh-ins", losing.Entries)
+	}
+	if losing.Entries[0].Trend >= 79.5 || losing.LatestWeight == nil || *losing.LatestWeight != 78.5 {
+		t.Errorf("trend %v and latest weight %v, want the trend smoothed from 80", losing.Entries[0].Trend, losing.LatestWeight)
+	}
+	if losing.TrendWeight == nil || *losing.TrendWeight != losing.Entries[1].Trend {
+		t.Errorf("trend weight %v, want %v", losing.TrendWeight, losing.Entries[1].Trend)
+	}
+	if losing.WeeklyChange >= 0 || losing.ProjectedDate == nil || !losing.ProjectedDate.After(now) {
+		t.Errorf("weekly change %v, projected %v, want a loss projected ahead", losing.WeeklyChange, losing.ProjectedDate)
+	}
+
+	// Nothing is projected while the trend moves away from the target.
+	setTarget(85)
+	var gaining weightResponse
+	s.expect(token, http.MethodGet, "/api/weight", nil, http.StatusOK, &gaining)
+	if len(gaining.Entries) != 4 || gaining.ProjectedDate != nil {
+		t.Errorf("target above a losing trend: %d entries, projected %v", len(gaining.Entries), gaining.ProjectedDate)
+	}
+}
+
+func TestAdaptiveTDEE(t *testing.T) {
+	s := newTestServer(t)
+	token := s.login(s.createUser("alice", models.RoleUser))
+
+	var response struct {
+		Estimate  models.TDEEEstimate `json:"estimate"`
+		CanAccept bool                `json:"canAccept"`
+	}
+	s.expect(token, http.MethodGet, "/api/user/tdee", nil, http.StatusOK, &response)
+	if response.Estimate.TDEE != 0 || response.Estimate.SuggestedGoal != 0 || response.CanAccept {
+		t.Errorf("without logs: %+v", response)
+	}
+	s.expect(token, http.MethodPost, "/api/user/tdee/accept", nil, http.StatusConflict, nil)
+
+	// Four weeks ending yesterday of 2492 kcal a day at a stable weight.
+	yesterday := time.Now().AddDate(0, 0, -1)
+	for i := 0; i < 28; i++ {
+		day := yesterday.AddDate(0, 0, -i)
+		s.addEntry(token, "173944", 2800, models.MealLunch, day)
+		s.expect(token, http.MethodPost, "/api/weight", gin.H{"weight": 65, "recordedAt": day}, http.StatusCreated, nil)
+	}
+	// Today is left out, as it is usually only partly logged.
+	s.addEntry(token, "173944", 100, models.MealBreakfast, time.Now())
+
+	s.expect(token, http.MethodGet, "/api/user/tdee", nil, http.StatusOK, &response)
+	if response.Estimate.TDEE != 2492 || response.Estimate.SuggestedGoal != 2490 || !response.CanAccept {
+		t.Errorf("after four weeks: %+v", response)
+	}
+
+	var accepted struct {
+		Goals models.UserGoals `json:"goals"`
+	}
+	s.expect(token, http.MethodPost, "/api/user/tdee/accept", nil, http.StatusOK, &accepted)
+	if accepted.Goals.TargetCalories != 2490 {
+		t.Errorf("accepted goal = %d kcal, want 2490", accepted.Goals.TargetCalories)
+	}
+}
+
+// evaluateAlerts runs the alert evaluation of asOf to the end and returns
+// how many alerts it opened.
+func (s *testServer) evaluateAlerts(adminToken string, asOf time.Time) int {
+	s.t.Helper()
+
+	var job models.Job
+	s.expect(adminToken, http.MethodPost, "/api/admin/jobs/evaluate-alerts", gin.H{"asOf": asOf.Format("2006-01-02")}, http.StatusAccepted, &job)
+	deadline := time.Now().Add(5 * time.Second)
+	for job.Status != models.JobSucceeded {
+		if job.Status == models.JobFailed || time.Now().After(deadline) {
+			s.t.Fatalf("alert evaluation ended as %+v", job)
+		}
+		time.Sleep(10 * time.Millisecond)
+		s.expect(adminToken, http.MethodGet, fmt.Sprintf("/api/admin/jobs/%d", job.ID), nil, http.StatusOK, &job)
+	}
+	return job.Changed
+}
+
+func TestAlertLifecycle(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.createUser("alice", models.RoleUser)
+	dietitian := s.createUser("dietitian", models.RoleDietitian)
+	aliceToken, dietitianToken := s.login(alice), s.login(dietitian)
+	adminToken := s.login(s.createUser("admin", models.RoleAdmin))
+
+	s.expect(aliceToken, http.MethodPost, fmt.Sprintf("/api/dietitians/%d/subscribe", dietitian.ID), nil, http.StatusAccepted, nil)
+	s.expect(dietitianToken, http.MethodPost, fmt.Sprintf("/api/dietitian/requests/%d/accept", alice.ID), nil, http.StatusOK, nil)
+
+	alertsWith := func(status string) []models.Alert {
+		t.Helper()
+		var alerts []models.Alert
+		s.expect(dietitianToken, http.MethodGet, "/api/dietitian/alerts?status="+status, nil, http.StatusOK, &alerts)
+		return alerts
+	}
+
+	// Alice last logged five days ago, so yesterday she hadn't for four.
+	today := models.DateOnly(time.Now())
+	s.addEntry(aliceToken, "173944", 100, models.MealSnack, today.AddDate(0, 0, -5).Add(12*time.Hour))
+	yesterday := today.AddDate(0, 0, -1)
+	if opened := s.evaluateAlerts(adminToken, yesterday); opened != 1 {
+		t.Fatalf("first evaluation opened %d alerts, want 1", opened)
+	}
+	open := alertsWith(models.AlertOpen)
+	if len(open) != 1 || open[0].Rule != models.AlertNotLogging || open[0].UserID != alice.ID {
+		t.Fatalf("open alerts = %+v, want Alice not logging", open)
+	}
+	alert := open[0]
+
+	// A snoozed alert stays hidden while the rule keeps applying.
+	s.expect(dietitianToken, http.MethodPost, fmt.Sprintf("/api/dietitian/alerts/%d/snooze", alert.ID), gin.H{"hours": 24}, http.StatusOK, nil)
+	if opened := s.evaluateAlerts(adminToken, yesterday); opened != 0 {
+		t.Errorf("evaluation of a snoozed alert opened %d alerts", opened)
+	}
+	if open := alertsWith(models.AlertOpen); len(open) != 0 {
+		t.Errorf("open alerts while snoozed = %+v", open)
+	}
+	snoozed := alertsWith(models.AlertSnoozed)
+	if len(snoozed) != 1 || !snoozed[0].DetectedAt.Equal(alert.DetectedAt) {
+		t.Fatalf("snoozed alerts = %+v, want the first alert", snoozed)
+	}
+
+	// It is back once the snooze ends.
+	later, err := s.store.Alerts.GetAlerts(context.Background(), dietitian.ID, models.AlertOpen, 0, snoozed[0].SnoozedUntil.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(later) != 1 || later[0].ID != alert.ID {
+		t.Errorf("open alerts after the snooze = %+v", later)
+	}
+
+	// An acknowledged alert stays acknowledged while the rule keeps applying.
+	s.expect(dietitianToken, http.MethodPost, fmt.Sprintf("/api/dietitian/alerts/%d/acknowledge", alert.ID), nil, http.StatusOK, nil)
+	if opened := s.evaluateAlerts(adminToken, yesterday); opened != 0 {
+		t.Errorf("evaluation of an acknowledged alert opened %d alerts", opened)
+	}
+	if open := alertsWith(models.AlertOpen); len(open) != 0 {
+		t.Errorf("open alerts after acknowledging = %+v", open)
+	}
+	if acknowledged := alertsWith(models.AlertAcknowledged); len(acknowledged) != 1 {
+		t.Errorf("acknowledged alerts = %+v, want the first alert", acknowledged)
+	}
+
+	// Once Alice logs again it is resolved, and it opens afresh only when she
+	// stops again.
+	s.addEntry(aliceToken, "173944", 100, models.MealSnack, yesterday.Add(12*time.Hour))
+	s.evaluateAlerts(adminToken, yesterday)
+	if resolved := alertsWith(models.AlertResolved); len(resolved) != 1 {
+		t.Errorf("resolved alerts = %+v, want the first alert", resolved)
+	}
+	if opened := s.evaluateAlerts(adminToken, yesterday.AddDate(0, 0, 3)); opened != 1 {
+		t.Errorf("evaluation after three more days opened %d alerts, want 1", opened)
+	}
+	open = alertsWith(models.AlertOpen)
+	if len(open) != 1 || open[0].ID != alert.ID || open[0].AcknowledgedAt != nil {
+		t.Errorf("open alerts after reopening = %+v", open)
+	}
+}
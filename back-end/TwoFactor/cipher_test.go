@@ -0,0 +1,82 @@
+package twofactor
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+func newTestCipher(t *testing.T, fill byte) *Cipher {
+	t.Helper()
+
+	cipher, err := NewCipher(bytes.Repeat([]byte{fill}, KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cipher
+}
+
+func TestCipherRoundTrip(t *testing.T) {
+	cipher := newTestCipher(t, 1)
+
+	encrypted, err := cipher.Encrypt(rfcSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := cipher.Encrypt(rfcSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encrypted == again {
+		t.Error("Encrypt reused a nonce")
+	}
+
+	for _, ciphertext := range []string{encrypted, again} {
+		plaintext, err := cipher.Decrypt(ciphertext)
+		if err != nil {
+			t.Fatalf("Decrypt: %v", err)
+		}
+		if plaintext != rfcSecret {
+			t.Errorf("Decrypt = %q, want %q", plaintext, rfcSecret)
+		}
+	}
+}
+
+func TestCipherRejectsTampering(t *testing.T) {
+	cipher := newTestCipher(t, 1)
+
+	encrypted, err := cipher.Encrypt(rfcSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Flipping any bit of the nonce, text or tag breaks authentication.
+	for _, i := range []int{0, len(sealed) / 2, len(sealed) - 1} {
+		tampered := bytes.Clone(sealed)
+		tampered[i] ^= 0x01
+		if _, err := cipher.Decrypt(base64.StdEncoding.EncodeToString(tampered)); err == nil {
+			t.Errorf("Decrypt accepted a flipped byte %d", i)
+		}
+	}
+
+	if _, err := newTestCipher(t, 2).Decrypt(encrypted); err == nil {
+		t.Error("Decrypt accepted another key")
+	}
+	for _, ciphertext := range []string{"", "AAAA", "not base64!"} {
+		if _, err := cipher.Decrypt(ciphertext); err == nil {
+			t.Errorf("Decrypt accepted %q", ciphertext)
+		}
+	}
+}
+
+func TestNewCipherKeySize(t *testing.T) {
+	for _, size := range []int{0, 16, KeySize - 1, KeySize + 1} {
+		if _, err := NewCipher(make([]byte, size)); err == nil {
+			t.Errorf("NewCipher accepted a %d-byte key", size)
+		}
+	}
+}
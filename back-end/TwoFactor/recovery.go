@@ -0,0 +1,49 @@
+package twofactor
+
+import (
+	"crypto/rand"
+	"strings"
+)
+
+// RecoveryCodeCount is how many recovery codes a user gets at a time.
+const RecoveryCodeCount = 10
+
+// recoveryAlphabet leaves out 0, 1, O and I, which are easy to misread.
+const recoveryAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// recoveryCodeLength gives 80 random bits per code, too many to brute-force
+// from a plain hash.
+const recoveryCodeLength = 16
+
+// GenerateRecoveryCodes returns count random codes formatted XXXX-XXXX-XXXX-XXXX.
+func GenerateRecoveryCodes(count int) ([]string, error) {
+	codes := make([]string, count)
+	for i := range codes {
+		b := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+
+		var code strings.Builder
+		for j, v := range b {
+			if j > 0 && j%4 == 0 {
+				code.WriteByte('-')
+			}
+			code.WriteByte(recoveryAlphabet[int(v)%len(recoveryAlphabet)])
+		}
+		codes[i] = code.String()
+	}
+	return codes, nil
+}
+
+// NormalizeRecoveryCode removes the separators and case a user may type, so
+// codes can be hashed and compared.
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code)
+}
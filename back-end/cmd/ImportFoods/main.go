@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	config "HabitBite/backend/Config"
+	importer "HabitBite/backend/Importer"
+	repositories "HabitBite/backend/Repositories"
+
+	"github.com/joho/godotenv"
+)
+
+// ImportFoods loads USDA FoodData Central bulk downloads into the foods table.
+//
+//	go run ./cmd/ImportFoods -format=json FoodData_Central_foundation_food_json_2024-10-31.json
+//	go run ./cmd/ImportFoods -format=csv ./FoodData_Central_sr_legacy_food_csv_2018-04
+func main() {
+	format := flag.String("format", "json", "format of the download: json (file) or csv (extracted directory)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-format=json|csv] <path>...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: .env file not found, using environment variables")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal("Error loading config:", err)
+	}
+
+	db, err := config.NewDB(cfg)
+	if err != nil {
+		log.Fatal("Database connection failed:", err)
+	}
+	defer db.Close()
+
+	foodRepo := repositories.NewFoodRepository(db)
+	fdcImporter := importer.NewFDCImporter(foodRepo)
+	ctx := context.Background()
+
+	for _, path := range flag.Args() {
+		var imported int
+		switch *format {
+		case "json":
+			imported, err = fdcImporter.ImportJSON(ctx, path)
+		case "csv":
+			imported, err = fdcImporter.ImportCSV(ctx, path)
+		default:
+			log.Fatalf("Unknown format %q", *format)
+		}
+		if err != nil {
+			log.Fatalf("Import of %s failed after %d foods: %v", path, imported, err)
+		}
+		log.Printf("Imported %d foods from %s", imported, path)
+	}
+
+	total, err := foodRepo.CountFoods(ctx)
+	if err != nil {
+		log.Fatal("Failed to count foods:", err)
+	}
+	log.Printf("Catalog now contains %d foods", total)
+}
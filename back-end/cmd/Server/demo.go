@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	models "HabitBite/backend/Models"
+	repositories "HabitBite/backend/Repositories"
+)
+
+// demoPassword is the password of every seeded demo account.
+const demoPassword = "demo1234"
+
+// seedDemoData fills an in-memory store with an admin, a dietitian with a
+// public profile, a client subscribed to that dietitian, a small food catalog, four weeks of meals and
+// three weeks of weigh-ins.
+func seedDemoData(ctx context.Context, store *repositories.Store) error {
+	birthdate := time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC)
+	accounts := []*models.User{
+		{Email: "admin@habitbite.local", Username: "admin", FullName: "Demo Admin", Role: models.RoleAdmin,
+			Gender: "other", Height: 175, Weight: 75, GoalType: models.GoalMaintain, ActivityLevel: models.ActivityModerate, DailyCalorieGoal: 2400},
+		{Email: "dietitian@habitbite.local", Username: "dietitian", FullName: "Demo Dietitian", Role: models.RoleDietitian,
+			Gender: "female", Height: 168, Weight: 62, GoalType: models.GoalMaintain, ActivityLevel: models.ActivityActive, DailyCalorieGoal: 2200},
+		{Email: "user@habitbite.local", Username: "user", FullName: "Demo User", Role: models.RoleUser,
+			Gender: "male", Height: 180, Weight: 88, GoalType: models.GoalLose, ActivityLevel: models.ActivityLight, DailyCalorieGoal: 2100},
+	}
+	verifiedAt := time.Now()
+	for _, account := range accounts {
+		account.Birthdate = birthdate
+		account.EmailVerifiedAt = &verifiedAt
+		if err := account.SetPassword(demoPassword); err != nil {
+			return err
+		}
+		if err := store.Users.CreateUser(ctx, account); err != nil {
+			return fmt.Errorf("could not create %s: %v", account.Username, err)
+		}
+	}
+	dietitian, client := accounts[1], accounts[2]
+
+	profile := &models.DietitianProfile{
+		UserID:        dietitian.ID,
+		DisplayName:   dietitian.FullName,
+		Credentials:   "Registered Dietitian Nutritionist",
+		LicenseNumber: "RD-000000",
+		Bio:           "Demo dietitian focused on sustainable weight loss.",
+	}
+	if err := store.Dietitians.SaveProfile(ctx, profile); err != nil {
+		return err
+	}
+
+	if _, err := store.Users.SubscribeUserToDietitian(ctx, client.ID, dietitian.ID); err != nil {
+		return err
+	}
+	if _, err := store.Users.RespondToSubscription(ctx, client.ID, dietitian.ID, true); err != nil {
+		return err
+	}
+
+	foods := []*models.Food{
+		{FdcID: 171688, Description: "Apples, raw, with skin", DataType: models.FoodTypeSRLegacy, Calories: 52, Protein: 0.26, Carbs: 13.81, Fat: 0.17},
+		{FdcID: 173944, Description: "Bananas, raw", DataType: models.FoodTypeSRLegacy, Calories: 89, Protein: 1.09, Carbs: 22.84, Fat: 0.33},
+		{FdcID: 171477, Description: "Chicken, broilers or fryers, breast, meat only, cooked, roasted", DataType: models.FoodTypeSRLegacy, Calories: 165, Protein: 31.02, Carbs: 0, Fat: 3.57},
+		{FdcID: 169756, Description: "Rice, white, long-grain, regular, cooked", DataType: models.FoodTypeSRLegacy, Calories: 130, Protein: 2.69, Carbs: 28.17, Fat: 0.28},
+		{FdcID: 173424, Description: "Oats, regular and quick, not fortified, dry", DataType: models.FoodTypeSRLegacy, Calories: 379, Protein: 13.15, Carbs: 67.7, Fat: 6.52},
+		{FdcID: 171287, Description: "Egg, whole, cooked, hard-boiled", DataType: models.FoodTypeSRLegacy, Calories: 155, Protein: 12.58, Carbs: 1.12, Fat: 10.61},
+		{FdcID: 170567, Description: "Broccoli, raw", DataType: models.FoodTypeSRLegacy, Calories: 34, Protein: 2.82, Carbs: 6.64, Fat: 0.37},
+		{FdcID: 173430, Description: "Yogurt, Greek, plain, nonfat", DataType: models.FoodTypeSRLegacy, Calories: 59, Protein: 10.19, Carbs: 3.6, Fat: 0.39},
+	}
+	if err := store.Foods.UpsertFoods(ctx, foods); err != nil {
+		return err
+	}
+
+	meals := []struct {
+		food   *models.Food
+		amount float64
+		meal   string
+		hour   int
+	}{
+		{foods[4], 90, models.MealBreakfast, 8},
+		{foods[1], 120, models.MealBreakfast, 8},
+		{foods[2], 220, models.MealLunch, 13},
+		{foods[3], 300, models.MealLunch, 13},
+		{foods[6], 150, models.MealDinner, 19},
+		{foods[5], 150, models.MealDinner, 19},
+		{foods[7], 250, models.MealSnack, 16},
+	}
+	today := time.Now()
+	for day := 27; day >= 0; day-- {
+		date := today.AddDate(0, 0, -day)
+		for i, planned := range meals {
+			// Skip a different item each day so the history is not flat.
+			if i == day%len(meals) {
+				continue
+			}
+			entry := &models.FoodEntry{
+				UserID: client.ID,
+				FoodID: fmt.Sprint(planned.food.FdcID),
+				Name:   planned.food.Description,
+				Amount: planned.amount,
+				Meal:   planned.meal,
+				Date:   time.Date(date.Year(), date.Month(), date.Day(), planned.hour, 0, 0, 0, time.Local),
+			}
+			entry.Calories, entry.Protein, entry.Carbs, entry.Fat = planned.food.NutritionFor(planned.amount)
+			if err := store.FoodEntries.CreateFoodEntry(ctx, entry); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Three weeks of weigh-ins trending down with some daily noise.
+	for day := 20; day >= 0; day-- {
+		date := today.AddDate(0, 0, -day)
+		noise := []float64{0.4, -0.3, 0.1, -0.5, 0.2}[day%5]
+		entry := &models.WeightEntry{
+			UserID:     client.ID,
+			Weight:     math.Round((90-0.1*float64(20-day)+noise)*10) / 10,
+			RecordedAt: time.Date(date.Year(), date.Month(), date.Day(), 7, 30, 0, 0, time.Local),
+		}
+		if err := store.Weights.CreateWeightEntry(ctx, entry); err != nil {
+			return err
+		}
+	}
+
+	goals, err := store.Users.GetUserGoals(ctx, client.ID)
+	if err != nil {
+		return err
+	}
+	goals.TargetWeight = 80
+	if err := store.Users.UpdateUserGoals(ctx, goals); err != nil {
+		return err
+	}
+
+	log.Printf("Demo mode: data is kept in memory and lost on exit")
+	for _, account := range accounts {
+		log.Printf("Demo account %-9s %s / %s", account.Role, account.Email, demoPassword)
+	}
+	return nil
+}
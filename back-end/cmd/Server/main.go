@@ -10,9 +10,16 @@ import (
 	"syscall"
 	"time"
 
+	alerts "HabitBite/backend/Alerts"
 	config "HabitBite/backend/Config"
+	goals "HabitBite/backend/Goals"
+	jobs "HabitBite/backend/Jobs"
+	mailer "HabitBite/backend/Mailer"
+	messaging "HabitBite/backend/Messaging"
 	middleware "HabitBite/backend/Middleware"
+	repositories "HabitBite/backend/Repositories"
 	Routes "HabitBite/backend/Routes"
+	twofactor "HabitBite/backend/TwoFactor"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-contrib/sessions/cookie"
@@ -41,12 +48,70 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	// Database connection
-	db, err := config.NewMySQLDB(cfg)
+	planner, err := goals.NewPlanner(cfg.BMRFormula, cfg.MacroPresets, cfg.ProteinPerKg)
 	if err != nil {
-		log.Fatal("Database connection failed:", err)
+		log.Fatal("Invalid goal configuration:", err)
+	}
+	alertRules, err := alerts.NewRules(cfg.AlertRules)
+	if err != nil {
+		log.Fatal("Invalid alert configuration:", err)
+	}
+	mail, err := mailer.New(mailer.Settings{
+		Driver:       cfg.MailDriver,
+		From:         cfg.MailFrom,
+		File:         cfg.MailFile,
+		SMTPHost:     cfg.SMTPHost,
+		SMTPPort:     cfg.SMTPPort,
+		SMTPUser:     cfg.SMTPUser,
+		SMTPPassword: cfg.SMTPPassword,
+	})
+	if err != nil {
+		log.Fatal("Invalid mail configuration:", err)
+	}
+	if cfg.MFAEncryptionKey == "" {
+		log.Println("Warning: MFA_ENCRYPTION_KEY not set, deriving the TOTP secret key from JWT_SECRET")
+	}
+	mfaKey, err := cfg.TwoFactorKey()
+	if err != nil {
+		log.Fatal("Invalid two-factor configuration:", err)
+	}
+	secrets, err := twofactor.NewCipher(mfaKey)
+	if err != nil {
+		log.Fatal("Invalid two-factor configuration:", err)
+	}
+
+	var repos *repositories.Store
+	if len(os.Args) > 1 && os.Args[1] == "--demo" {
+		// Demo mode runs without a database on seeded in-memory repositories
+		repos = repositories.NewMemoryStore(planner)
+		if err := seedDemoData(context.Background(), repos); err != nil {
+			log.Fatal("Seeding demo data failed:", err)
+		}
+	} else {
+		// Database connection
+		db, err := config.NewDB(cfg)
+		if err != nil {
+			log.Fatal("Database connection failed:", err)
+		}
+		defer db.Close()
+
+		if len(os.Args) > 1 && os.Args[1] == "migrate" {
+			runMigrate(db, os.Args[2:])
+			return
+		}
+		warnPendingMigrations(db)
+
+		repos = repositories.NewStore(db, planner)
+	}
+
+	// Background jobs, including those interrupted by the last shutdown
+	runner := jobs.NewRunner(repos, alertRules)
+	if err := runner.Resume(context.Background()); err != nil {
+		log.Printf("Warning: could not resume jobs: %v", err)
+	}
+	if cfg.AlertHour >= 0 {
+		runner.ScheduleAlerts(cfg.AlertHour)
 	}
-	defer db.Close()
 
 	// Create Gin router
 	router := gin.New()
@@ -69,8 +134,11 @@ func main() {
 		middleware.SecurityHeaders(),
 	)
 
+	// Live delivery of messages to open conversation streams
+	hub := messaging.NewHub()
+
 	// Set up all routes using the routes.go file
-	Routes.SetupRoutes(router, db, cfg)
+	Routes.SetupRoutes(router, repos, runner, hub, mail, secrets, cfg)
 
 	api := router.Group("/api")
 	public := api.Group("")
@@ -97,6 +165,8 @@ func main() {
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	// Shutdown waits for active requests, so end the open streams first.
+	srv.RegisterOnShutdown(hub.Close)
 
 	// Start server in a goroutine
 	go func() {
@@ -137,6 +207,9 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatal("Server forced to shutdown:", err)
 	}
+	if err := runner.Shutdown(ctx); err != nil {
+		log.Printf("Jobs did not stop in time: %v", err)
+	}
 
 	log.Println("Server exiting")
 }
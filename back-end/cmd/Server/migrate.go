@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	migrations "HabitBite/backend/Migrations"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const migrateUsage = "Usage: server migrate up|down [steps]|status"
+
+// runMigrate implements the migrate subcommand:
+//
+//	go run ./cmd/Server migrate up
+//	go run ./cmd/Server migrate down 1
+//	go run ./cmd/Server migrate status
+func runMigrate(db *sqlx.DB, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		log.Fatal("Failed to load migrations:", err)
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			log.Printf("Applied %04d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(applied) == 0 {
+			log.Println("Database is up to date")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				fmt.Fprintln(os.Stderr, migrateUsage)
+				os.Exit(2)
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			log.Printf("Reverted %04d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(reverted) == 0 {
+			log.Println("No migrations to revert")
+		}
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, state)
+		}
+
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+}
+
+// warnPendingMigrations logs a warning when the schema is behind the binary.
+func warnPendingMigrations(db *sqlx.DB) {
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		log.Printf("Warning: could not load migrations: %v", err)
+		return
+	}
+
+	pending, err := migrator.Pending(context.Background())
+	if err != nil {
+		log.Printf("Warning: could not check migrations: %v", err)
+		return
+	}
+	if len(pending) > 0 {
+		log.Printf("Warning: %d pending migrations, run `server migrate up`", len(pending))
+	}
+}
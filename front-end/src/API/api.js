@@ -240,6 +240,7 @@ export const foodEntryAPI = {
         protein: foodEntry.protein,
         carbs: foodEntry.carbs,
         fat: foodEntry.fat || foodEntry.fats,
+        meal: foodEntry.meal,
       };
 
       const response = await api.post("/consumed-foods", sanitizedEntry);
@@ -262,16 +263,14 @@ export const foodEntryAPI = {
     try {
       const response = await api.get(`/consumed-foods/daily?date=${date}`);
 
-      if (!response.data) {
+      if (!response.data || !Array.isArray(response.data.meals)) {
+        console.error("Unexpected response for daily entries:", response.data);
         return [];
       }
 
-      if (!Array.isArray(response.data)) {
-        console.error("Non-array response for daily entries:", response.data);
-        return [];
-      }
+      const entries = response.data.meals.flatMap((meal) => meal.entries || []);
 
-      const mappedEntries = response.data.map((entry) => {
+      const mappedEntries = entries.map((entry) => {
         let entryDate = entry.entry_date;
         let timestamp = "";
 
@@ -304,6 +303,7 @@ export const foodEntryAPI = {
           carbs: entry.carbs,
           fat: entry.fat,
           calories: entry.calories,
+          meal: entry.meal,
           date: new Date(entry.entry_date),
           timestamp: timestamp,
           grams: entry.quantity,
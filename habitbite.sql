@@ -10,6 +10,7 @@
 SET SQL_MODE = "NO_AUTO_VALUE_ON_ZERO";
 START TRANSACTION;
 SET time_zone = "+00:00";
+SET FOREIGN_KEY_CHECKS = 0;
 
 
 /*!40101 SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT */;
@@ -20,27 +21,11 @@ SET time_zone = "+00:00";
 --
 -- Database: `habitbite`
 --
-
--- --------------------------------------------------------
-
---
--- Table structure for table `consumed_foods`
+-- Sample data only. Create the schema first with `go run ./cmd/Server migrate up`
+-- from back-end/, then import this file.
 --
 
-CREATE TABLE `consumed_foods` (
-  `id` int(11) NOT NULL,
-  `user_id` int(11) NOT NULL,
-  `food_id` varchar(50) NOT NULL,
-  `food_name` varchar(255) NOT NULL,
-  `quantity` decimal(10,2) NOT NULL,
-  `calories` decimal(10,2) NOT NULL,
-  `protein` decimal(10,2) NOT NULL,
-  `carbs` decimal(10,2) NOT NULL,
-  `fats` decimal(10,2) NOT NULL,
-  `entry_date` datetime NOT NULL,
-  `created_at` datetime NOT NULL,
-  `updated_at` datetime NOT NULL
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+-- --------------------------------------------------------
 
 --
 -- Dumping data for table `consumed_foods`
@@ -88,21 +73,6 @@ INSERT INTO `consumed_foods` (`id`, `user_id`, `food_id`, `food_name`, `quantity
 
 -- --------------------------------------------------------
 
---
--- Table structure for table `daily_entries`
---
-
-CREATE TABLE `daily_entries` (
-  `id` int(11) NOT NULL,
-  `user_id` int(11) NOT NULL,
-  `entry_date` date NOT NULL,
-  `total_calories` int(11) NOT NULL,
-  `total_protein` decimal(5,2) DEFAULT NULL,
-  `total_carbs` decimal(5,2) DEFAULT NULL,
-  `total_fats` decimal(5,2) DEFAULT NULL,
-  `notes` text DEFAULT NULL
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
-
 --
 -- Dumping data for table `daily_entries`
 --
@@ -129,28 +99,6 @@ INSERT INTO `daily_entries` (`id`, `user_id`, `entry_date`, `total_calories`, `t
 
 -- --------------------------------------------------------
 
---
--- Table structure for table `users`
---
-
-CREATE TABLE `users` (
-  `id` int(11) NOT NULL,
-  `email` varchar(100) NOT NULL,
-  `username` varchar(50) NOT NULL,
-  `password_hash` varchar(255) NOT NULL,
-  `full_name` varchar(100) NOT NULL,
-  `birthdate` date NOT NULL,
-  `gender` enum('male','female','other') NOT NULL,
-  `height` decimal(5,2) NOT NULL,
-  `weight` decimal(5,2) NOT NULL,
-  `goal_type` enum('lose','gain','maintain') NOT NULL,
-  `activity_level` enum('sedentary','light','moderate','active','very_active') NOT NULL,
-  `daily_calorie_goal` int(11) NOT NULL DEFAULT 2000 COMMENT 'Default value will be calculated during registration',
-  `role` enum('admin','dietitian','user') NOT NULL DEFAULT 'user',
-  `created_at` timestamp NOT NULL DEFAULT current_timestamp(),
-  `updated_at` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp()
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
-
 --
 -- Dumping data for table `users`
 --
@@ -182,16 +130,6 @@ INSERT INTO `users` (`id`, `email`, `username`, `password_hash`, `full_name`, `b
 
 -- --------------------------------------------------------
 
---
--- Table structure for table `user_dietitian`
---
-
-CREATE TABLE `user_dietitian` (
-  `user_id` int(11) NOT NULL,
-  `dietitian_id` int(11) NOT NULL,
-  `assigned_at` timestamp NOT NULL DEFAULT current_timestamp()
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
-
 --
 -- Dumping data for table `user_dietitian`
 --
@@ -202,19 +140,6 @@ INSERT INTO `user_dietitian` (`user_id`, `dietitian_id`, `assigned_at`) VALUES
 
 -- --------------------------------------------------------
 
---
--- Table structure for table `user_goals`
---
-
-CREATE TABLE `user_goals` (
-  `user_id` int(11) NOT NULL,
-  `target_calories` int(11) NOT NULL,
-  `target_protein` decimal(5,2) DEFAULT NULL,
-  `target_carbs` decimal(5,2) DEFAULT NULL,
-  `target_fats` decimal(5,2) DEFAULT NULL,
-  `target_weight` decimal(5,2) DEFAULT NULL
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
-
 --
 -- Dumping data for table `user_goals`
 --
@@ -231,98 +156,7 @@ INSERT INTO `user_goals` (`user_id`, `target_calories`, `target_protein`, `targe
 (26, 2367, 207.11, 177.53, 92.05, 75.00),
 (27, 2390, 149.38, 298.75, 66.39, 70.00);
 
---
--- Indexes for dumped tables
---
-
---
--- Indexes for table `consumed_foods`
---
-ALTER TABLE `consumed_foods`
-  ADD PRIMARY KEY (`id`),
-  ADD KEY `idx_user_date` (`user_id`,`entry_date`);
-
---
--- Indexes for table `daily_entries`
---
-ALTER TABLE `daily_entries`
-  ADD PRIMARY KEY (`id`),
-  ADD UNIQUE KEY `user_id` (`user_id`,`entry_date`),
-  ADD KEY `idx_entries_user_date` (`user_id`,`entry_date`),
-  ADD KEY `user_id_2` (`user_id`);
-
---
--- Indexes for table `users`
---
-ALTER TABLE `users`
-  ADD PRIMARY KEY (`id`),
-  ADD UNIQUE KEY `email` (`email`),
-  ADD UNIQUE KEY `username` (`username`),
-  ADD KEY `idx_users_email` (`email`);
-
---
--- Indexes for table `user_dietitian`
---
-ALTER TABLE `user_dietitian`
-  ADD PRIMARY KEY (`user_id`,`dietitian_id`),
-  ADD KEY `dietitian_id` (`dietitian_id`);
-
---
--- Indexes for table `user_goals`
---
-ALTER TABLE `user_goals`
-  ADD PRIMARY KEY (`user_id`);
-
---
--- AUTO_INCREMENT for dumped tables
---
-
---
--- AUTO_INCREMENT for table `consumed_foods`
---
-ALTER TABLE `consumed_foods`
-  MODIFY `id` int(11) NOT NULL AUTO_INCREMENT, AUTO_INCREMENT=66;
-
---
--- AUTO_INCREMENT for table `daily_entries`
---
-ALTER TABLE `daily_entries`
-  MODIFY `id` int(11) NOT NULL AUTO_INCREMENT, AUTO_INCREMENT=25;
-
---
--- AUTO_INCREMENT for table `users`
---
-ALTER TABLE `users`
-  MODIFY `id` int(11) NOT NULL AUTO_INCREMENT, AUTO_INCREMENT=29;
-
---
--- Constraints for dumped tables
---
-
---
--- Constraints for table `consumed_foods`
---
-ALTER TABLE `consumed_foods`
-  ADD CONSTRAINT `consumed_foods_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE;
-
---
--- Constraints for table `daily_entries`
---
-ALTER TABLE `daily_entries`
-  ADD CONSTRAINT `daily_entries_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE;
-
---
--- Constraints for table `user_dietitian`
---
-ALTER TABLE `user_dietitian`
-  ADD CONSTRAINT `user_dietitian_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
-  ADD CONSTRAINT `user_dietitian_ibfk_2` FOREIGN KEY (`dietitian_id`) REFERENCES `users` (`id`) ON DELETE CASCADE;
-
---
--- Constraints for table `user_goals`
---
-ALTER TABLE `user_goals`
-  ADD CONSTRAINT `user_goals_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE;
+SET FOREIGN_KEY_CHECKS = 1;
 COMMIT;
 
 /*!40101 SET CHARACTER_SET_CLIENT=@OLD_CHARACTER_SET_CLIENT */;